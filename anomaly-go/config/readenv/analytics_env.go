@@ -0,0 +1,257 @@
+package readenv
+
+import (
+	"anomaly-go/log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// Default values used when ANALYTICS.env or one of its variables is missing.
+const (
+	defaultRuleReloadSeconds             = 30
+	defaultDriftIntervalMinutes          = 60
+	defaultDriftCurrentHours             = 24
+	defaultDriftReferenceDays            = 14
+	defaultDriftMinSamples               = 50
+	defaultDriftPSIAlert                 = 0.25
+	defaultDriftKLAlert                  = 0.1
+	defaultDuplicateIntervalSeconds      = 60
+	defaultDuplicateLookbackHours        = 24
+	defaultDuplicateWindowSeconds        = 30
+	defaultSeasonalityIntervalMinutes    = 60
+	defaultSeasonalityHistoryWeeks       = 8
+	defaultSilentIntervalMinutes         = 15
+	defaultSilentWindowHours             = 6
+	defaultSilentDropRatio               = 0.2
+	defaultSilentMinExpected             = 5
+	defaultSilentDeadBatteryLevel        = 5
+	defaultBatteryScoreIntervalMinutes   = 60
+	defaultBatteryScoreWindowDays        = 30
+	defaultBatteryAnomalyIntervalMinutes = 5
+	defaultBatteryWearIntervalMinutes    = 15
+	defaultBatteryKPIIntervalHours       = 24
+)
+
+// AnalyticsConfiguration holds the settings of the detection jobs and background workers.
+type AnalyticsConfiguration struct {
+	RuleReloadInterval time.Duration
+
+	// Drift monitoring compares the last DriftCurrentWindow with the
+	// DriftReferenceWindow before it, every DriftInterval. Segments with fewer than
+	// DriftMinSamples transactions in either window are skipped, and results above
+	// DriftPSIAlert or DriftKLAlert raise an alert.
+	DriftInterval        time.Duration
+	DriftCurrentWindow   time.Duration
+	DriftReferenceWindow time.Duration
+	DriftMinSamples      int
+	DriftPSIAlert        float64
+	DriftKLAlert         float64
+
+	// Duplicate detection runs every DuplicateInterval over the last DuplicateLookback
+	// of transactions. A transaction is a duplicate when an earlier one of the same
+	// device lies within DuplicateWindow and DuplicateAmountTolerance of it.
+	DuplicateInterval        time.Duration
+	DuplicateLookback        time.Duration
+	DuplicateWindow          time.Duration
+	DuplicateAmountTolerance float64
+
+	// Structuring detector overrides. Zero values keep the detector defaults.
+	StructuringLimits           []float64
+	StructuringNearLimitPercent int
+	StructuringMinRepeats       int
+	StructuringRoundUnit        int
+	StructuringMinSamples       int
+
+	// Seasonal profiles are updated every SeasonalityInterval with the completed
+	// hours since the last run, looking back at most SeasonalityHistory.
+	SeasonalityInterval time.Duration
+	SeasonalityHistory  time.Duration
+
+	// Silent device detection runs every SilentInterval. A device is silent when its
+	// transactions over the last SilentWindow fall to SilentDropRatio of what its
+	// seasonal profile expects, provided it expects at least SilentMinExpected. A last
+	// battery level at or below SilentDeadBatteryLevel percent blames the battery.
+	SilentInterval         time.Duration
+	SilentWindow           time.Duration
+	SilentDropRatio        float64
+	SilentMinExpected      float64
+	SilentDeadBatteryLevel float64
+
+	// Battery scores are recomputed into bl_score every BatteryScoreInterval from the
+	// battery_health blocks of the last BatteryScoreWindow.
+	BatteryScoreInterval time.Duration
+	BatteryScoreWindow   time.Duration
+
+	// New battery_health blocks are checked for anomalies every
+	// BatteryAnomalyInterval, against the fleet rates of the BatteryScoreWindow.
+	BatteryAnomalyInterval time.Duration
+
+	// Charge cycles and wear metrics are brought up to date with the new
+	// battery_health blocks every BatteryWearInterval.
+	BatteryWearInterval time.Duration
+
+	// The fleet battery KPIs are snapshotted into battery_kpi_snapshots once the last
+	// snapshot is BatteryKPIInterval old.
+	BatteryKPIInterval time.Duration
+}
+
+var AnalyticsConfigVar AnalyticsConfiguration
+
+// ReadAnalyticsConfiguration reads ANALYTICS.env. The file is optional: every setting
+// has a default so existing deployments keep working without it.
+func ReadAnalyticsConfiguration() bool {
+	AnalyticsConfigVar = AnalyticsConfiguration{
+		RuleReloadInterval:     defaultRuleReloadSeconds * time.Second,
+		DriftInterval:          defaultDriftIntervalMinutes * time.Minute,
+		DriftCurrentWindow:     defaultDriftCurrentHours * time.Hour,
+		DriftReferenceWindow:   defaultDriftReferenceDays * 24 * time.Hour,
+		DriftMinSamples:        defaultDriftMinSamples,
+		DriftPSIAlert:          defaultDriftPSIAlert,
+		DriftKLAlert:           defaultDriftKLAlert,
+		DuplicateInterval:      defaultDuplicateIntervalSeconds * time.Second,
+		DuplicateLookback:      defaultDuplicateLookbackHours * time.Hour,
+		DuplicateWindow:        defaultDuplicateWindowSeconds * time.Second,
+		SeasonalityInterval:    defaultSeasonalityIntervalMinutes * time.Minute,
+		SeasonalityHistory:     defaultSeasonalityHistoryWeeks * 7 * 24 * time.Hour,
+		SilentInterval:         defaultSilentIntervalMinutes * time.Minute,
+		SilentWindow:           defaultSilentWindowHours * time.Hour,
+		SilentDropRatio:        defaultSilentDropRatio,
+		SilentMinExpected:      defaultSilentMinExpected,
+		SilentDeadBatteryLevel: defaultSilentDeadBatteryLevel,
+		BatteryScoreInterval:   defaultBatteryScoreIntervalMinutes * time.Minute,
+		BatteryScoreWindow:     defaultBatteryScoreWindowDays * 24 * time.Hour,
+		BatteryAnomalyInterval: defaultBatteryAnomalyIntervalMinutes * time.Minute,
+		BatteryWearInterval:    defaultBatteryWearIntervalMinutes * time.Minute,
+		BatteryKPIInterval:     defaultBatteryKPIIntervalHours * time.Hour,
+	}
+
+	if _, err := os.Stat(ANALYTICS_VAR_ENV_FILENAME); err != nil {
+		log.WriteLog.Warn("Analytics env file not found, using defaults", zap.Error(err))
+		return true
+	}
+	viper.SetConfigFile(ANALYTICS_VAR_ENV_FILENAME)
+
+	// Read all the configs from Viper
+	err := viper.ReadInConfig()
+	if err != nil {
+		log.WriteLog.Error("Failed to read from the file", zap.Error(err))
+		return false
+	}
+
+	if _, seconds := ReadENVValueInt(PRODUCTION_ENVIRONMENT, ANALYTICS_VAR_RULE_RELOAD_SECONDS); seconds > 0 {
+		AnalyticsConfigVar.RuleReloadInterval = time.Duration(seconds) * time.Second
+	}
+	if _, minutes := ReadENVValueInt(PRODUCTION_ENVIRONMENT, ANALYTICS_VAR_DRIFT_INTERVAL_MINUTES); minutes > 0 {
+		AnalyticsConfigVar.DriftInterval = time.Duration(minutes) * time.Minute
+	}
+	if _, hours := ReadENVValueInt(PRODUCTION_ENVIRONMENT, ANALYTICS_VAR_DRIFT_CURRENT_HOURS); hours > 0 {
+		AnalyticsConfigVar.DriftCurrentWindow = time.Duration(hours) * time.Hour
+	}
+	if _, days := ReadENVValueInt(PRODUCTION_ENVIRONMENT, ANALYTICS_VAR_DRIFT_REFERENCE_DAYS); days > 0 {
+		AnalyticsConfigVar.DriftReferenceWindow = time.Duration(days) * 24 * time.Hour
+	}
+	if _, samples := ReadENVValueInt(PRODUCTION_ENVIRONMENT, ANALYTICS_VAR_DRIFT_MIN_SAMPLES); samples > 0 {
+		AnalyticsConfigVar.DriftMinSamples = samples
+	}
+	if _, limit := ReadENVValueFloat64(PRODUCTION_ENVIRONMENT, ANALYTICS_VAR_DRIFT_PSI_ALERT); limit > 0 {
+		AnalyticsConfigVar.DriftPSIAlert = limit
+	}
+	if _, limit := ReadENVValueFloat64(PRODUCTION_ENVIRONMENT, ANALYTICS_VAR_DRIFT_KL_ALERT); limit > 0 {
+		AnalyticsConfigVar.DriftKLAlert = limit
+	}
+	if _, seconds := ReadENVValueInt(PRODUCTION_ENVIRONMENT, ANALYTICS_VAR_DUPLICATE_INTERVAL_SECONDS); seconds > 0 {
+		AnalyticsConfigVar.DuplicateInterval = time.Duration(seconds) * time.Second
+	}
+	if _, hours := ReadENVValueInt(PRODUCTION_ENVIRONMENT, ANALYTICS_VAR_DUPLICATE_LOOKBACK_HOURS); hours > 0 {
+		AnalyticsConfigVar.DuplicateLookback = time.Duration(hours) * time.Hour
+	}
+	if _, seconds := ReadENVValueInt(PRODUCTION_ENVIRONMENT, ANALYTICS_VAR_DUPLICATE_WINDOW_SECONDS); seconds > 0 {
+		AnalyticsConfigVar.DuplicateWindow = time.Duration(seconds) * time.Second
+	}
+	if _, tolerance := ReadENVValueFloat64(PRODUCTION_ENVIRONMENT, ANALYTICS_VAR_DUPLICATE_AMOUNT_TOLERANCE); tolerance > 0 {
+		AnalyticsConfigVar.DuplicateAmountTolerance = tolerance
+	}
+	if ok, limits := ReadENVValueString(PRODUCTION_ENVIRONMENT, ANALYTICS_VAR_STRUCTURING_LIMITS); ok {
+		for _, part := range strings.Split(limits, ",") {
+			limit, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+			if err != nil || limit <= 0 {
+				log.WriteLog.Error("Invalid structuring limit, ignoring it", zap.String("value", part))
+				continue
+			}
+			AnalyticsConfigVar.StructuringLimits = append(AnalyticsConfigVar.StructuringLimits, limit)
+		}
+	}
+	_, AnalyticsConfigVar.StructuringNearLimitPercent = ReadENVValueInt(PRODUCTION_ENVIRONMENT, ANALYTICS_VAR_STRUCTURING_NEAR_LIMIT_PERCENT)
+	_, AnalyticsConfigVar.StructuringMinRepeats = ReadENVValueInt(PRODUCTION_ENVIRONMENT, ANALYTICS_VAR_STRUCTURING_MIN_REPEATS)
+	_, AnalyticsConfigVar.StructuringRoundUnit = ReadENVValueInt(PRODUCTION_ENVIRONMENT, ANALYTICS_VAR_STRUCTURING_ROUND_UNIT)
+	_, AnalyticsConfigVar.StructuringMinSamples = ReadENVValueInt(PRODUCTION_ENVIRONMENT, ANALYTICS_VAR_STRUCTURING_MIN_SAMPLES)
+	if _, minutes := ReadENVValueInt(PRODUCTION_ENVIRONMENT, ANALYTICS_VAR_SEASONALITY_INTERVAL_MINUTES); minutes > 0 {
+		AnalyticsConfigVar.SeasonalityInterval = time.Duration(minutes) * time.Minute
+	}
+	if _, weeks := ReadENVValueInt(PRODUCTION_ENVIRONMENT, ANALYTICS_VAR_SEASONALITY_HISTORY_WEEKS); weeks > 0 {
+		AnalyticsConfigVar.SeasonalityHistory = time.Duration(weeks) * 7 * 24 * time.Hour
+	}
+	if _, minutes := ReadENVValueInt(PRODUCTION_ENVIRONMENT, ANALYTICS_VAR_SILENT_INTERVAL_MINUTES); minutes > 0 {
+		AnalyticsConfigVar.SilentInterval = time.Duration(minutes) * time.Minute
+	}
+	if _, hours := ReadENVValueInt(PRODUCTION_ENVIRONMENT, ANALYTICS_VAR_SILENT_WINDOW_HOURS); hours > 0 {
+		AnalyticsConfigVar.SilentWindow = time.Duration(hours) * time.Hour
+	}
+	if _, ratio := ReadENVValueFloat64(PRODUCTION_ENVIRONMENT, ANALYTICS_VAR_SILENT_DROP_RATIO); ratio > 0 && ratio < 1 {
+		AnalyticsConfigVar.SilentDropRatio = ratio
+	}
+	if _, expected := ReadENVValueFloat64(PRODUCTION_ENVIRONMENT, ANALYTICS_VAR_SILENT_MIN_EXPECTED); expected > 0 {
+		AnalyticsConfigVar.SilentMinExpected = expected
+	}
+	if _, level := ReadENVValueFloat64(PRODUCTION_ENVIRONMENT, ANALYTICS_VAR_SILENT_DEAD_BATTERY_LEVEL); level > 0 {
+		AnalyticsConfigVar.SilentDeadBatteryLevel = level
+	}
+	if _, minutes := ReadENVValueInt(PRODUCTION_ENVIRONMENT, ANALYTICS_VAR_BATTERY_SCORE_INTERVAL_MINUTES); minutes > 0 {
+		AnalyticsConfigVar.BatteryScoreInterval = time.Duration(minutes) * time.Minute
+	}
+	if _, days := ReadENVValueInt(PRODUCTION_ENVIRONMENT, ANALYTICS_VAR_BATTERY_SCORE_WINDOW_DAYS); days > 0 {
+		AnalyticsConfigVar.BatteryScoreWindow = time.Duration(days) * 24 * time.Hour
+	}
+	if _, minutes := ReadENVValueInt(PRODUCTION_ENVIRONMENT, ANALYTICS_VAR_BATTERY_ANOMALY_INTERVAL_MINUTES); minutes > 0 {
+		AnalyticsConfigVar.BatteryAnomalyInterval = time.Duration(minutes) * time.Minute
+	}
+	if _, minutes := ReadENVValueInt(PRODUCTION_ENVIRONMENT, ANALYTICS_VAR_BATTERY_WEAR_INTERVAL_MINUTES); minutes > 0 {
+		AnalyticsConfigVar.BatteryWearInterval = time.Duration(minutes) * time.Minute
+	}
+	if _, hours := ReadENVValueInt(PRODUCTION_ENVIRONMENT, ANALYTICS_VAR_BATTERY_KPI_INTERVAL_HOURS); hours > 0 {
+		AnalyticsConfigVar.BatteryKPIInterval = time.Duration(hours) * time.Hour
+	}
+
+	log.WriteLog.Info("Analytics configuration",
+		zap.Duration("RuleReloadInterval", AnalyticsConfigVar.RuleReloadInterval),
+		zap.Duration("DriftInterval", AnalyticsConfigVar.DriftInterval),
+		zap.Duration("DriftCurrentWindow", AnalyticsConfigVar.DriftCurrentWindow),
+		zap.Duration("DriftReferenceWindow", AnalyticsConfigVar.DriftReferenceWindow),
+		zap.Int("DriftMinSamples", AnalyticsConfigVar.DriftMinSamples),
+		zap.Float64("DriftPSIAlert", AnalyticsConfigVar.DriftPSIAlert),
+		zap.Float64("DriftKLAlert", AnalyticsConfigVar.DriftKLAlert),
+		zap.Duration("DuplicateInterval", AnalyticsConfigVar.DuplicateInterval),
+		zap.Duration("DuplicateLookback", AnalyticsConfigVar.DuplicateLookback),
+		zap.Duration("DuplicateWindow", AnalyticsConfigVar.DuplicateWindow),
+		zap.Float64("DuplicateAmountTolerance", AnalyticsConfigVar.DuplicateAmountTolerance),
+		zap.Float64s("StructuringLimits", AnalyticsConfigVar.StructuringLimits),
+		zap.Duration("SeasonalityInterval", AnalyticsConfigVar.SeasonalityInterval),
+		zap.Duration("SeasonalityHistory", AnalyticsConfigVar.SeasonalityHistory),
+		zap.Duration("SilentInterval", AnalyticsConfigVar.SilentInterval),
+		zap.Duration("SilentWindow", AnalyticsConfigVar.SilentWindow),
+		zap.Float64("SilentDropRatio", AnalyticsConfigVar.SilentDropRatio),
+		zap.Float64("SilentMinExpected", AnalyticsConfigVar.SilentMinExpected),
+		zap.Float64("SilentDeadBatteryLevel", AnalyticsConfigVar.SilentDeadBatteryLevel),
+		zap.Duration("BatteryScoreInterval", AnalyticsConfigVar.BatteryScoreInterval),
+		zap.Duration("BatteryScoreWindow", AnalyticsConfigVar.BatteryScoreWindow),
+		zap.Duration("BatteryAnomalyInterval", AnalyticsConfigVar.BatteryAnomalyInterval),
+		zap.Duration("BatteryWearInterval", AnalyticsConfigVar.BatteryWearInterval),
+		zap.Duration("BatteryKPIInterval", AnalyticsConfigVar.BatteryKPIInterval),
+	)
+	return true
+}
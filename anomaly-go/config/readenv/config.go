@@ -7,9 +7,10 @@ import (
 
 // AppConfig holds all application configurations.
 type AppConfig struct {
-	DBConfig   PostgresDBConfiguration
-	RestConfig GinConfiguration
-	JWTSecret  []byte // Centralized for easy access
+	DBConfig        PostgresDBConfiguration
+	RestConfig      GinConfiguration
+	AnalyticsConfig AnalyticsConfiguration
+	JWTSecret       []byte // Centralized for easy access
 }
 
 // Load reads all environment files and returns a single AppConfig struct.
@@ -37,11 +38,17 @@ func Load() (*AppConfig, error) {
 		return nil, fmt.Errorf("rest configuration error")
 	}
 
+	if !ReadAnalyticsConfiguration() {
+		log.WriteLog.Error("Failed to read analytics configuration")
+		return nil, fmt.Errorf("analytics configuration error")
+	}
+
 	// 3. Assemble the final config struct.
 	config := &AppConfig{
-		DBConfig:   PostgresDBVar,
-		RestConfig: GinConfigVar,
-		JWTSecret:  GinConfigVar.GinWebVar.WebJWTTokenKey,
+		DBConfig:        PostgresDBVar,
+		RestConfig:      GinConfigVar,
+		AnalyticsConfig: AnalyticsConfigVar,
+		JWTSecret:       GinConfigVar.GinWebVar.WebJWTTokenKey,
 	}
 
 	return config, nil
@@ -31,4 +31,35 @@ const (
 	GIN_VAR_REST_WEB_JWT_PASSKEY      = "GIN_REST_WEB_JWT_PASSKEY"
 	GIN_VAR_REST_BASE_PATH            = "GIN_REST_API_BASE_PATH"
 	GIN_VAR_REST_SERVER_EXTERNAL_PORT = "GIN_REST_SERVER_EXTERNAL_PORT"
+	// ENV FILE FOR ANALYTICS (optional, defaults are used when missing)
+	ANALYTICS_VAR_ENV_FILENAME = "ANALYTICS.env"
+	// Variable Names for ANALYTICS
+	ANALYTICS_VAR_RULE_RELOAD_SECONDS              = "ANALYTICS_RULE_RELOAD_SECONDS"
+	ANALYTICS_VAR_DRIFT_INTERVAL_MINUTES           = "ANALYTICS_DRIFT_INTERVAL_MINUTES"
+	ANALYTICS_VAR_DRIFT_CURRENT_HOURS              = "ANALYTICS_DRIFT_CURRENT_HOURS"
+	ANALYTICS_VAR_DRIFT_REFERENCE_DAYS             = "ANALYTICS_DRIFT_REFERENCE_DAYS"
+	ANALYTICS_VAR_DRIFT_MIN_SAMPLES                = "ANALYTICS_DRIFT_MIN_SAMPLES"
+	ANALYTICS_VAR_DRIFT_PSI_ALERT                  = "ANALYTICS_DRIFT_PSI_ALERT"
+	ANALYTICS_VAR_DRIFT_KL_ALERT                   = "ANALYTICS_DRIFT_KL_ALERT"
+	ANALYTICS_VAR_DUPLICATE_INTERVAL_SECONDS       = "ANALYTICS_DUPLICATE_INTERVAL_SECONDS"
+	ANALYTICS_VAR_DUPLICATE_LOOKBACK_HOURS         = "ANALYTICS_DUPLICATE_LOOKBACK_HOURS"
+	ANALYTICS_VAR_DUPLICATE_WINDOW_SECONDS         = "ANALYTICS_DUPLICATE_WINDOW_SECONDS"
+	ANALYTICS_VAR_DUPLICATE_AMOUNT_TOLERANCE       = "ANALYTICS_DUPLICATE_AMOUNT_TOLERANCE"
+	ANALYTICS_VAR_STRUCTURING_LIMITS               = "ANALYTICS_STRUCTURING_LIMITS"
+	ANALYTICS_VAR_STRUCTURING_NEAR_LIMIT_PERCENT   = "ANALYTICS_STRUCTURING_NEAR_LIMIT_PERCENT"
+	ANALYTICS_VAR_STRUCTURING_MIN_REPEATS          = "ANALYTICS_STRUCTURING_MIN_REPEATS"
+	ANALYTICS_VAR_STRUCTURING_ROUND_UNIT           = "ANALYTICS_STRUCTURING_ROUND_UNIT"
+	ANALYTICS_VAR_STRUCTURING_MIN_SAMPLES          = "ANALYTICS_STRUCTURING_MIN_SAMPLES"
+	ANALYTICS_VAR_SEASONALITY_INTERVAL_MINUTES     = "ANALYTICS_SEASONALITY_INTERVAL_MINUTES"
+	ANALYTICS_VAR_SEASONALITY_HISTORY_WEEKS        = "ANALYTICS_SEASONALITY_HISTORY_WEEKS"
+	ANALYTICS_VAR_SILENT_INTERVAL_MINUTES          = "ANALYTICS_SILENT_INTERVAL_MINUTES"
+	ANALYTICS_VAR_SILENT_WINDOW_HOURS              = "ANALYTICS_SILENT_WINDOW_HOURS"
+	ANALYTICS_VAR_SILENT_DROP_RATIO                = "ANALYTICS_SILENT_DROP_RATIO"
+	ANALYTICS_VAR_SILENT_MIN_EXPECTED              = "ANALYTICS_SILENT_MIN_EXPECTED"
+	ANALYTICS_VAR_SILENT_DEAD_BATTERY_LEVEL        = "ANALYTICS_SILENT_DEAD_BATTERY_LEVEL"
+	ANALYTICS_VAR_BATTERY_SCORE_INTERVAL_MINUTES   = "ANALYTICS_BATTERY_SCORE_INTERVAL_MINUTES"
+	ANALYTICS_VAR_BATTERY_SCORE_WINDOW_DAYS        = "ANALYTICS_BATTERY_SCORE_WINDOW_DAYS"
+	ANALYTICS_VAR_BATTERY_ANOMALY_INTERVAL_MINUTES = "ANALYTICS_BATTERY_ANOMALY_INTERVAL_MINUTES"
+	ANALYTICS_VAR_BATTERY_WEAR_INTERVAL_MINUTES    = "ANALYTICS_BATTERY_WEAR_INTERVAL_MINUTES"
+	ANALYTICS_VAR_BATTERY_KPI_INTERVAL_HOURS       = "ANALYTICS_BATTERY_KPI_INTERVAL_HOURS"
 )
@@ -49,6 +49,19 @@ func ReadENVValueInt32(productionEnv bool, configName string) (bool, int32) {
 	return true, value
 }
 
+// Helper function read configurations from either File / OS ENV
+func ReadENVValueFloat64(productionEnv bool, configName string) (bool, float64) {
+
+	if productionEnv {
+		viper.BindEnv(configName)
+	}
+
+	// Get the Data
+	value := viper.GetFloat64(configName)
+
+	return true, value
+}
+
 // Helper function read configurations from either File / OS ENV
 func ReadENVValueBool(productionEnv bool, configName string) (bool, bool) {
 
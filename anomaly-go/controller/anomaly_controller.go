@@ -3,11 +3,15 @@
 package controller
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 
 	"anomaly-go/log"
+	jsonmodel "anomaly-go/model/json"
+	model "anomaly-go/model/postgres"
 	anomaly "anomaly-go/service/anomaly"
+	"anomaly-go/util/constants"
 	"anomaly-go/util/httputils/response"
 
 	"github.com/gin-gonic/gin"
@@ -39,7 +43,7 @@ func (a *API) LoginHandler(c *gin.Context) {
 	}
 
 	// Note: Hardcoded credentials should be replaced for production environments.
-	if req.Username != "admin" || req.Password != "password" {
+	if req.Username != constants.AdminUsername || req.Password != "password" {
 		// UPDATED: Use the new response handling
 		appErr := response.NewAppError(http.StatusUnauthorized, "Invalid credentials", nil)
 		response.HandleError(c, appErr)
@@ -104,12 +108,7 @@ func (a *API) UpdateConfidenceThresholdHandler(c *gin.Context) {
 
 // FetchDataHandler fetches transaction data with filtering.
 func (a *API) FetchDataHandler(c *gin.Context) {
-	timeFilter := c.Query("time")
-	anomalyCheck := c.Query("anomaly_check")
-	deviceID := c.Query("device_id")
-	searchTerm := c.Query("search")
-
-	resp, err := a.Service.FetchData(timeFilter, anomalyCheck, deviceID, searchTerm)
+	resp, err := a.Service.FetchData(transactionFilterFromQuery(c))
 	if err != nil {
 		log.WriteLog.Error("Fetch data error", zap.Error(err))
 		response.HandleError(c, err)
@@ -120,6 +119,19 @@ func (a *API) FetchDataHandler(c *gin.Context) {
 	response.HandleSuccess(c, http.StatusOK, resp)
 }
 
+// transactionFilterFromQuery reads the /fetchData filters from the query string.
+func transactionFilterFromQuery(c *gin.Context) model.TransactionFilter {
+	return model.TransactionFilter{
+		Time:         c.Query("time"),
+		AnomalyCheck: c.Query("anomaly_check"),
+		DeviceID:     c.Query("device_id"),
+		Search:       c.Query("search"),
+		ReasonCode:   c.Query("reason_code"),
+		ModelVersion: c.Query("model_version"),
+		Duplicate:    c.Query("duplicate"),
+	}
+}
+
 // GetAllDeviceIdsHandler fetches all unique device IDs.
 func (a *API) GetAllDeviceIdsHandler(c *gin.Context) {
 	ids, err := a.Service.GetAllDeviceIds()
@@ -179,30 +191,50 @@ func (a *API) UpdateReviewHandler(c *gin.Context) {
 	response.HandleSuccess(c, http.StatusOK, gin.H{"message": "Review status updated successfully"})
 }
 
-// GetDeviceHealthDataHandler fetches device health data with filters.
+// GetDeviceHealthDataHandler fetches one page of device health data with filters.
 func (a *API) GetDeviceHealthDataHandler(c *gin.Context) {
-	deviceID := c.Query("device_id")
-	chargingStatus := c.Query("charging_status")
-	isAnomaly := c.Query("is_anomaly")
-	searchTerm := c.Query("search")
-
-	data, err := a.Service.GetDeviceHealthData(deviceID, chargingStatus, isAnomaly, searchTerm)
+	query := jsonmodel.DeviceHealthQuery{
+		DeviceID:       c.Query("device_id"),
+		ChargingStatus: c.Query("charging_status"),
+		IsAnomaly:      c.Query("is_anomaly"),
+		Search:         c.Query("search"),
+		From:           c.Query("from"),
+		To:             c.Query("to"),
+		MinBlock:       c.Query("min_block"),
+		MaxBlock:       c.Query("max_block"),
+		MinLevel:       c.Query("min_level"),
+		MaxLevel:       c.Query("max_level"),
+		Page:           c.Query("page"),
+		PageSize:       c.Query("page_size"),
+	}
+
+	resp, err := a.Service.GetDeviceHealthData(query)
+	if errors.Is(err, anomaly.ErrInvalidFilter) {
+		response.HandleError(c, response.NewAppError(http.StatusBadRequest, err.Error(), err))
+		return
+	}
 	if err != nil {
 		log.WriteLog.Error("Get device health data error", zap.Error(err))
 		response.HandleError(c, err)
 		return
 	}
 
-	log.WriteLog.Info("Fetched battery health data", zap.Int("record_count", len(data)))
-	response.HandleSuccess(c, http.StatusOK, gin.H{"battery_health": data})
+	log.WriteLog.Info("Fetched battery health data", zap.Int("record_count", len(resp.BatteryHealth)), zap.Int64("total", resp.Total))
+	response.HandleSuccess(c, http.StatusOK, resp)
 }
 
-// GetAtRiskKPIsHandler fetches at-risk device KPIs and details.
+// GetAtRiskKPIsHandler fetches device counts per battery risk tier and the devices
+// of the tier given in 'tier' ("all" for every device, at-risk tiers by default).
 func (a *API) GetAtRiskKPIsHandler(c *gin.Context) {
 	deviceID := c.Query("device_id")
 	searchTerm := c.Query("search")
+	tier := c.Query("tier")
 
-	resp, err := a.Service.GetAtRiskKPIs(deviceID, searchTerm)
+	resp, err := a.Service.GetAtRiskKPIs(deviceID, searchTerm, tier)
+	if errors.Is(err, anomaly.ErrUnknownRiskTier) {
+		response.HandleError(c, response.NewAppError(http.StatusBadRequest, "Invalid 'tier'. Expected a configured risk tier or 'all'", err))
+		return
+	}
 	if err != nil {
 		log.WriteLog.Error("Get at-risk KPIs error", zap.Error(err))
 		response.HandleError(c, err)
@@ -0,0 +1,244 @@
+// File: controller/battery_controller.go
+
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"anomaly-go/log"
+	jsonmodel "anomaly-go/model/json"
+	anomaly "anomaly-go/service/anomaly"
+	"anomaly-go/util/httputils/response"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// GetBatteryForecastHandler forecasts when devices will cross the at-risk battery
+// score, from the discharge blocks of the last three months unless 'time' says
+// otherwise. Without 'device_id' every device is forecast.
+func (a *API) GetBatteryForecastHandler(c *gin.Context) {
+	timeFilter := c.DefaultQuery("time", "3m")
+
+	var deviceID int64
+	if idStr := c.Query("device_id"); idStr != "" && idStr != "all" {
+		id, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil || id <= 0 {
+			appErr := response.NewAppError(http.StatusBadRequest, "Invalid 'device_id'. Expected a positive integer", err)
+			response.HandleError(c, appErr)
+			return
+		}
+		deviceID = id
+	}
+
+	resp, found, err := a.Service.ForecastBatteries(deviceID, timeFilter)
+	if errors.Is(err, anomaly.ErrNoAtRiskTier) {
+		response.HandleError(c, response.NewAppError(http.StatusConflict, err.Error(), err))
+		return
+	}
+	if err != nil {
+		log.WriteLog.Error("Battery forecast error", zap.Error(err))
+		response.HandleError(c, err)
+		return
+	}
+
+	if !found {
+		appErr := response.NewAppError(http.StatusNotFound, "No discharge blocks found for this device", nil)
+		response.HandleError(c, appErr)
+		return
+	}
+
+	log.WriteLog.Info("Forecast battery degradation", zap.Int("count", len(resp.Forecasts)))
+	response.HandleSuccess(c, http.StatusOK, resp)
+}
+
+// ReevaluateBatteryAnomaliesHandler queues battery blocks for a new anomaly check and
+// starts the check in the background (admin only).
+func (a *API) ReevaluateBatteryAnomaliesHandler(c *gin.Context) {
+	var req jsonmodel.ReevaluateBatteryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		appErr := response.NewAppError(http.StatusBadRequest, "Invalid request body. Expected optional 'device_id', 'from' and 'to'", err)
+		response.HandleError(c, appErr)
+		return
+	}
+	if err := a.Service.ValidateReevaluateBatteryRequest(req); err != nil {
+		response.HandleError(c, response.NewAppError(http.StatusBadRequest, err.Error(), err))
+		return
+	}
+
+	queued, err := a.Service.ReevaluateBatteryAnomalies(req)
+	if err != nil {
+		log.WriteLog.Error("Battery re-evaluation error", zap.Error(err))
+		response.HandleError(c, err)
+		return
+	}
+
+	log.WriteLog.Info("Battery blocks queued for re-evaluation", zap.Int64("count", queued))
+	response.HandleSuccess(c, http.StatusAccepted, gin.H{"message": "Battery anomaly re-evaluation started", "queued": queued})
+}
+
+// IngestBatteryBlocksHandler validates and stores a batch of battery blocks of one device.
+func (a *API) IngestBatteryBlocksHandler(c *gin.Context) {
+	var req jsonmodel.IngestBatteryBlocksRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		appErr := response.NewAppError(http.StatusBadRequest, "Invalid request body. Expected 'device_id' and 'blocks' with 1 to 1000 entries", err)
+		response.HandleError(c, appErr)
+		return
+	}
+
+	resp, err := a.Service.IngestBatteryBlocks(req)
+	if errors.Is(err, anomaly.ErrBatteryIngestUnavailable) {
+		response.HandleError(c, response.NewAppError(http.StatusServiceUnavailable, err.Error(), err))
+		return
+	}
+	if err != nil {
+		log.WriteLog.Error("Ingest battery blocks error", zap.Error(err))
+		response.HandleError(c, err)
+		return
+	}
+
+	log.WriteLog.Info("Ingested battery block batch", zap.Int64("device_id", req.DeviceID), zap.Int("received", len(req.Blocks)))
+	response.HandleSuccess(c, http.StatusOK, resp)
+}
+
+// GetBatteryTimeSeriesHandler returns the battery level of a device downsampled for
+// charting, with optional 'from', 'to' and 'resolution'.
+func (a *API) GetBatteryTimeSeriesHandler(c *gin.Context) {
+	deviceID, err := strconv.ParseInt(c.Query("device_id"), 10, 64)
+	if err != nil || deviceID <= 0 {
+		appErr := response.NewAppError(http.StatusBadRequest, "Invalid 'device_id'. Expected a positive integer", err)
+		response.HandleError(c, appErr)
+		return
+	}
+
+	resp, found, err := a.Service.GetBatteryTimeSeries(deviceID, c.Query("from"), c.Query("to"), c.DefaultQuery("resolution", "auto"))
+	if errors.Is(err, anomaly.ErrInvalidTimeSeries) {
+		response.HandleError(c, response.NewAppError(http.StatusBadRequest, err.Error(), err))
+		return
+	}
+	if err != nil {
+		log.WriteLog.Error("Battery time series error", zap.Error(err))
+		response.HandleError(c, err)
+		return
+	}
+
+	if !found {
+		appErr := response.NewAppError(http.StatusNotFound, "No battery blocks found for this device in the range", nil)
+		response.HandleError(c, appErr)
+		return
+	}
+
+	log.WriteLog.Info("Fetched battery time series", zap.Int64("device_id", deviceID), zap.String("resolution", resp.Resolution), zap.Int("points", len(resp.Points)))
+	response.HandleSuccess(c, http.StatusOK, resp)
+}
+
+// GetBatteryWearHandler returns the charge cycles and charging habits of one device,
+// or of every device without 'device_id'.
+func (a *API) GetBatteryWearHandler(c *gin.Context) {
+	var deviceID int64
+	if idStr := c.Query("device_id"); idStr != "" && idStr != "all" {
+		id, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil || id <= 0 {
+			appErr := response.NewAppError(http.StatusBadRequest, "Invalid 'device_id'. Expected a positive integer", err)
+			response.HandleError(c, appErr)
+			return
+		}
+		deviceID = id
+	}
+
+	resp, err := a.Service.GetBatteryWear(deviceID)
+	if err != nil {
+		log.WriteLog.Error("Battery wear error", zap.Error(err))
+		response.HandleError(c, err)
+		return
+	}
+
+	if deviceID != 0 && len(resp.Devices) == 0 {
+		appErr := response.NewAppError(http.StatusNotFound, "No battery wear found for this device", nil)
+		response.HandleError(c, appErr)
+		return
+	}
+
+	log.WriteLog.Info("Fetched battery wear", zap.Int("count", len(resp.Devices)))
+	response.HandleSuccess(c, http.StatusOK, resp)
+}
+
+// GetFleetBatteryWearHandler returns the fleet distribution of each wear metric.
+func (a *API) GetFleetBatteryWearHandler(c *gin.Context) {
+	resp, err := a.Service.GetFleetBatteryWear()
+	if err != nil {
+		log.WriteLog.Error("Fleet battery wear error", zap.Error(err))
+		response.HandleError(c, err)
+		return
+	}
+
+	log.WriteLog.Info("Fetched fleet battery wear", zap.Int("devices", resp.Devices))
+	response.HandleSuccess(c, http.StatusOK, resp)
+}
+
+// GetBatteryKPITrendHandler returns the fleet battery KPI snapshots between the
+// optional 'from' and 'to'.
+func (a *API) GetBatteryKPITrendHandler(c *gin.Context) {
+	resp, err := a.Service.GetBatteryKPITrend(c.Query("from"), c.Query("to"))
+	if errors.Is(err, anomaly.ErrInvalidDateRange) {
+		response.HandleError(c, response.NewAppError(http.StatusBadRequest, err.Error(), err))
+		return
+	}
+	if err != nil {
+		log.WriteLog.Error("Battery KPI trend error", zap.Error(err))
+		response.HandleError(c, err)
+		return
+	}
+
+	log.WriteLog.Info("Fetched battery KPI trend", zap.Int("snapshots", len(resp.Snapshots)))
+	response.HandleSuccess(c, http.StatusOK, resp)
+}
+
+// GetBatteryDataQualityHandler reports gaps, overlaps, duplicates and level
+// discontinuities in the battery blocks of one device, with its issues, or of every
+// device without 'device_id', over the last month unless 'time' says otherwise.
+func (a *API) GetBatteryDataQualityHandler(c *gin.Context) {
+	timeFilter := c.DefaultQuery("time", "1m")
+
+	var deviceID int64
+	if idStr := c.Query("device_id"); idStr != "" && idStr != "all" {
+		id, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil || id <= 0 {
+			appErr := response.NewAppError(http.StatusBadRequest, "Invalid 'device_id'. Expected a positive integer", err)
+			response.HandleError(c, appErr)
+			return
+		}
+		deviceID = id
+	}
+
+	resp, found, err := a.Service.GetBatteryDataQuality(deviceID, timeFilter)
+	if err != nil {
+		log.WriteLog.Error("Battery data quality error", zap.Error(err))
+		response.HandleError(c, err)
+		return
+	}
+
+	if !found {
+		appErr := response.NewAppError(http.StatusNotFound, "No battery blocks found for this device", nil)
+		response.HandleError(c, appErr)
+		return
+	}
+
+	log.WriteLog.Info("Fetched battery data quality", zap.Int("count", len(resp.Devices)))
+	response.HandleSuccess(c, http.StatusOK, resp)
+}
+
+// GetFleetDataQualityHandler summarizes battery data quality over the fleet.
+func (a *API) GetFleetDataQualityHandler(c *gin.Context) {
+	resp, err := a.Service.GetFleetDataQuality(c.DefaultQuery("time", "1m"))
+	if err != nil {
+		log.WriteLog.Error("Fleet data quality error", zap.Error(err))
+		response.HandleError(c, err)
+		return
+	}
+
+	log.WriteLog.Info("Fetched fleet data quality", zap.Int("devices", resp.Devices))
+	response.HandleSuccess(c, http.StatusOK, resp)
+}
@@ -0,0 +1,61 @@
+// File: controller/drift_controller.go
+
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"anomaly-go/log"
+	model "anomaly-go/model/postgres"
+	anomaly "anomaly-go/service/anomaly"
+	"anomaly-go/util/httputils/response"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// GetDriftSeriesHandler returns the score drift series of the fleet, or of one device
+// when 'device_id' is given.
+func (a *API) GetDriftSeriesHandler(c *gin.Context) {
+	filter := model.DriftFilter{
+		Time:   c.Query("time"),
+		Metric: c.DefaultQuery("metric", "all"),
+	}
+
+	switch filter.Metric {
+	case "all", anomaly.DriftMetricConfidence, anomaly.DriftMetricLabelMix:
+	default:
+		appErr := response.NewAppError(http.StatusBadRequest, "Invalid 'metric'. Expected 'confidence', 'label_mix' or 'all'", nil)
+		response.HandleError(c, appErr)
+		return
+	}
+	if idStr := c.Query("device_id"); idStr != "" && idStr != "all" {
+		id, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil || id <= 0 {
+			appErr := response.NewAppError(http.StatusBadRequest, "Invalid 'device_id'. Expected a positive integer", err)
+			response.HandleError(c, appErr)
+			return
+		}
+		filter.DeviceID = id
+	}
+	if alertsOnly := c.Query("alerts_only"); alertsOnly != "" {
+		value, err := strconv.ParseBool(alertsOnly)
+		if err != nil {
+			appErr := response.NewAppError(http.StatusBadRequest, "Invalid 'alerts_only'. Expected true or false", err)
+			response.HandleError(c, appErr)
+			return
+		}
+		filter.AlertsOnly = value
+	}
+
+	resp, err := a.Service.GetDriftSeries(filter)
+	if err != nil {
+		log.WriteLog.Error("Get drift series error", zap.Error(err))
+		response.HandleError(c, err)
+		return
+	}
+
+	log.WriteLog.Info("Fetched drift series", zap.Int("count", len(resp.Series)))
+	response.HandleSuccess(c, http.StatusOK, resp)
+}
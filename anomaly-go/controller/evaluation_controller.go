@@ -0,0 +1,69 @@
+// File: controller/evaluation_controller.go
+
+package controller
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"anomaly-go/log"
+	anomaly "anomaly-go/service/anomaly"
+	"anomaly-go/util/httputils/response"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// GetModelEvaluationHandler reports model precision, recall and false positive rate
+// against reviewer verdicts, per time window and per device. It accepts the /fetchData filters.
+func (a *API) GetModelEvaluationHandler(c *gin.Context) {
+	filter := transactionFilterFromQuery(c)
+	window := strings.ToLower(c.DefaultQuery("window", "day"))
+
+	if !anomaly.EvaluationWindows[window] {
+		appErr := response.NewAppError(http.StatusBadRequest, "Invalid 'window'. Expected one of hour, day, week, month", nil)
+		response.HandleError(c, appErr)
+		return
+	}
+
+	thresholds, err := parseThresholds(c.Query("thresholds"))
+	if err != nil {
+		appErr := response.NewAppError(http.StatusBadRequest, "Invalid 'thresholds'. Expected a comma-separated list of integers between 0 and 100", err)
+		response.HandleError(c, appErr)
+		return
+	}
+
+	resp, err := a.Service.EvaluateModel(filter, window, thresholds)
+	if err != nil {
+		log.WriteLog.Error("Model evaluation error", zap.Error(err))
+		response.HandleError(c, err)
+		return
+	}
+
+	log.WriteLog.Info("Evaluated model", zap.String("window", window), zap.Int("reviewed", resp.Overall.Reviewed))
+	response.HandleSuccess(c, http.StatusOK, resp)
+}
+
+// parseThresholds parses a comma-separated list of confidence thresholds, falling back
+// to the default curve when the list is empty.
+func parseThresholds(raw string) ([]int, error) {
+	if strings.TrimSpace(raw) == "" {
+		return anomaly.DefaultEvaluationThresholds, nil
+	}
+
+	var thresholds []int
+	for _, part := range strings.Split(raw, ",") {
+		t, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil {
+			return nil, err
+		}
+		if t < 0 || t > 100 {
+			return nil, strconv.ErrRange
+		}
+		thresholds = append(thresholds, t)
+	}
+	sort.Ints(thresholds)
+	return thresholds, nil
+}
@@ -0,0 +1,41 @@
+// File: controller/heatmap_controller.go
+
+package controller
+
+import (
+	"net/http"
+	"time"
+
+	"anomaly-go/log"
+	"anomaly-go/util/httputils/response"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// GetAnomalyHeatmapHandler returns 7x24 matrices of transactions, anomalies and
+// anomaly rates by weekday and hour for the transactions matching the /fetchData
+// filters. 'tz' takes an IANA time zone such as Asia/Kolkata so buckets follow the
+// merchants' local hours; without it times are bucketed as stored.
+func (a *API) GetAnomalyHeatmapHandler(c *gin.Context) {
+	filter := transactionFilterFromQuery(c)
+
+	timeZone := c.Query("tz")
+	if timeZone != "" {
+		if _, err := time.LoadLocation(timeZone); err != nil || timeZone == "Local" {
+			appErr := response.NewAppError(http.StatusBadRequest, "Invalid 'tz'. Expected an IANA time zone such as Asia/Kolkata", err)
+			response.HandleError(c, appErr)
+			return
+		}
+	}
+
+	resp, err := a.Service.GetAnomalyHeatmap(filter, timeZone)
+	if err != nil {
+		log.WriteLog.Error("Anomaly heatmap error", zap.Error(err))
+		response.HandleError(c, err)
+		return
+	}
+
+	log.WriteLog.Info("Fetched anomaly heatmap", zap.String("timezone", resp.TimeZone), zap.Int("transactions", resp.Total))
+	response.HandleSuccess(c, http.StatusOK, resp)
+}
@@ -0,0 +1,34 @@
+// File: controller/ingest_controller.go
+
+package controller
+
+import (
+	"net/http"
+
+	"anomaly-go/log"
+	jsonmodel "anomaly-go/model/json"
+	"anomaly-go/util/httputils/response"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// IngestTransactionsHandler scores and stores a batch of new transactions.
+func (a *API) IngestTransactionsHandler(c *gin.Context) {
+	var req jsonmodel.IngestTransactionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		appErr := response.NewAppError(http.StatusBadRequest, "Invalid request body. Expected 'transactions' with 1 to 1000 entries", err)
+		response.HandleError(c, appErr)
+		return
+	}
+
+	resp, err := a.Service.IngestTransactions(req.Transactions)
+	if err != nil {
+		log.WriteLog.Error("Ingest transactions error", zap.Error(err))
+		response.HandleError(c, err)
+		return
+	}
+
+	log.WriteLog.Info("Ingested transaction batch", zap.Int("received", len(req.Transactions)), zap.Int("scored", resp.Scored))
+	response.HandleSuccess(c, http.StatusOK, resp)
+}
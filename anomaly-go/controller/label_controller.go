@@ -0,0 +1,105 @@
+// File: controller/label_controller.go
+
+package controller
+
+import (
+	"net/http"
+
+	"anomaly-go/log"
+	jsonmodel "anomaly-go/model/json"
+	"anomaly-go/util/httputils/response"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// GetLabelsHandler fetches the label taxonomy.
+func (a *API) GetLabelsHandler(c *gin.Context) {
+	labels, err := a.Service.GetLabels()
+	if err != nil {
+		log.WriteLog.Error("Get labels error", zap.Error(err))
+		response.HandleError(c, err)
+		return
+	}
+
+	log.WriteLog.Info("Fetched labels", zap.Int("count", len(labels)))
+	response.HandleSuccess(c, http.StatusOK, gin.H{"labels": labels})
+}
+
+// CreateLabelHandler adds a label to the taxonomy (admin only).
+func (a *API) CreateLabelHandler(c *gin.Context) {
+	var req jsonmodel.Label
+	if err := c.ShouldBindJSON(&req); err != nil {
+		appErr := response.NewAppError(http.StatusBadRequest, "Invalid request body. Expected 'name', 'display_name', 'severity' (0-3) and 'color' (hex)", err)
+		response.HandleError(c, appErr)
+		return
+	}
+
+	rowsAffected, err := a.Service.CreateLabel(req)
+	if err != nil {
+		log.WriteLog.Error("Create label error", zap.Error(err))
+		response.HandleError(c, err)
+		return
+	}
+
+	if rowsAffected == 0 {
+		appErr := response.NewAppError(http.StatusConflict, "Label already exists", nil)
+		response.HandleError(c, appErr)
+		return
+	}
+
+	log.WriteLog.Info("Label created", zap.String("name", req.Name))
+	response.HandleSuccess(c, http.StatusCreated, gin.H{"message": "Label created successfully"})
+}
+
+// UpdateLabelHandler updates an existing label (admin only).
+func (a *API) UpdateLabelHandler(c *gin.Context) {
+	var req jsonmodel.Label
+	if err := c.ShouldBindJSON(&req); err != nil {
+		appErr := response.NewAppError(http.StatusBadRequest, "Invalid request body. Expected 'name', 'display_name', 'severity' (0-3) and 'color' (hex)", err)
+		response.HandleError(c, appErr)
+		return
+	}
+
+	rowsAffected, err := a.Service.UpdateLabel(req)
+	if err != nil {
+		log.WriteLog.Error("Update label error", zap.Error(err))
+		response.HandleError(c, err)
+		return
+	}
+
+	if rowsAffected == 0 {
+		appErr := response.NewAppError(http.StatusNotFound, "No label found to update", nil)
+		response.HandleError(c, appErr)
+		return
+	}
+
+	log.WriteLog.Info("Label updated", zap.String("name", req.Name))
+	response.HandleSuccess(c, http.StatusOK, gin.H{"message": "Label updated successfully"})
+}
+
+// DeleteLabelHandler removes a label from the taxonomy (admin only).
+func (a *API) DeleteLabelHandler(c *gin.Context) {
+	name := c.Query("name")
+	if name == "" {
+		appErr := response.NewAppError(http.StatusBadRequest, "Query parameter 'name' is required", nil)
+		response.HandleError(c, appErr)
+		return
+	}
+
+	rowsAffected, err := a.Service.DeleteLabel(name)
+	if err != nil {
+		log.WriteLog.Error("Delete label error", zap.Error(err))
+		response.HandleError(c, err)
+		return
+	}
+
+	if rowsAffected == 0 {
+		appErr := response.NewAppError(http.StatusNotFound, "No label found to delete", nil)
+		response.HandleError(c, appErr)
+		return
+	}
+
+	log.WriteLog.Info("Label deleted", zap.String("name", name))
+	response.HandleSuccess(c, http.StatusOK, gin.H{"message": "Label deleted successfully"})
+}
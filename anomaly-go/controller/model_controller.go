@@ -0,0 +1,103 @@
+// File: controller/model_controller.go
+
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"anomaly-go/log"
+	jsonmodel "anomaly-go/model/json"
+	anomaly "anomaly-go/service/anomaly"
+	"anomaly-go/util/httputils/response"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// GetModelsHandler fetches the model registry.
+func (a *API) GetModelsHandler(c *gin.Context) {
+	models, err := a.Service.GetModels()
+	if err != nil {
+		log.WriteLog.Error("Get models error", zap.Error(err))
+		response.HandleError(c, err)
+		return
+	}
+
+	log.WriteLog.Info("Fetched model registry", zap.Int("count", len(models)))
+	response.HandleSuccess(c, http.StatusOK, gin.H{"models": models})
+}
+
+// RegisterModelHandler adds a model version to the registry (admin only).
+func (a *API) RegisterModelHandler(c *gin.Context) {
+	var req jsonmodel.ModelVersion
+	if err := c.ShouldBindJSON(&req); err != nil {
+		appErr := response.NewAppError(http.StatusBadRequest, "Invalid request body. Expected 'name', 'version' and optional 'parameters'", err)
+		response.HandleError(c, appErr)
+		return
+	}
+	if err := a.Service.ValidateModel(req); err != nil {
+		response.HandleError(c, response.NewAppError(http.StatusBadRequest, "Invalid model parameters: "+err.Error(), err))
+		return
+	}
+
+	id, rowsAffected, err := a.Service.RegisterModel(req)
+	if err != nil {
+		log.WriteLog.Error("Register model error", zap.Error(err))
+		response.HandleError(c, err)
+		return
+	}
+
+	if rowsAffected == 0 {
+		appErr := response.NewAppError(http.StatusConflict, "This model version is already registered", nil)
+		response.HandleError(c, appErr)
+		return
+	}
+
+	log.WriteLog.Info("Model registered", zap.String("name", req.Name), zap.String("version", req.Version))
+	response.HandleSuccess(c, http.StatusCreated, gin.H{"message": "Model registered successfully", "id": id})
+}
+
+// ActivateModelHandler switches the scorer used on ingest (admin only).
+func (a *API) ActivateModelHandler(c *gin.Context) {
+	var req jsonmodel.ActivateModelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		appErr := response.NewAppError(http.StatusBadRequest, "Invalid request body. Expected 'id'", err)
+		response.HandleError(c, appErr)
+		return
+	}
+
+	found, err := a.Service.ActivateModel(req.ID)
+	if errors.Is(err, anomaly.ErrModelNotRunnable) {
+		response.HandleError(c, response.NewAppError(http.StatusBadRequest, err.Error(), err))
+		return
+	}
+	if err != nil {
+		log.WriteLog.Error("Activate model error", zap.Error(err))
+		response.HandleError(c, err)
+		return
+	}
+
+	if !found {
+		appErr := response.NewAppError(http.StatusNotFound, "No model found to activate", nil)
+		response.HandleError(c, appErr)
+		return
+	}
+
+	log.WriteLog.Info("Model activated", zap.Uint("id", req.ID))
+	response.HandleSuccess(c, http.StatusOK, gin.H{"message": "Model activated successfully"})
+}
+
+// CompareModelVersionsHandler compares the results of each model version among the
+// transactions matching the /fetchData filters.
+func (a *API) CompareModelVersionsHandler(c *gin.Context) {
+	comparisons, err := a.Service.CompareModelVersions(transactionFilterFromQuery(c))
+	if err != nil {
+		log.WriteLog.Error("Compare model versions error", zap.Error(err))
+		response.HandleError(c, err)
+		return
+	}
+
+	log.WriteLog.Info("Compared model versions", zap.Int("count", len(comparisons)))
+	response.HandleSuccess(c, http.StatusOK, gin.H{"model_versions": comparisons})
+}
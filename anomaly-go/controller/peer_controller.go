@@ -0,0 +1,54 @@
+// File: controller/peer_controller.go
+
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"anomaly-go/log"
+	"anomaly-go/util/httputils/response"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// GetPeerBenchmarkHandler places a device against its peer group, or lists the
+// devices far outside their peer group when no 'device_id' is given. Peer groups are
+// built from the transactions matching the other /fetchData filters, over the last
+// month unless 'time' says otherwise.
+func (a *API) GetPeerBenchmarkHandler(c *gin.Context) {
+	filter := transactionFilterFromQuery(c)
+	if filter.Time == "" {
+		filter.Time = "1m"
+	}
+
+	var deviceID int64
+	if filter.DeviceID != "" && filter.DeviceID != "all" {
+		id, err := strconv.ParseInt(filter.DeviceID, 10, 64)
+		if err != nil || id <= 0 {
+			appErr := response.NewAppError(http.StatusBadRequest, "Invalid 'device_id'. Expected a positive integer", err)
+			response.HandleError(c, appErr)
+			return
+		}
+		deviceID = id
+	}
+	// Peers come from the whole fleet, not only the requested device.
+	filter.DeviceID = ""
+
+	resp, found, err := a.Service.BenchmarkPeers(filter, deviceID)
+	if err != nil {
+		log.WriteLog.Error("Peer benchmark error", zap.Error(err))
+		response.HandleError(c, err)
+		return
+	}
+
+	if !found {
+		appErr := response.NewAppError(http.StatusNotFound, "No transactions found for this device", nil)
+		response.HandleError(c, appErr)
+		return
+	}
+
+	log.WriteLog.Info("Benchmarked devices against peers", zap.Int("count", len(resp.Devices)))
+	response.HandleSuccess(c, http.StatusOK, resp)
+}
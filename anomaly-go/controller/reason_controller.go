@@ -0,0 +1,35 @@
+// File: controller/reason_controller.go
+
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"anomaly-go/log"
+	"anomaly-go/util/httputils/response"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// GetTopReasonsHandler returns the most frequent reason codes of the transactions
+// matching the /fetchData filters.
+func (a *API) GetTopReasonsHandler(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit < 1 || limit > 100 {
+		appErr := response.NewAppError(http.StatusBadRequest, "Invalid 'limit'. Expected an integer between 1 and 100", err)
+		response.HandleError(c, appErr)
+		return
+	}
+
+	resp, err := a.Service.GetTopReasons(transactionFilterFromQuery(c), limit)
+	if err != nil {
+		log.WriteLog.Error("Get top reasons error", zap.Error(err))
+		response.HandleError(c, err)
+		return
+	}
+
+	log.WriteLog.Info("Fetched top reasons", zap.Int("count", len(resp.Reasons)))
+	response.HandleSuccess(c, http.StatusOK, resp)
+}
@@ -0,0 +1,114 @@
+// File: controller/rescore_controller.go
+
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"anomaly-go/log"
+	jsonmodel "anomaly-go/model/json"
+	anomaly "anomaly-go/service/anomaly"
+	"anomaly-go/util/httputils/response"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// StartRescoreHandler starts a rescore job, or resumes a paused one (admin only).
+func (a *API) StartRescoreHandler(c *gin.Context) {
+	var req jsonmodel.StartRescoreRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		appErr := response.NewAppError(http.StatusBadRequest, "Invalid request body. Expected optional 'job_id', 'target' (live or shadow), 'from', 'to' and 'chunk_size' (1-5000)", err)
+		response.HandleError(c, appErr)
+		return
+	}
+	if err := a.Service.ValidateRescoreRequest(req); err != nil {
+		response.HandleError(c, response.NewAppError(http.StatusBadRequest, err.Error(), err))
+		return
+	}
+
+	job, found, err := a.Service.StartRescore(req)
+	if errors.Is(err, anomaly.ErrRescoreRunning) || errors.Is(err, anomaly.ErrRescoreNotResumable) {
+		response.HandleError(c, response.NewAppError(http.StatusConflict, err.Error(), err))
+		return
+	}
+	if errors.Is(err, anomaly.ErrModelNotRunnable) {
+		response.HandleError(c, response.NewAppError(http.StatusBadRequest, err.Error(), err))
+		return
+	}
+	if err != nil {
+		log.WriteLog.Error("Start rescore error", zap.Error(err))
+		response.HandleError(c, err)
+		return
+	}
+
+	if !found {
+		appErr := response.NewAppError(http.StatusNotFound, "No rescore job found to resume", nil)
+		response.HandleError(c, appErr)
+		return
+	}
+
+	log.WriteLog.Info("Rescore job started", zap.Uint("id", job.ID))
+	response.HandleSuccess(c, http.StatusAccepted, gin.H{"message": "Rescore job started", "job": job})
+}
+
+// PauseRescoreHandler pauses the running rescore job after its current chunk (admin only).
+func (a *API) PauseRescoreHandler(c *gin.Context) {
+	var req jsonmodel.PauseRescoreRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		appErr := response.NewAppError(http.StatusBadRequest, "Invalid request body. Expected 'id'", err)
+		response.HandleError(c, appErr)
+		return
+	}
+
+	found, err := a.Service.PauseRescore(req.ID)
+	if errors.Is(err, anomaly.ErrRescoreNotRunning) {
+		response.HandleError(c, response.NewAppError(http.StatusConflict, err.Error(), err))
+		return
+	}
+	if err != nil {
+		log.WriteLog.Error("Pause rescore error", zap.Error(err))
+		response.HandleError(c, err)
+		return
+	}
+
+	if !found {
+		appErr := response.NewAppError(http.StatusNotFound, "No rescore job found to pause", nil)
+		response.HandleError(c, appErr)
+		return
+	}
+
+	log.WriteLog.Info("Rescore job pause requested", zap.Uint("id", req.ID))
+	response.HandleSuccess(c, http.StatusOK, gin.H{"message": "Rescore job pauses after its current chunk"})
+}
+
+// GetRescoreStatusHandler reports the progress of a rescore job, the latest one when
+// no 'id' is given.
+func (a *API) GetRescoreStatusHandler(c *gin.Context) {
+	var id uint64
+	if idStr := c.Query("id"); idStr != "" {
+		var err error
+		id, err = strconv.ParseUint(idStr, 10, 32)
+		if err != nil || id == 0 {
+			response.HandleError(c, response.NewAppError(http.StatusBadRequest, "Query parameter 'id' must be a positive integer", err))
+			return
+		}
+	}
+
+	job, found, err := a.Service.GetRescoreStatus(uint(id))
+	if err != nil {
+		log.WriteLog.Error("Get rescore status error", zap.Error(err))
+		response.HandleError(c, err)
+		return
+	}
+
+	if !found {
+		appErr := response.NewAppError(http.StatusNotFound, "No rescore job found", nil)
+		response.HandleError(c, appErr)
+		return
+	}
+
+	response.HandleSuccess(c, http.StatusOK, gin.H{"job": job})
+}
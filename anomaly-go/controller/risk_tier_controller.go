@@ -0,0 +1,117 @@
+// File: controller/risk_tier_controller.go
+
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"anomaly-go/log"
+	jsonmodel "anomaly-go/model/json"
+	anomaly "anomaly-go/service/anomaly"
+	"anomaly-go/util/httputils/response"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const riskTierBodyHint = "Invalid request body. Expected 'name', 'display_name', 'max_score' (omit for the top tier), 'at_risk' and 'color' (hex)"
+
+// GetRiskTiersHandler fetches the battery risk tiers.
+func (a *API) GetRiskTiersHandler(c *gin.Context) {
+	tiers, err := a.Service.GetRiskTiers()
+	if err != nil {
+		log.WriteLog.Error("Get risk tiers error", zap.Error(err))
+		response.HandleError(c, err)
+		return
+	}
+
+	log.WriteLog.Info("Fetched risk tiers", zap.Int("count", len(tiers)))
+	response.HandleSuccess(c, http.StatusOK, gin.H{"tiers": tiers})
+}
+
+// CreateRiskTierHandler adds a battery risk tier (admin only).
+func (a *API) CreateRiskTierHandler(c *gin.Context) {
+	var req jsonmodel.RiskTier
+	if err := c.ShouldBindJSON(&req); err != nil {
+		appErr := response.NewAppError(http.StatusBadRequest, riskTierBodyHint, err)
+		response.HandleError(c, appErr)
+		return
+	}
+
+	rowsAffected, err := a.Service.CreateRiskTier(req)
+	if errors.Is(err, anomaly.ErrRiskTierBoundaryTaken) {
+		response.HandleError(c, response.NewAppError(http.StatusConflict, err.Error(), err))
+		return
+	}
+	if err != nil {
+		log.WriteLog.Error("Create risk tier error", zap.Error(err))
+		response.HandleError(c, err)
+		return
+	}
+
+	if rowsAffected == 0 {
+		appErr := response.NewAppError(http.StatusConflict, "Risk tier already exists", nil)
+		response.HandleError(c, appErr)
+		return
+	}
+
+	log.WriteLog.Info("Risk tier created", zap.String("name", req.Name))
+	response.HandleSuccess(c, http.StatusCreated, gin.H{"message": "Risk tier created successfully"})
+}
+
+// UpdateRiskTierHandler updates an existing battery risk tier (admin only).
+func (a *API) UpdateRiskTierHandler(c *gin.Context) {
+	var req jsonmodel.RiskTier
+	if err := c.ShouldBindJSON(&req); err != nil {
+		appErr := response.NewAppError(http.StatusBadRequest, riskTierBodyHint, err)
+		response.HandleError(c, appErr)
+		return
+	}
+
+	rowsAffected, err := a.Service.UpdateRiskTier(req)
+	if errors.Is(err, anomaly.ErrRiskTierBoundaryTaken) {
+		response.HandleError(c, response.NewAppError(http.StatusConflict, err.Error(), err))
+		return
+	}
+	if err != nil {
+		log.WriteLog.Error("Update risk tier error", zap.Error(err))
+		response.HandleError(c, err)
+		return
+	}
+
+	if rowsAffected == 0 {
+		appErr := response.NewAppError(http.StatusNotFound, "No risk tier found to update", nil)
+		response.HandleError(c, appErr)
+		return
+	}
+
+	log.WriteLog.Info("Risk tier updated", zap.String("name", req.Name))
+	response.HandleSuccess(c, http.StatusOK, gin.H{"message": "Risk tier updated successfully"})
+}
+
+// DeleteRiskTierHandler removes a battery risk tier (admin only).
+func (a *API) DeleteRiskTierHandler(c *gin.Context) {
+	name := c.Query("name")
+	if name == "" {
+		appErr := response.NewAppError(http.StatusBadRequest, "Query parameter 'name' is required", nil)
+		response.HandleError(c, appErr)
+		return
+	}
+
+	rowsAffected, err := a.Service.DeleteRiskTier(name)
+	if err != nil {
+		log.WriteLog.Error("Delete risk tier error", zap.Error(err))
+		response.HandleError(c, err)
+		return
+	}
+
+	if rowsAffected == 0 {
+		appErr := response.NewAppError(http.StatusNotFound, "No risk tier found to delete", nil)
+		response.HandleError(c, appErr)
+		return
+	}
+
+	log.WriteLog.Info("Risk tier deleted", zap.String("name", name))
+	response.HandleSuccess(c, http.StatusOK, gin.H{"message": "Risk tier deleted successfully"})
+}
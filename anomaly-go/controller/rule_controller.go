@@ -0,0 +1,114 @@
+// File: controller/rule_controller.go
+
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"anomaly-go/log"
+	jsonmodel "anomaly-go/model/json"
+	"anomaly-go/util/httputils/response"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// GetRulesHandler fetches all fraud rules.
+func (a *API) GetRulesHandler(c *gin.Context) {
+	rules, err := a.Service.GetRules()
+	if err != nil {
+		log.WriteLog.Error("Get rules error", zap.Error(err))
+		response.HandleError(c, err)
+		return
+	}
+
+	log.WriteLog.Info("Fetched fraud rules", zap.Int("count", len(rules)))
+	response.HandleSuccess(c, http.StatusOK, gin.H{"rules": rules})
+}
+
+// CreateRuleHandler adds a fraud rule (admin only).
+func (a *API) CreateRuleHandler(c *gin.Context) {
+	var req jsonmodel.FraudRule
+	if err := c.ShouldBindJSON(&req); err != nil {
+		appErr := response.NewAppError(http.StatusBadRequest, "Invalid request body. Expected 'name' and 'definition'", err)
+		response.HandleError(c, appErr)
+		return
+	}
+	if err := a.Service.ValidateRule(req.Name, req.Definition); err != nil {
+		response.HandleError(c, response.NewAppError(http.StatusBadRequest, err.Error(), err))
+		return
+	}
+
+	id, rowsAffected, err := a.Service.CreateRule(req)
+	if err != nil {
+		log.WriteLog.Error("Create rule error", zap.Error(err))
+		response.HandleError(c, err)
+		return
+	}
+
+	if rowsAffected == 0 {
+		appErr := response.NewAppError(http.StatusConflict, "A rule with this name already exists", nil)
+		response.HandleError(c, appErr)
+		return
+	}
+
+	log.WriteLog.Info("Fraud rule created", zap.String("name", req.Name), zap.Uint("id", id))
+	response.HandleSuccess(c, http.StatusCreated, gin.H{"message": "Rule created successfully", "id": id})
+}
+
+// UpdateRuleHandler updates a fraud rule (admin only).
+func (a *API) UpdateRuleHandler(c *gin.Context) {
+	var req jsonmodel.FraudRule
+	if err := c.ShouldBindJSON(&req); err != nil || req.ID == 0 {
+		appErr := response.NewAppError(http.StatusBadRequest, "Invalid request body. Expected 'id', 'name' and 'definition'", err)
+		response.HandleError(c, appErr)
+		return
+	}
+	if err := a.Service.ValidateRule(req.Name, req.Definition); err != nil {
+		response.HandleError(c, response.NewAppError(http.StatusBadRequest, err.Error(), err))
+		return
+	}
+
+	rowsAffected, err := a.Service.UpdateRule(req)
+	if err != nil {
+		log.WriteLog.Error("Update rule error", zap.Error(err))
+		response.HandleError(c, err)
+		return
+	}
+
+	if rowsAffected == 0 {
+		appErr := response.NewAppError(http.StatusNotFound, "No rule found to update", nil)
+		response.HandleError(c, appErr)
+		return
+	}
+
+	log.WriteLog.Info("Fraud rule updated", zap.Uint("id", req.ID))
+	response.HandleSuccess(c, http.StatusOK, gin.H{"message": "Rule updated successfully"})
+}
+
+// DeleteRuleHandler removes a fraud rule (admin only).
+func (a *API) DeleteRuleHandler(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Query("id"), 10, 0)
+	if err != nil || id == 0 {
+		appErr := response.NewAppError(http.StatusBadRequest, "Query parameter 'id' must be a positive integer", err)
+		response.HandleError(c, appErr)
+		return
+	}
+
+	rowsAffected, err := a.Service.DeleteRule(uint(id))
+	if err != nil {
+		log.WriteLog.Error("Delete rule error", zap.Error(err))
+		response.HandleError(c, err)
+		return
+	}
+
+	if rowsAffected == 0 {
+		appErr := response.NewAppError(http.StatusNotFound, "No rule found to delete", nil)
+		response.HandleError(c, appErr)
+		return
+	}
+
+	log.WriteLog.Info("Fraud rule deleted", zap.Uint64("id", id))
+	response.HandleSuccess(c, http.StatusOK, gin.H{"message": "Rule deleted successfully"})
+}
@@ -0,0 +1,41 @@
+// File: controller/seasonality_controller.go
+
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"anomaly-go/log"
+	"anomaly-go/util/httputils/response"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// GetSeasonalityProfileHandler returns the expected transaction count of a device
+// for each hour of the week against the actual count of the last seven days.
+func (a *API) GetSeasonalityProfileHandler(c *gin.Context) {
+	deviceID, err := strconv.ParseInt(c.Query("device_id"), 10, 64)
+	if err != nil || deviceID <= 0 {
+		appErr := response.NewAppError(http.StatusBadRequest, "Invalid 'device_id'. Expected a positive integer", err)
+		response.HandleError(c, appErr)
+		return
+	}
+
+	resp, found, err := a.Service.GetSeasonalityProfile(deviceID)
+	if err != nil {
+		log.WriteLog.Error("Seasonality profile error", zap.Error(err))
+		response.HandleError(c, err)
+		return
+	}
+
+	if !found {
+		appErr := response.NewAppError(http.StatusNotFound, "No seasonal profile found for this device", nil)
+		response.HandleError(c, appErr)
+		return
+	}
+
+	log.WriteLog.Info("Fetched seasonality profile", zap.Int64("device_id", deviceID))
+	response.HandleSuccess(c, http.StatusOK, resp)
+}
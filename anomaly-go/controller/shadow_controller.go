@@ -0,0 +1,79 @@
+// File: controller/shadow_controller.go
+
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"anomaly-go/log"
+	jsonmodel "anomaly-go/model/json"
+	anomaly "anomaly-go/service/anomaly"
+	"anomaly-go/util/httputils/response"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// StartShadowHandler runs a candidate model and/or threshold in shadow mode (admin only).
+func (a *API) StartShadowHandler(c *gin.Context) {
+	var req jsonmodel.StartShadowRequest
+	if err := c.ShouldBindJSON(&req); err != nil || (req.ModelID == nil && req.Threshold == nil) {
+		appErr := response.NewAppError(http.StatusBadRequest, "Invalid request body. Expected 'model_id' and/or 'threshold' (0-100)", err)
+		response.HandleError(c, appErr)
+		return
+	}
+
+	found, err := a.Service.StartShadow(req)
+	if errors.Is(err, anomaly.ErrModelNotRunnable) {
+		response.HandleError(c, response.NewAppError(http.StatusBadRequest, err.Error(), err))
+		return
+	}
+	if err != nil {
+		log.WriteLog.Error("Start shadow mode error", zap.Error(err))
+		response.HandleError(c, err)
+		return
+	}
+
+	if !found {
+		appErr := response.NewAppError(http.StatusNotFound, "No model found to run in shadow mode", nil)
+		response.HandleError(c, appErr)
+		return
+	}
+
+	log.WriteLog.Info("Shadow mode started")
+	response.HandleSuccess(c, http.StatusOK, gin.H{"message": "Shadow mode started successfully"})
+}
+
+// StopShadowHandler ends shadow mode (admin only).
+func (a *API) StopShadowHandler(c *gin.Context) {
+	rowsAffected, err := a.Service.StopShadow()
+	if err != nil {
+		log.WriteLog.Error("Stop shadow mode error", zap.Error(err))
+		response.HandleError(c, err)
+		return
+	}
+
+	if rowsAffected == 0 {
+		appErr := response.NewAppError(http.StatusNotFound, "No shadow candidate is running", nil)
+		response.HandleError(c, appErr)
+		return
+	}
+
+	log.WriteLog.Info("Shadow mode stopped")
+	response.HandleSuccess(c, http.StatusOK, gin.H{"message": "Shadow mode stopped successfully"})
+}
+
+// CompareShadowHandler compares live and shadow labels among the transactions matching
+// the /fetchData filters (admin only, shadow labels are not shown to reviewers).
+func (a *API) CompareShadowHandler(c *gin.Context) {
+	resp, err := a.Service.CompareShadow(transactionFilterFromQuery(c))
+	if err != nil {
+		log.WriteLog.Error("Compare shadow error", zap.Error(err))
+		response.HandleError(c, err)
+		return
+	}
+
+	log.WriteLog.Info("Compared shadow labels", zap.Int("count", len(resp.Comparisons)))
+	response.HandleSuccess(c, http.StatusOK, resp)
+}
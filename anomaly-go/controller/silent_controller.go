@@ -0,0 +1,62 @@
+// File: controller/silent_controller.go
+
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"anomaly-go/log"
+	model "anomaly-go/model/postgres"
+	anomaly "anomaly-go/service/anomaly"
+	"anomaly-go/util/httputils/response"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// GetSilentDevicesHandler lists devices whose transaction volume dropped far below
+// their seasonal baseline, with the battery based cause. Open findings are returned
+// unless 'status' says otherwise.
+func (a *API) GetSilentDevicesHandler(c *gin.Context) {
+	filter := model.SilentDeviceFilter{
+		Status: c.DefaultQuery("status", "open"),
+		Cause:  c.DefaultQuery("cause", "all"),
+		Time:   c.Query("time"),
+	}
+
+	switch filter.Status {
+	case "open", "resolved", "all":
+	default:
+		appErr := response.NewAppError(http.StatusBadRequest, "Invalid 'status'. Expected 'open', 'resolved' or 'all'", nil)
+		response.HandleError(c, appErr)
+		return
+	}
+	switch filter.Cause {
+	case "all", anomaly.SilentCauseBatteryDead, anomaly.SilentCauseDeviceOffline, anomaly.SilentCauseNoTransactions, anomaly.SilentCauseNoBatteryData:
+	default:
+		appErr := response.NewAppError(http.StatusBadRequest,
+			"Invalid 'cause'. Expected 'battery_dead', 'device_offline', 'no_transactions', 'no_battery_data' or 'all'", nil)
+		response.HandleError(c, appErr)
+		return
+	}
+	if idStr := c.Query("device_id"); idStr != "" && idStr != "all" {
+		id, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil || id <= 0 {
+			appErr := response.NewAppError(http.StatusBadRequest, "Invalid 'device_id'. Expected a positive integer", err)
+			response.HandleError(c, appErr)
+			return
+		}
+		filter.DeviceID = id
+	}
+
+	resp, err := a.Service.GetSilentDevices(filter)
+	if err != nil {
+		log.WriteLog.Error("Get silent devices error", zap.Error(err))
+		response.HandleError(c, err)
+		return
+	}
+
+	log.WriteLog.Info("Fetched silent devices", zap.Int("count", resp.Total))
+	response.HandleSuccess(c, http.StatusOK, resp)
+}
@@ -0,0 +1,42 @@
+// File: controller/structuring_controller.go
+
+package controller
+
+import (
+	"net/http"
+	"strings"
+
+	"anomaly-go/log"
+	anomaly "anomaly-go/service/anomaly"
+	"anomaly-go/util/httputils/response"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// GetStructuringFindingsHandler reports near-limit, round-amount and last-digit
+// findings per device and time window. It accepts the /fetchData filters and looks at
+// the last month unless 'time' says otherwise.
+func (a *API) GetStructuringFindingsHandler(c *gin.Context) {
+	window := strings.ToLower(c.DefaultQuery("window", "day"))
+	if !anomaly.EvaluationWindows[window] {
+		appErr := response.NewAppError(http.StatusBadRequest, "Invalid 'window'. Expected one of hour, day, week, month", nil)
+		response.HandleError(c, appErr)
+		return
+	}
+
+	filter := transactionFilterFromQuery(c)
+	if filter.Time == "" {
+		filter.Time = "1m"
+	}
+
+	resp, err := a.Service.DetectStructuring(filter, window)
+	if err != nil {
+		log.WriteLog.Error("Structuring detection error", zap.Error(err))
+		response.HandleError(c, err)
+		return
+	}
+
+	log.WriteLog.Info("Detected structuring", zap.String("window", window), zap.Int("findings", len(resp.Findings)))
+	response.HandleSuccess(c, http.StatusOK, resp)
+}
@@ -12,24 +12,83 @@ import (
 func BootstrapSchema(db *database.DBStore) error {
 	log.WriteLog.Debug("Initializing database schema")
 
+	// Wear accumulators used to track the last block folded in; blocks up to it are
+	// marked accumulated once, when the accumulated_at column is added.
+	markAccumulated := !db.DB.Migrator().HasColumn(&postgres.DeviceHealth{}, "accumulated_at") &&
+		db.DB.Migrator().HasColumn(&postgres.BatteryWear{}, "last_block")
+
+	// battery_health is created with unquoted, and so lowercase, cs, start_bl and
+	// end_bl columns. Tables migrated from an older model have them in mixed case.
+	for _, column := range [][2]string{{"CS", "cs"}, {"start_BL", "start_bl"}, {"end_BL", "end_bl"}} {
+		m := db.DB.Migrator()
+		if m.HasColumn(postgres.BatteryHealthTable, column[0]) && !m.HasColumn(postgres.BatteryHealthTable, column[1]) {
+			if err := m.RenameColumn(postgres.BatteryHealthTable, column[0], column[1]); err != nil {
+				log.WriteLog.Error("Failed to rename battery_health column", zap.String("column", column[0]), zap.Error(err))
+				return err
+			}
+		}
+	}
+
+	// Blocks accumulated before the accumulated columns were added keep their current
+	// values as the version folded in.
+	snapshotAccumulated := !db.DB.Migrator().HasColumn(&postgres.DeviceHealth{}, "accumulated_cs")
+
 	// AutoMigrate creates tables based on model definitions
 	err := db.DB.AutoMigrate(
 		&postgres.Threshold{},
 		&postgres.Transaction{},
 		&postgres.DeviceHealth{},
 		&postgres.BlScore{},
+		&postgres.Label{},
+		&postgres.DeviceBaseline{},
+		&postgres.AnomalyReason{},
+		&postgres.FraudRule{},
+		&postgres.RuleHit{},
+		&postgres.ModelVersion{},
+		&postgres.RescoreJob{},
+		&postgres.RescoreBaseline{},
+		&postgres.ShadowCandidate{},
+		&postgres.DriftResult{},
+		&postgres.DeviceSeasonality{},
+		&postgres.SilentDevice{},
+		&postgres.BatteryRiskTier{},
+		&postgres.BatteryAnomalyReason{},
+		&postgres.BatteryWear{},
+		&postgres.BatteryKPISnapshot{},
+		&postgres.BatteryKPITier{},
 	)
 	if err != nil {
 		log.WriteLog.Error("Failed to auto-migrate tables", zap.Error(err))
 		return err
 	}
 
+	if markAccumulated {
+		err = db.DB.Exec("UPDATE battery_health h SET accumulated_at = w.updated_at FROM battery_wear w WHERE w.device_id = h.device_id AND h.block <= w.last_block").Error
+		if err != nil {
+			log.WriteLog.Error("Failed to mark accumulated battery blocks", zap.Error(err))
+			return err
+		}
+	}
+
+	if snapshotAccumulated {
+		err = db.DB.Exec(`UPDATE battery_health SET accumulated_cs = cs, accumulated_start_time = start_time,
+			accumulated_end_time = end_time, accumulated_start_bl = start_bl, accumulated_end_bl = end_bl
+			WHERE accumulated_at IS NOT NULL`).Error
+		if err != nil {
+			log.WriteLog.Error("Failed to record accumulated battery blocks", zap.Error(err))
+			return err
+		}
+	}
+
 	// Create indexes for performance
 	indexes := []string{
 		"CREATE INDEX IF NOT EXISTS idx_anomaly_results_device_id ON anomaly_results (device_id)",
 		"CREATE INDEX IF NOT EXISTS idx_anomaly_results_label ON anomaly_results (label)",
 		"CREATE INDEX IF NOT EXISTS idx_battery_health_device_id ON battery_health (device_id)",
 		"CREATE INDEX IF NOT EXISTS idx_battery_health_is_anomaly ON battery_health (is_anomaly)",
+		"CREATE INDEX IF NOT EXISTS idx_anomaly_results_device_ts ON anomaly_results (device_id, txn_ts)",
+		"CREATE INDEX IF NOT EXISTS idx_anomaly_results_keyset ON anomaly_results (txn_ts, device_id, txn_id)",
+		"CREATE UNIQUE INDEX IF NOT EXISTS idx_battery_risk_tiers_bound ON battery_risk_tiers ((COALESCE(max_score, 'Infinity'::float8)))",
 	}
 	for _, idx := range indexes {
 		if err := db.DB.Exec(idx).Error; err != nil {
@@ -38,6 +97,21 @@ func BootstrapSchema(db *database.DBStore) error {
 		}
 	}
 
+	// Blocks ingested are upserted on (device_id, block). Duplicates left by older
+	// loads would fail the unique index, so report them and start without it.
+	var duplicates int64
+	err = db.DB.Raw("SELECT COUNT(*) FROM (SELECT 1 FROM battery_health GROUP BY device_id, block HAVING COUNT(*) > 1) d").Scan(&duplicates).Error
+	if err != nil {
+		log.WriteLog.Error("Failed to check battery_health for duplicate blocks", zap.Error(err))
+		return err
+	}
+	if duplicates > 0 {
+		log.WriteLog.Error("battery_health has duplicate (device_id, block) rows; skipping idx_battery_health_device_block, battery ingest answers 503 until they are removed",
+			zap.Int64("duplicate_blocks", duplicates))
+	} else if err := db.DB.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_battery_health_device_block ON battery_health (device_id, block)").Error; err != nil {
+		log.WriteLog.Error("Failed to create index idx_battery_health_device_block", zap.Error(err))
+	}
+
 	log.WriteLog.Info("✅ Database schema initialized with indexes")
 	return nil
 }
\ No newline at end of file
@@ -1,12 +1,58 @@
 package postgres
 
 import (
+	"database/sql"
+
 	"anomaly-go/database"
 	"anomaly-go/log"
+	"anomaly-go/model/postgres"
+	"anomaly-go/util/constants"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm/clause"
 )
 
+// defaultLabels seeds the label taxonomy with the values the upstream model already emits.
+var defaultLabels = []postgres.Label{
+	{Name: constants.LabelFraud, DisplayName: "Fraud", Severity: 3, Color: "#E83B2D"},
+	{Name: constants.LabelAnomalyDetected, DisplayName: "Anomaly Detected", Severity: 2, Color: "#F6A121"},
+	{Name: constants.LabelReviewRequired, DisplayName: "Review Required", Severity: 1, Color: "#F6C344"},
+	{Name: constants.LabelNotFraud, DisplayName: "Normal", Severity: 0, Color: "#2DA74E"},
+}
+
+// defaultRules seeds the rule engine with the rules requested by risk ops.
+var defaultRules = []postgres.FraudRule{
+	{Name: "device_velocity_5m", Definition: `{"type": "velocity", "window": "5m", "min_count": 21}`, Enabled: true},
+	{Name: "amount_10x_30d_median", Definition: `{"type": "amount_spike", "window": "30d", "multiplier": 10, "min_history": 10}`, Enabled: true},
+	{Name: "repeated_amount_1h", Definition: `{"type": "repeated_amount", "window": "1h", "min_count": 5}`, Enabled: true},
+}
+
+// defaultRiskTiers split devices by battery score; critical and warning together
+// keep the former "bl_score <= 100" at-risk cutoff.
+var defaultRiskTiers = []postgres.BatteryRiskTier{
+	{Name: "critical", DisplayName: "Critical", MaxScore: sql.NullFloat64{Float64: 50, Valid: true}, AtRisk: true, Color: "#E83B2D"},
+	{Name: "warning", DisplayName: "Warning", MaxScore: sql.NullFloat64{Float64: 100, Valid: true}, AtRisk: true, Color: "#F6A121"},
+	{Name: "healthy", DisplayName: "Healthy", AtRisk: false, Color: "#2DA74E"},
+}
+
 // InsertInitialData adds initial data (e.g., default threshold) using GORM.
 func InsertInitialData(db *database.DBStore) error {
+	// Existing labels are left untouched so admin edits survive restarts.
+	if err := db.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&defaultLabels).Error; err != nil {
+		log.WriteLog.Error("Failed to insert default labels", zap.Error(err))
+		return err
+	}
+
+	if err := db.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&defaultRules).Error; err != nil {
+		log.WriteLog.Error("Failed to insert default fraud rules", zap.Error(err))
+		return err
+	}
+
+	if err := db.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&defaultRiskTiers).Error; err != nil {
+		log.WriteLog.Error("Failed to insert default battery risk tiers", zap.Error(err))
+		return err
+	}
+
 	log.WriteLog.Info("✅ Database setup checks complete (no default threshold inserted)")
 	return nil
 }
@@ -59,6 +59,18 @@ func InitializeApp(cfg *readenv.AppConfig) (*App, error) {
 	// 4. Initialize service layer
 	log.WriteLog.Info("Initializing services...")
 	service := anomaly.NewService(db.DB, cfg)
+	if err := service.InitModelRegistry(); err != nil {
+		log.WriteLog.Error("Failed to initialize model registry", zap.Error(err))
+		return nil, err
+	}
+	if err := service.InitShadowMode(); err != nil {
+		log.WriteLog.Error("Failed to load shadow candidate", zap.Error(err))
+		return nil, err
+	}
+	if err := service.ReloadRules(); err != nil {
+		log.WriteLog.Error("Failed to load fraud rules", zap.Error(err))
+		return nil, err
+	}
 	log.WriteLog.Info("Services initialized.")
 
 	// Return the fully initialized App struct
@@ -49,6 +49,10 @@ func main() {
 	// UPDATED: Pass the controller and the secret key to the router.
 	r := router.SetupRouter(apiController, string(cfg.JWTSecret))
 
+	// --- Start background jobs ---
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	app.Service.StartBackgroundJobs(jobsCtx)
+
 	serverAddr := fmt.Sprintf(":%d", cfg.RestConfig.GinData.PublicServerPort)
 
 	// --- Create and configure HTTP server ---
@@ -70,6 +74,7 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 	log.WriteLog.Info("Shutting down server...")
+	stopJobs()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -0,0 +1,30 @@
+// File: middleware/auth/admin_middleware.go
+
+package auth
+
+import (
+	"net/http"
+
+	"anomaly-go/log"
+	"anomaly-go/util/constants"
+	"anomaly-go/util/httputils/response"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// AdminMiddleware only lets the admin user through. It must run after JWTMiddleware,
+// which stores the username from the token claims in the context.
+func AdminMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		username := c.GetString("username")
+		if username != constants.AdminUsername {
+			log.WriteLog.Warn("Admin route denied", zap.String("username", username), zap.String("path", c.FullPath()))
+			response.HandleError(c, response.NewAppError(http.StatusForbidden, "Admin privileges required", nil))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
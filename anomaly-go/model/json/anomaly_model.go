@@ -9,13 +9,17 @@ type CommonWebRespJSON struct {
 
 // Existing JSON models from your original code
 type Transaction struct {
-	DeviceID          int64   `json:"device_id"`
-	TransactionID     string  `json:"transaction_id"`
-	TransactionTime   string  `json:"transaction_time"`
-	TransactionAmount float64 `json:"transaction_amount"`
-	ConfidenceScore   float64 `json:"confidence_score"`
-	AnomalyCheck      *string `json:"anomaly_check"`
-	Review            *string `json:"review"`
+	DeviceID          int64     `json:"device_id"`
+	TransactionID     string    `json:"transaction_id"`
+	TransactionTime   string    `json:"transaction_time"`
+	TransactionAmount float64   `json:"transaction_amount"`
+	ConfidenceScore   float64   `json:"confidence_score"`
+	AnomalyCheck      *string   `json:"anomaly_check"`
+	Review            *string   `json:"review"`
+	ModelVersion      *string   `json:"model_version"`
+	DuplicateOf       *string   `json:"duplicate_of"`
+	RuleHits          []RuleHit `json:"rule_hits"`
+	Reasons           []Reason  `json:"reasons"`
 }
 
 type FetchDataResponse struct {
@@ -24,28 +28,68 @@ type FetchDataResponse struct {
 	TotalAnomalyDetected  int           `json:"total_anomaly_detected"`
 	TotalFraud            int           `json:"total_fraud"`
 	TotalNullAnomalyCheck int           `json:"total_null_anomaly_check"`
+	// LabelMetrics holds the count of every label present in the filtered data, keyed by
+	// lower-cased label ("null" for unlabelled rows). The Total* fields above are kept for
+	// older clients.
+	LabelMetrics map[string]LabelMetric `json:"label_metrics"`
 }
 
+// DeviceHealthQuery holds the raw /getDeviceHealthData query parameters. Empty
+// values and the value "all" disable a filter.
+type DeviceHealthQuery struct {
+	DeviceID       string
+	ChargingStatus string
+	IsAnomaly      string
+	Search         string
+	From           string
+	To             string
+	MinBlock       string
+	MaxBlock       string
+	MinLevel       string
+	MaxLevel       string
+	Page           string
+	PageSize       string
+}
+
+// DeviceHealthResponse is one page of battery_health blocks. Total counts every
+// matching block; PageSize is 0 when the response is not paginated.
+type DeviceHealthResponse struct {
+	BatteryHealth []DeviceHealth `json:"battery_health"`
+	Total         int64          `json:"total"`
+	Page          int            `json:"page"`
+	PageSize      int            `json:"page_size"`
+}
+
+// DeviceHealth is one battery_health block. AnomalyReason holds the reason codes of
+// a flagged block and Reasons their explanations.
 type DeviceHealth struct {
-	Block     int    `json:"block"`
-	DeviceID  int    `json:"device_id"`
-	Charging  string `json:"charging"`
-	StartBL   float64 `json:"start_bl"`
-	EndBL     float64 `json:"end_bl"`
-	StartTime string `json:"start_time"`
-	EndTime   string `json:"end_time"`
-	IsAnomaly string `json:"is_anomaly"`
+	Block         int             `json:"block"`
+	DeviceID      int             `json:"device_id"`
+	Charging      string          `json:"charging"`
+	StartBL       float64         `json:"start_bl"`
+	EndBL         float64         `json:"end_bl"`
+	StartTime     string          `json:"start_time"`
+	EndTime       string          `json:"end_time"`
+	IsAnomaly     string          `json:"is_anomaly"`
+	AnomalyReason string          `json:"anomaly_reason"`
+	Reasons       []BatteryReason `json:"reasons"`
 }
 
 type AtRiskKPI struct {
-	TotalDevices  int     `json:"total_devices"`
-	AtRisk        int     `json:"at_risk"`
-	AtRiskPercent float64 `json:"at_risk_percent"`
+	TotalDevices  int             `json:"total_devices"`
+	AtRisk        int             `json:"at_risk"`
+	AtRiskPercent float64         `json:"at_risk_percent"`
+	Tiers         []RiskTierCount `json:"tiers"`
 }
 
+// AtRiskDevice is a scored device. ScoreComputedAt and ScoreAgeHours are null for
+// scores not written by the battery scoring job.
 type AtRiskDevice struct {
-	DeviceID int     `json:"device_id"`
-	DeviceBS float64 `json:"device_bs"`
+	DeviceID        int      `json:"device_id"`
+	DeviceBS        float64  `json:"device_bs"`
+	Tier            string   `json:"tier"`
+	ScoreComputedAt *string  `json:"score_computed_at"`
+	ScoreAgeHours   *float64 `json:"score_age_hours"`
 }
 
 type AtRiskResponse struct {
@@ -65,4 +109,4 @@ type UpdateReviewRequest struct {
 
 type UpdateConfidenceRequest struct {
 	Threshold int `json:"threshold" binding:"required"`
-}
\ No newline at end of file
+}
@@ -0,0 +1,216 @@
+package json
+
+// BatteryForecast is the projected date a device crosses the at-risk battery score,
+// with a 95% interval. Dates are null when no crossing is forecast.
+type BatteryForecast struct {
+	DeviceID             int64    `json:"device_id"`
+	Status               string   `json:"status"`
+	CurrentScore         *float64 `json:"current_score"`
+	ScoreComputedAt      *string  `json:"score_computed_at"`
+	ScoreAgeHours        *float64 `json:"score_age_hours"`
+	DischargeBlocks      int      `json:"discharge_blocks"`
+	CurrentDischargeRate float64  `json:"current_discharge_rate"`
+	RateSlopePerDay      float64  `json:"rate_slope_per_day"`
+	EstimatedDate        *string  `json:"estimated_date"`
+	EarliestDate         *string  `json:"earliest_date"`
+	LatestDate           *string  `json:"latest_date"`
+}
+
+type BatteryForecastResponse struct {
+	AtRiskScore float64           `json:"at_risk_score"`
+	Forecasts   []BatteryForecast `json:"forecasts"`
+}
+
+// BatteryReason explains why a battery block was flagged.
+type BatteryReason struct {
+	Code      string  `json:"code"`
+	Message   string  `json:"message"`
+	Value     float64 `json:"value"`
+	Threshold float64 `json:"threshold"`
+}
+
+// ReevaluateBatteryRequest queues battery blocks for a new anomaly check: those of
+// one device, or every device without DeviceID, started within the optional range.
+type ReevaluateBatteryRequest struct {
+	DeviceID int64  `json:"device_id" binding:"min=0"`
+	From     string `json:"from"`
+	To       string `json:"to"`
+}
+
+// IngestBatteryBlock is one charge or discharge block pushed by a device. CS is 0
+// unknown, 1 charging or 2 discharging.
+type IngestBatteryBlock struct {
+	Block     int     `json:"block"`
+	CS        int     `json:"cs"`
+	StartTime string  `json:"start_time"`
+	EndTime   string  `json:"end_time"`
+	StartBL   float64 `json:"start_bl"`
+	EndBL     float64 `json:"end_bl"`
+}
+
+type IngestBatteryBlocksRequest struct {
+	DeviceID int64                `json:"device_id" binding:"required,min=1"`
+	Blocks   []IngestBatteryBlock `json:"blocks" binding:"required,min=1,max=1000"`
+}
+
+// IngestBatteryResult reports the outcome for one ingested block. Status is one of
+// "created", "updated" or "invalid".
+type IngestBatteryResult struct {
+	Block  int    `json:"block"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+type IngestBatteryBlocksResponse struct {
+	DeviceID int64                 `json:"device_id"`
+	Created  int                   `json:"created"`
+	Updated  int                   `json:"updated"`
+	Invalid  int                   `json:"invalid"`
+	Results  []IngestBatteryResult `json:"results"`
+}
+
+// BatteryPoint summarizes the blocks of a device started within one bucket. State is
+// "charging", "discharging" or "unknown", whichever took most of the bucket.
+type BatteryPoint struct {
+	Time               string  `json:"time"`
+	Blocks             int64   `json:"blocks"`
+	MinLevel           float64 `json:"min_level"`
+	MaxLevel           float64 `json:"max_level"`
+	AvgLevel           float64 `json:"avg_level"`
+	State              string  `json:"state"`
+	ChargingMinutes    float64 `json:"charging_minutes"`
+	DischargingMinutes float64 `json:"discharging_minutes"`
+	Anomalies          int64   `json:"anomalies"`
+}
+
+// BatteryTimeSeriesResponse is the downsampled battery level of a device.
+// AutoResolution is true when the service chose the resolution.
+type BatteryTimeSeriesResponse struct {
+	DeviceID       int64          `json:"device_id"`
+	From           string         `json:"from"`
+	To             string         `json:"to"`
+	Resolution     string         `json:"resolution"`
+	AutoResolution bool           `json:"auto_resolution"`
+	Points         []BatteryPoint `json:"points"`
+}
+
+// BatteryWear is the charge cycle count and charging habits of a device over all
+// its battery_health blocks. Percentages are of observed time for LowBatteryPercent
+// and of charges for OvernightChargePercent and TopUpPercent.
+type BatteryWear struct {
+	DeviceID               int64   `json:"device_id"`
+	Cycles                 float64 `json:"cycles"`
+	ChargedPercent         float64 `json:"charged_percent"`
+	DischargedPercent      float64 `json:"discharged_percent"`
+	AvgDepthOfDischarge    float64 `json:"avg_depth_of_discharge"`
+	LowBatteryHours        float64 `json:"low_battery_hours"`
+	LowBatteryPercent      float64 `json:"low_battery_percent"`
+	Charges                int64   `json:"charges"`
+	OvernightChargePercent float64 `json:"overnight_charge_percent"`
+	TopUpPercent           float64 `json:"top_up_percent"`
+	Blocks                 int64   `json:"blocks"`
+	ObservedHours          float64 `json:"observed_hours"`
+	FirstTime              *string `json:"first_time"`
+	LastTime               *string `json:"last_time"`
+	UpdatedAt              string  `json:"updated_at"`
+}
+
+type BatteryWearResponse struct {
+	Devices []BatteryWear `json:"devices"`
+}
+
+// Distribution summarizes one metric over the Devices it applies to.
+type Distribution struct {
+	Devices int     `json:"devices"`
+	Mean    float64 `json:"mean"`
+	Min     float64 `json:"min"`
+	P10     float64 `json:"p10"`
+	P25     float64 `json:"p25"`
+	P50     float64 `json:"p50"`
+	P75     float64 `json:"p75"`
+	P90     float64 `json:"p90"`
+	Max     float64 `json:"max"`
+}
+
+// FleetBatteryWearResponse holds the fleet distribution of each wear metric.
+type FleetBatteryWearResponse struct {
+	Devices                int          `json:"devices"`
+	Cycles                 Distribution `json:"cycles"`
+	AvgDepthOfDischarge    Distribution `json:"avg_depth_of_discharge"`
+	LowBatteryPercent      Distribution `json:"low_battery_percent"`
+	OvernightChargePercent Distribution `json:"overnight_charge_percent"`
+	TopUpPercent           Distribution `json:"top_up_percent"`
+}
+
+// BatteryKPISnapshot is the fleet battery state at one point in time. Score is the
+// distribution of battery scores over the scored devices.
+type BatteryKPISnapshot struct {
+	TakenAt       string          `json:"taken_at"`
+	TotalDevices  int             `json:"total_devices"`
+	ScoredDevices int             `json:"scored_devices"`
+	AtRisk        int             `json:"at_risk"`
+	AtRiskPercent float64         `json:"at_risk_percent"`
+	Score         Distribution    `json:"score"`
+	Tiers         []RiskTierCount `json:"tiers"`
+}
+
+type BatteryKPITrendResponse struct {
+	From      string               `json:"from"`
+	To        string               `json:"to"`
+	Snapshots []BatteryKPISnapshot `json:"snapshots"`
+}
+
+// BatteryDataIssue is one data quality problem in the blocks of a device. Block is
+// -1 for a silence after the last block and Previous -1 for a silence before the
+// first one. Value is minutes for a gap or overlap and
+// percentage points for a level discontinuity.
+type BatteryDataIssue struct {
+	Type     string  `json:"type"`
+	Block    int     `json:"block"`
+	Previous int     `json:"previous_block"`
+	At       string  `json:"at"`
+	Value    float64 `json:"value"`
+}
+
+// BatteryDataQuality is the data quality of the blocks of a device. Completeness is
+// the percentage of the time filter range, or of the time since the first block
+// without one, covered by a block. Issues are only listed for a single device.
+type BatteryDataQuality struct {
+	DeviceID        int64              `json:"device_id"`
+	Completeness    float64            `json:"completeness"`
+	Blocks          int                `json:"blocks"`
+	SpanHours       float64            `json:"span_hours"`
+	CoveredHours    float64            `json:"covered_hours"`
+	Gaps            int                `json:"gaps"`
+	GapHours        float64            `json:"gap_hours"`
+	LongestGapHours float64            `json:"longest_gap_hours"`
+	Overlaps        int                `json:"overlaps"`
+	Duplicates      int                `json:"duplicates"`
+	OutOfOrder      int                `json:"out_of_order"`
+	Discontinuities int                `json:"level_discontinuities"`
+	LastBlockEnd    string             `json:"last_block_end"`
+	Issues          []BatteryDataIssue `json:"issues,omitempty"`
+}
+
+type BatteryDataQualityResponse struct {
+	Devices []BatteryDataQuality `json:"devices"`
+}
+
+// FleetDataQualityResponse summarizes battery data quality over the fleet. The
+// Devices* counts are devices with at least one issue of the kind, and Worst lists
+// the least complete devices.
+type FleetDataQualityResponse struct {
+	Devices                    int                  `json:"devices"`
+	Completeness               Distribution         `json:"completeness"`
+	DevicesWithGaps            int                  `json:"devices_with_gaps"`
+	DevicesWithOverlaps        int                  `json:"devices_with_overlaps"`
+	DevicesWithDuplicates      int                  `json:"devices_with_duplicates"`
+	DevicesOutOfOrder          int                  `json:"devices_out_of_order"`
+	DevicesWithDiscontinuities int                  `json:"devices_with_level_discontinuities"`
+	Gaps                       int                  `json:"gaps"`
+	Overlaps                   int                  `json:"overlaps"`
+	Duplicates                 int                  `json:"duplicates"`
+	OutOfOrder                 int                  `json:"out_of_order"`
+	Discontinuities            int                  `json:"level_discontinuities"`
+	Worst                      []BatteryDataQuality `json:"worst"`
+}
@@ -0,0 +1,23 @@
+package json
+
+// DriftPoint is one drift measurement of a segment.
+type DriftPoint struct {
+	ComputedAt     string  `json:"computed_at"`
+	Segment        string  `json:"segment"`
+	DeviceID       *int64  `json:"device_id"`
+	Metric         string  `json:"metric"`
+	PSI            float64 `json:"psi"`
+	KL             float64 `json:"kl_divergence"`
+	ReferenceCount int     `json:"reference_count"`
+	CurrentCount   int     `json:"current_count"`
+	ReferenceFrom  string  `json:"reference_from"`
+	CurrentFrom    string  `json:"current_from"`
+	CurrentTo      string  `json:"current_to"`
+	Alert          bool    `json:"alert"`
+}
+
+type DriftSeriesResponse struct {
+	PSIAlert float64      `json:"psi_alert"`
+	KLAlert  float64      `json:"kl_alert"`
+	Series   []DriftPoint `json:"series"`
+}
@@ -0,0 +1,44 @@
+package json
+
+// ConfusionMatrix holds the reviewer-confirmed outcomes of the model's labels.
+type ConfusionMatrix struct {
+	TruePositives  int `json:"true_positives"`
+	FalsePositives int `json:"false_positives"`
+	TrueNegatives  int `json:"true_negatives"`
+	FalseNegatives int `json:"false_negatives"`
+}
+
+// QualityMetrics are the model quality figures derived from a confusion matrix.
+type QualityMetrics struct {
+	Reviewed          int             `json:"reviewed"`
+	Precision         float64         `json:"precision"`
+	Recall            float64         `json:"recall"`
+	FalsePositiveRate float64         `json:"false_positive_rate"`
+	ConfusionMatrix   ConfusionMatrix `json:"confusion_matrix"`
+}
+
+type WindowQuality struct {
+	WindowStart string `json:"window_start"`
+	QualityMetrics
+}
+
+type DeviceQuality struct {
+	DeviceID int64 `json:"device_id"`
+	QualityMetrics
+}
+
+// ThresholdPoint is one point of the precision-recall curve.
+type ThresholdPoint struct {
+	Threshold int     `json:"threshold"`
+	Precision float64 `json:"precision"`
+	Recall    float64 `json:"recall"`
+	Flagged   int     `json:"flagged"`
+}
+
+type ModelEvaluationResponse struct {
+	Window  string           `json:"window"`
+	Overall QualityMetrics   `json:"overall"`
+	Windows []WindowQuality  `json:"windows"`
+	Devices []DeviceQuality  `json:"devices"`
+	Curve   []ThresholdPoint `json:"precision_recall_curve"`
+}
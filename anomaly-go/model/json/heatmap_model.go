@@ -0,0 +1,13 @@
+package json
+
+// AnomalyHeatmapResponse holds 7x24 matrices indexed by weekday (Monday first, as
+// listed in Days) and hour of day.
+type AnomalyHeatmapResponse struct {
+	TimeZone       string      `json:"timezone"`
+	Days           []string    `json:"days"`
+	Transactions   [][]int     `json:"transactions"`
+	Anomalies      [][]int     `json:"anomalies"`
+	AnomalyRates   [][]float64 `json:"anomaly_rates"`
+	Total          int         `json:"total_transactions"`
+	TotalAnomalies int         `json:"total_anomalies"`
+}
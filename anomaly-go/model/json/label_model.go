@@ -0,0 +1,17 @@
+package json
+
+// Label describes one entry of the label taxonomy.
+type Label struct {
+	Name        string `json:"name"         binding:"required"`
+	DisplayName string `json:"display_name" binding:"required"`
+	Severity    int    `json:"severity"     binding:"min=0,max=3"`
+	Color       string `json:"color"        binding:"required,hexcolor"`
+}
+
+// LabelMetric is the transaction count for one label, decorated with its taxonomy entry.
+type LabelMetric struct {
+	DisplayName string `json:"display_name"`
+	Severity    int    `json:"severity"`
+	Color       string `json:"color"`
+	Count       int    `json:"count"`
+}
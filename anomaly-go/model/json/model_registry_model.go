@@ -0,0 +1,30 @@
+package json
+
+import "encoding/json"
+
+// ModelVersion is an entry of the model registry.
+type ModelVersion struct {
+	ID          uint            `json:"id"`
+	Name        string          `json:"name"       binding:"required"`
+	Version     string          `json:"version"    binding:"required"`
+	Key         string          `json:"key"`
+	Parameters  json.RawMessage `json:"parameters"`
+	Active      bool            `json:"active"`
+	Runnable    bool            `json:"runnable"`
+	ActivatedAt *string         `json:"activated_at"`
+	CreatedAt   string          `json:"created_at,omitempty"`
+}
+
+type ActivateModelRequest struct {
+	ID uint `json:"id" binding:"required"`
+}
+
+// ModelVersionComparison summarises the results produced by one model version.
+type ModelVersionComparison struct {
+	ModelVersion  *string        `json:"model_version"`
+	Transactions  int            `json:"transactions"`
+	Flagged       int            `json:"flagged"`
+	FlagRate      float64        `json:"flag_rate"`
+	AvgConfidence float64        `json:"avg_confidence"`
+	Quality       QualityMetrics `json:"quality"`
+}
@@ -0,0 +1,31 @@
+package json
+
+// PeerBands places a device on each peer grouping dimension.
+type PeerBands struct {
+	Volume      string `json:"volume"`
+	TicketSize  string `json:"ticket_size"`
+	ActiveHours string `json:"active_hours"`
+}
+
+// PeerMetric is one device metric against its peer group.
+type PeerMetric struct {
+	Value      float64 `json:"value"`
+	Percentile float64 `json:"percentile"`
+	PeerMedian float64 `json:"peer_median"`
+	RobustZ    float64 `json:"robust_z"`
+	Outlier    bool    `json:"outlier"`
+}
+
+// PeerPlacement is a device against its peer group.
+type PeerPlacement struct {
+	DeviceID  int64                 `json:"device_id"`
+	Bands     PeerBands             `json:"bands"`
+	PeerGroup string                `json:"peer_group"`
+	Peers     int                   `json:"peers"`
+	Metrics   map[string]PeerMetric `json:"metrics"`
+	Outlier   bool                  `json:"outlier"`
+}
+
+type PeerBenchmarkResponse struct {
+	Devices []PeerPlacement `json:"devices"`
+}
@@ -0,0 +1,42 @@
+package json
+
+// StartRescoreRequest starts a new rescore job, or resumes the job given by JobID.
+type StartRescoreRequest struct {
+	JobID     uint   `json:"job_id"`
+	Target    string `json:"target"     binding:"omitempty,oneof=live shadow"`
+	From      string `json:"from"`
+	To        string `json:"to"`
+	ChunkSize int    `json:"chunk_size" binding:"omitempty,min=1,max=5000"`
+}
+
+type PauseRescoreRequest struct {
+	ID uint `json:"id" binding:"required"`
+}
+
+// RescoreCheckpoint is the last transaction processed by a rescore job.
+type RescoreCheckpoint struct {
+	TransactionTime string `json:"transaction_time"`
+	DeviceID        int64  `json:"device_id"`
+	TransactionID   string `json:"transaction_id"`
+}
+
+// RescoreJob reports the progress of a rescore job.
+type RescoreJob struct {
+	ID              uint               `json:"id"`
+	Status          string             `json:"status"`
+	Target          string             `json:"target"`
+	ModelVersion    string             `json:"model_version"`
+	Threshold       float64            `json:"threshold"`
+	RangeFrom       *string            `json:"range_from"`
+	RangeTo         *string            `json:"range_to"`
+	ChunkSize       int                `json:"chunk_size"`
+	Processed       int64              `json:"processed"`
+	Rescored        int64              `json:"rescored"`
+	SkippedReviewed int64              `json:"skipped_reviewed"`
+	Remaining       int64              `json:"remaining"`
+	Checkpoint      *RescoreCheckpoint `json:"checkpoint"`
+	Error           string             `json:"error,omitempty"`
+	CreatedAt       string             `json:"created_at"`
+	UpdatedAt       string             `json:"updated_at"`
+	FinishedAt      *string            `json:"finished_at"`
+}
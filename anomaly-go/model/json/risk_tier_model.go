@@ -0,0 +1,23 @@
+package json
+
+// RiskTier describes one battery risk tier. A missing max_score makes it the
+// catch-all tier above every bounded one.
+type RiskTier struct {
+	Name        string   `json:"name"         binding:"required"`
+	DisplayName string   `json:"display_name" binding:"required"`
+	MaxScore    *float64 `json:"max_score"`
+	AtRisk      bool     `json:"at_risk"`
+	Color       string   `json:"color"        binding:"required,hexcolor"`
+}
+
+// RiskTierCount is the number of devices in one risk tier, as a count and a
+// percentage of all devices in battery_health.
+type RiskTierCount struct {
+	Name        string   `json:"name"`
+	DisplayName string   `json:"display_name"`
+	MaxScore    *float64 `json:"max_score"`
+	AtRisk      bool     `json:"at_risk"`
+	Color       string   `json:"color"`
+	Count       int      `json:"count"`
+	Percent     float64  `json:"percent"`
+}
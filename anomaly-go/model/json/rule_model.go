@@ -0,0 +1,22 @@
+package json
+
+// FraudRule is a rule of the fraud rule engine. Definition is a JSON or YAML document.
+type FraudRule struct {
+	ID         uint   `json:"id"`
+	Name       string `json:"name"       binding:"required"`
+	Definition string `json:"definition" binding:"required"`
+	Enabled    *bool  `json:"enabled"`
+	CreatedAt  string `json:"created_at,omitempty"`
+	UpdatedAt  string `json:"updated_at,omitempty"`
+}
+
+// RuleHit is a rule matched by a transaction.
+type RuleHit struct {
+	RuleID   uint    `json:"rule_id"`
+	RuleName string  `json:"rule_name"`
+	RuleType string  `json:"rule_type"`
+	Message  string  `json:"message"`
+	Feature  string  `json:"feature"`
+	Value    float64 `json:"value"`
+	Limit    float64 `json:"limit"`
+}
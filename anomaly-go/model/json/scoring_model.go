@@ -0,0 +1,56 @@
+package json
+
+// IngestTransaction is one transaction pushed to the service for scoring.
+type IngestTransaction struct {
+	DeviceID          int64   `json:"device_id"`
+	TransactionID     string  `json:"transaction_id"`
+	TransactionTime   string  `json:"transaction_time"`
+	TransactionAmount float64 `json:"transaction_amount"`
+}
+
+type IngestTransactionsRequest struct {
+	Transactions []IngestTransaction `json:"transactions" binding:"required,min=1,max=1000"`
+}
+
+// Reason explains why a transaction was scored the way it was.
+type Reason struct {
+	Code     string  `json:"code"`
+	Message  string  `json:"message"`
+	Feature  string  `json:"feature"`
+	Value    float64 `json:"value"`
+	Baseline float64 `json:"baseline"`
+}
+
+// IngestResult reports the outcome for one ingested transaction. Status is one of
+// "scored", "duplicate" or "invalid".
+type IngestResult struct {
+	DeviceID        int64     `json:"device_id"`
+	TransactionID   string    `json:"transaction_id"`
+	Status          string    `json:"status"`
+	Error           string    `json:"error,omitempty"`
+	ConfidenceScore float64   `json:"confidence_score"`
+	AnomalyCheck    string    `json:"anomaly_check,omitempty"`
+	ModelVersion    string    `json:"model_version,omitempty"`
+	Reasons         []Reason  `json:"reasons,omitempty"`
+	RuleHits        []RuleHit `json:"rule_hits,omitempty"`
+}
+
+type IngestTransactionsResponse struct {
+	Scored     int            `json:"scored"`
+	Duplicates int            `json:"duplicates"`
+	Invalid    int            `json:"invalid"`
+	Results    []IngestResult `json:"results"`
+}
+
+// TopReason is the number of transactions flagged for one reason code.
+type TopReason struct {
+	Code         string  `json:"code"`
+	Transactions int64   `json:"transactions"`
+	Occurrences  int64   `json:"occurrences"`
+	Percent      float64 `json:"percent"`
+}
+
+type TopReasonsResponse struct {
+	TotalTransactions int64       `json:"total_transactions"`
+	Reasons           []TopReason `json:"reasons"`
+}
@@ -0,0 +1,22 @@
+package json
+
+// SeasonalitySlot is one hour of the week of a device: the expected transaction
+// count from its seasonal profile next to the actual count of the last seven days.
+type SeasonalitySlot struct {
+	DayOfWeek    string  `json:"day_of_week"`
+	Hour         int     `json:"hour"`
+	Expected     float64 `json:"expected"`
+	StdDev       float64 `json:"std_dev"`
+	Observations int64   `json:"observations"`
+	Actual       int     `json:"actual"`
+}
+
+type SeasonalityProfileResponse struct {
+	DeviceID     int64             `json:"device_id"`
+	LastHour     string            `json:"last_hour"`
+	ActualFrom   string            `json:"actual_from"`
+	ActualTo     string            `json:"actual_to"`
+	ExpectedWeek float64           `json:"expected_week"`
+	ActualWeek   int               `json:"actual_week"`
+	Slots        []SeasonalitySlot `json:"slots"`
+}
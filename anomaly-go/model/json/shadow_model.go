@@ -0,0 +1,36 @@
+package json
+
+// StartShadowRequest names the candidate to run in shadow mode. At least one of
+// ModelID and Threshold is required; the other keeps the live value.
+type StartShadowRequest struct {
+	ModelID   *uint `json:"model_id"`
+	Threshold *int  `json:"threshold" binding:"omitempty,min=0,max=100"`
+}
+
+// ShadowCandidate is the model and threshold run in shadow mode.
+type ShadowCandidate struct {
+	ID           uint     `json:"id"`
+	ModelVersion *string  `json:"model_version"`
+	Threshold    *float64 `json:"threshold"`
+	CreatedAt    string   `json:"created_at"`
+}
+
+// ShadowComparison compares live and shadow labels for one shadow model version and
+// threshold, with the reviewer-confirmed outcomes of each side.
+type ShadowComparison struct {
+	ShadowModelVersion *string        `json:"shadow_model_version"`
+	ShadowThreshold    *float64       `json:"shadow_threshold"`
+	Transactions       int            `json:"transactions"`
+	AgreementRate      float64        `json:"agreement_rate"`
+	LiveFlagged        int            `json:"live_flagged"`
+	ShadowFlagged      int            `json:"shadow_flagged"`
+	ExtraFlags         int            `json:"extra_flags"`
+	MissedFlags        int            `json:"missed_flags"`
+	Live               QualityMetrics `json:"live"`
+	Shadow             QualityMetrics `json:"shadow"`
+}
+
+type ShadowComparisonResponse struct {
+	Candidate   *ShadowCandidate   `json:"candidate"`
+	Comparisons []ShadowComparison `json:"comparisons"`
+}
@@ -0,0 +1,26 @@
+package json
+
+// SilentDevice is a device that stopped reporting the transactions its seasonal
+// profile expects, with the battery state used to explain it.
+type SilentDevice struct {
+	ID                uint     `json:"id"`
+	DeviceID          int64    `json:"device_id"`
+	Cause             string   `json:"cause"`
+	Expected          float64  `json:"expected"`
+	Actual            int      `json:"actual"`
+	WindowFrom        string   `json:"window_from"`
+	WindowTo          string   `json:"window_to"`
+	LastTransactionAt *string  `json:"last_transaction_at"`
+	BatteryLevel      *float64 `json:"battery_level"`
+	BatteryCharging   *bool    `json:"battery_charging"`
+	BatteryReportedAt *string  `json:"battery_reported_at"`
+	DetectedAt        string   `json:"detected_at"`
+	UpdatedAt         string   `json:"updated_at"`
+	ResolvedAt        *string  `json:"resolved_at"`
+}
+
+type SilentDevicesResponse struct {
+	Total   int            `json:"total"`
+	ByCause map[string]int `json:"by_cause"`
+	Devices []SilentDevice `json:"devices"`
+}
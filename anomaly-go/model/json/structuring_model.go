@@ -0,0 +1,20 @@
+package json
+
+// StructuringFinding is one structuring signal of a device in a time window.
+type StructuringFinding struct {
+	DeviceID     int64    `json:"device_id"`
+	WindowStart  string   `json:"window_start"`
+	Type         string   `json:"type"`
+	Score        float64  `json:"score"`
+	Message      string   `json:"message"`
+	Limit        *float64 `json:"limit,omitempty"`
+	Observed     float64  `json:"observed"`
+	Expected     float64  `json:"expected"`
+	Transactions []string `json:"transactions"`
+	Supporting   int      `json:"supporting_count"`
+}
+
+type StructuringResponse struct {
+	Window   string               `json:"window"`
+	Findings []StructuringFinding `json:"findings"`
+}
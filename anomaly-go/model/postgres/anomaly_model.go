@@ -9,13 +9,24 @@ import (
 
 // Transaction maps to the 'anomaly_results' table.
 type Transaction struct {
-    DeviceID          int64          `gorm:"column:device_id;primaryKey"`
-    TransactionID     string         `gorm:"column:txn_id;primaryKey"`
-    TransactionTime   time.Time      `gorm:"column:txn_ts"`
-    TransactionAmount float64        `gorm:"column:txn_amt"`
-    ConfidenceScore   float64        `gorm:"column:confidence"`
-    AnomalyCheck      sql.NullString `gorm:"column:label"`
-    Review            sql.NullString `gorm:"column:review"`
+    DeviceID           int64           `gorm:"column:device_id;primaryKey"`
+    TransactionID      string          `gorm:"column:txn_id;primaryKey"`
+    TransactionTime    time.Time       `gorm:"column:txn_ts"`
+    TransactionAmount  float64         `gorm:"column:txn_amt"`
+    ConfidenceScore    float64         `gorm:"column:confidence"`
+    AnomalyCheck       sql.NullString  `gorm:"column:label"`
+    Review             sql.NullString  `gorm:"column:review"`
+    // ModelVersion records the model that produced the score, as "name@version".
+    ModelVersion       sql.NullString  `gorm:"column:model_version;index"`
+    // Shadow* hold the scores of a candidate model or threshold, from shadow mode or
+    // a rescore job run in shadow mode. They are never shown to reviewers.
+    ShadowConfidence   sql.NullFloat64 `gorm:"column:shadow_confidence"`
+    ShadowLabel        sql.NullString  `gorm:"column:shadow_label"`
+    ShadowModelVersion sql.NullString  `gorm:"column:shadow_model_version"`
+    ShadowThreshold    sql.NullFloat64 `gorm:"column:shadow_threshold"`
+    // DuplicateOf is the txn_id of the earlier transaction of the same device this
+    // one duplicates, set by the duplicate detector.
+    DuplicateOf        sql.NullString  `gorm:"column:duplicate_of;index"`
 }
 
 
@@ -27,12 +38,25 @@ func (Transaction) TableName() string {
 type DeviceHealth struct {
     Block     int       `gorm:"column:block;primaryKey"`
     DeviceID  int       `gorm:"column:device_id;primaryKey"`
-    CS        int       `gorm:"column:CS"`
-    StartBL   float64   `gorm:"column:start_BL"`
-    EndBL     float64   `gorm:"column:end_BL"`
+    CS        int       `gorm:"column:cs"`
+    StartBL   float64   `gorm:"column:start_bl"`
+    EndBL     float64   `gorm:"column:end_bl"`
     StartTime time.Time `gorm:"column:start_time"`
     EndTime   time.Time `gorm:"column:end_time"`
     IsAnomaly int       `gorm:"column:is_anomaly"`
+    // AnomalyReason lists the reason codes of the block, comma separated; EvaluatedAt
+    // is when the battery anomaly detector last checked it and AccumulatedAt when it
+    // was folded into the wear accumulators (NULL queues it for either).
+    AnomalyReason string       `gorm:"column:anomaly_reason;not null;default:''"`
+    EvaluatedAt   sql.NullTime `gorm:"column:evaluated_at;index"`
+    AccumulatedAt sql.NullTime `gorm:"column:accumulated_at;index"`
+    // The accumulated columns keep the version of the block folded into the wear
+    // accumulators, to be taken back out when ingest replaces it.
+    AccumulatedCS        sql.NullInt64   `gorm:"column:accumulated_cs"`
+    AccumulatedStartTime sql.NullTime    `gorm:"column:accumulated_start_time"`
+    AccumulatedEndTime   sql.NullTime    `gorm:"column:accumulated_end_time"`
+    AccumulatedStartBL   sql.NullFloat64 `gorm:"column:accumulated_start_bl"`
+    AccumulatedEndBL     sql.NullFloat64 `gorm:"column:accumulated_end_bl"`
 }
 
 func (DeviceHealth) TableName() string {
@@ -41,14 +65,17 @@ func (DeviceHealth) TableName() string {
 
 // AtRiskDevice is a projection for at-risk devices query.
 type AtRiskDevice struct {
-	DeviceID int     `gorm:"column:device_id"`
-	DeviceBS float64 `gorm:"column:device_bs"`
+	DeviceID   int          `gorm:"column:device_id"`
+	DeviceBS   float64      `gorm:"column:device_bs"`
+	ComputedAt sql.NullTime `gorm:"column:computed_at"`
 }
 
-// BlScore maps to the 'bl_score' table, used for joins.
+// BlScore maps to the 'bl_score' table, used for joins. ComputedAt is set by the
+// battery scoring job and is NULL for scores written by other processes.
 type BlScore struct {
-	DeviceID int     `gorm:"column:device_id;primaryKey"`
-	DeviceBS float64 `gorm:"column:device_bs"`
+	DeviceID   int          `gorm:"column:device_id;primaryKey"`
+	DeviceBS   float64      `gorm:"column:device_bs"`
+	ComputedAt sql.NullTime `gorm:"column:computed_at"`
 }
 
 func (BlScore) TableName() string {
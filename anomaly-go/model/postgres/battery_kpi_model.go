@@ -0,0 +1,47 @@
+package postgres
+
+import (
+	"database/sql"
+	"time"
+)
+
+// BatteryKPISnapshot maps to the 'battery_kpi_snapshots' table: the fleet battery
+// KPIs and battery score distribution at one point in time.
+type BatteryKPISnapshot struct {
+	ID            uint      `gorm:"primaryKey"`
+	TakenAt       time.Time `gorm:"column:taken_at;not null;index"`
+	TotalDevices  int       `gorm:"column:total_devices"`
+	ScoredDevices int       `gorm:"column:scored_devices"`
+	AtRisk        int       `gorm:"column:at_risk"`
+	AtRiskPercent float64   `gorm:"column:at_risk_percent"`
+	ScoreMean     float64   `gorm:"column:score_mean"`
+	ScoreMin      float64   `gorm:"column:score_min"`
+	ScoreP10      float64   `gorm:"column:score_p10"`
+	ScoreP25      float64   `gorm:"column:score_p25"`
+	ScoreP50      float64   `gorm:"column:score_p50"`
+	ScoreP75      float64   `gorm:"column:score_p75"`
+	ScoreP90      float64   `gorm:"column:score_p90"`
+	ScoreMax      float64   `gorm:"column:score_max"`
+}
+
+func (BatteryKPISnapshot) TableName() string {
+	return BatteryKPITable
+}
+
+// BatteryKPITier maps to the 'battery_kpi_snapshot_tiers' child table: the device
+// count of one risk tier in a snapshot, with the tier as configured at the time.
+type BatteryKPITier struct {
+	ID          uint            `gorm:"primaryKey"`
+	SnapshotID  uint            `gorm:"column:snapshot_id;not null;index"`
+	Name        string          `gorm:"column:name;not null"`
+	DisplayName string          `gorm:"column:display_name"`
+	MaxScore    sql.NullFloat64 `gorm:"column:max_score"`
+	AtRisk      bool            `gorm:"column:at_risk"`
+	Color       string          `gorm:"column:color"`
+	Devices     int             `gorm:"column:devices"`
+	Percent     float64         `gorm:"column:percent"`
+}
+
+func (BatteryKPITier) TableName() string {
+	return BatteryKPITiersTable
+}
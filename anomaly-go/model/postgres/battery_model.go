@@ -0,0 +1,96 @@
+package postgres
+
+import (
+	"database/sql"
+	"time"
+)
+
+// BatteryBlock is a projection of one battery_health block used by the battery
+// analytics.
+type BatteryBlock struct {
+	DeviceID  int64     `gorm:"column:device_id"`
+	Block     int       `gorm:"column:block"`
+	CS        int       `gorm:"column:cs"`
+	StartTime time.Time `gorm:"column:start_time"`
+	EndTime   time.Time `gorm:"column:end_time"`
+	StartBL   float64   `gorm:"column:start_bl"`
+	EndBL     float64   `gorm:"column:end_bl"`
+	IsAnomaly int       `gorm:"column:is_anomaly"`
+}
+
+// UnaccumulatedBatteryBlock is a battery_health block queued for the wear
+// accumulators, with the version of it folded in before it was replaced, if any.
+type UnaccumulatedBatteryBlock struct {
+	BatteryBlock
+	AccumulatedCS        sql.NullInt64   `gorm:"column:accumulated_cs"`
+	AccumulatedStartTime sql.NullTime    `gorm:"column:accumulated_start_time"`
+	AccumulatedEndTime   sql.NullTime    `gorm:"column:accumulated_end_time"`
+	AccumulatedStartBL   sql.NullFloat64 `gorm:"column:accumulated_start_bl"`
+	AccumulatedEndBL     sql.NullFloat64 `gorm:"column:accumulated_end_bl"`
+}
+
+// BatteryAnomalyReason maps to the 'battery_anomaly_reasons' child table of
+// battery_health. Each row explains why a block was flagged.
+type BatteryAnomalyReason struct {
+	ID         uint      `gorm:"primaryKey"`
+	DeviceID   int64     `gorm:"column:device_id;not null;index:idx_battery_anomaly_reasons_block"`
+	Block      int       `gorm:"column:block;not null;index:idx_battery_anomaly_reasons_block"`
+	Code       string    `gorm:"column:code;not null;index"`
+	Message    string    `gorm:"column:message"`
+	Value      float64   `gorm:"column:value"`
+	Threshold  float64   `gorm:"column:threshold"`
+	DetectedAt time.Time `gorm:"column:detected_at;not null"`
+}
+
+func (BatteryAnomalyReason) TableName() string {
+	return BatteryReasonsTable
+}
+
+// BatteryEvaluation is the outcome of the battery anomaly detector for one block.
+type BatteryEvaluation struct {
+	DeviceID int64
+	Block    int
+	Reasons  []BatteryAnomalyReason
+}
+
+// BatteryBucket aggregates the battery_health blocks of one device started within
+// one time bucket.
+type BatteryBucket struct {
+	Bucket             time.Time `gorm:"column:bucket"`
+	Blocks             int64     `gorm:"column:blocks"`
+	MinLevel           float64   `gorm:"column:min_level"`
+	MaxLevel           float64   `gorm:"column:max_level"`
+	AvgLevel           float64   `gorm:"column:avg_level"`
+	ChargingSeconds    float64   `gorm:"column:charging_seconds"`
+	DischargingSeconds float64   `gorm:"column:discharging_seconds"`
+	Anomalies          int64     `gorm:"column:anomalies"`
+}
+
+// BatteryTimeRange is the span covered by the battery_health blocks of a device.
+type BatteryTimeRange struct {
+	Blocks int64        `gorm:"column:blocks"`
+	First  sql.NullTime `gorm:"column:first"`
+	Last   sql.NullTime `gorm:"column:last"`
+}
+
+// BatteryWear maps to the 'battery_wear' table: the charge cycle and charging habit
+// accumulators of each device.
+type BatteryWear struct {
+	DeviceID          int64        `gorm:"column:device_id;primaryKey"`
+	Blocks            int64        `gorm:"column:blocks"`
+	ChargedPercent    float64      `gorm:"column:charged_percent"`
+	DischargedPercent float64      `gorm:"column:discharged_percent"`
+	DischargeBlocks   int64        `gorm:"column:discharge_blocks"`
+	ChargeBlocks      int64        `gorm:"column:charge_blocks"`
+	OvernightCharges  int64        `gorm:"column:overnight_charges"`
+	TopUps            int64        `gorm:"column:top_ups"`
+	LowSeconds        float64      `gorm:"column:low_seconds"`
+	ObservedSeconds   float64      `gorm:"column:observed_seconds"`
+	FirstTime         sql.NullTime `gorm:"column:first_time"`
+	LastTime          sql.NullTime `gorm:"column:last_time"`
+	UpdatedAt         time.Time    `gorm:"column:updated_at"`
+}
+
+func (BatteryWear) TableName() string {
+	return BatteryWearTable
+}
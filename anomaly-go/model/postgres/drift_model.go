@@ -0,0 +1,47 @@
+package postgres
+
+import (
+	"database/sql"
+	"time"
+)
+
+// DriftResult maps to the 'drift_results' table. Each row compares one distribution
+// of one segment (the whole fleet, or a single device) between the current and the
+// reference window.
+type DriftResult struct {
+	ID             uint          `gorm:"primaryKey"`
+	ComputedAt     time.Time     `gorm:"column:computed_at;not null;index"`
+	Segment        string        `gorm:"column:segment;not null;index:idx_drift_results_segment"`
+	DeviceID       sql.NullInt64 `gorm:"column:device_id;index:idx_drift_results_segment"`
+	Metric         string        `gorm:"column:metric;not null"`
+	PSI            float64       `gorm:"column:psi"`
+	KL             float64       `gorm:"column:kl"`
+	ReferenceCount int           `gorm:"column:reference_count"`
+	CurrentCount   int           `gorm:"column:current_count"`
+	ReferenceFrom  time.Time     `gorm:"column:reference_from"`
+	CurrentFrom    time.Time     `gorm:"column:current_from"`
+	CurrentTo      time.Time     `gorm:"column:current_to"`
+	Alert          bool          `gorm:"column:alert;not null;default:false"`
+}
+
+func (DriftResult) TableName() string {
+	return DriftResultsTable
+}
+
+// DriftBucketCount is a projection counting transactions of one device per window
+// and distribution bucket.
+type DriftBucketCount struct {
+	DeviceID int64  `gorm:"column:device_id"`
+	Current  bool   `gorm:"column:is_current"`
+	Bucket   string `gorm:"column:bucket"`
+	Count    int    `gorm:"column:count"`
+}
+
+// DriftFilter selects drift results for the series endpoint. A zero DeviceID selects
+// the fleet segment; Time takes the /fetchData time filter values.
+type DriftFilter struct {
+	Time       string
+	DeviceID   int64
+	Metric     string
+	AlertsOnly bool
+}
@@ -0,0 +1,22 @@
+package postgres
+
+import "time"
+
+// ConfusionCounts is a projection for confusion matrix queries, grouped either by
+// time bucket or by device depending on the query.
+type ConfusionCounts struct {
+	Bucket         time.Time `gorm:"column:bucket"`
+	DeviceID       int64     `gorm:"column:device_id"`
+	TruePositives  int       `gorm:"column:tp"`
+	FalsePositives int       `gorm:"column:fp"`
+	TrueNegatives  int       `gorm:"column:tn"`
+	FalseNegatives int       `gorm:"column:fn"`
+}
+
+// ConfidenceBucket is a projection counting reviewed transactions per whole
+// confidence point, split by reviewer verdict.
+type ConfidenceBucket struct {
+	Confidence int `gorm:"column:confidence"`
+	Positives  int `gorm:"column:positives"`
+	Negatives  int `gorm:"column:negatives"`
+}
@@ -0,0 +1,38 @@
+package postgres
+
+import "database/sql"
+
+// TransactionFilter holds the /fetchData filters shared by every query over
+// anomaly_results. Empty fields and the value "all" disable a filter.
+type TransactionFilter struct {
+	Time         string
+	AnomalyCheck string
+	DeviceID     string
+	Search       string
+	ReasonCode   string
+	ModelVersion string
+	// Duplicate is "yes" for detected duplicates and "no" for the others.
+	Duplicate string
+}
+
+// DeviceHealthFilter holds the validated /getDeviceHealthData filters over
+// battery_health. Zero and invalid (NULL) values disable a filter.
+type DeviceHealthFilter struct {
+	DeviceID int64
+	// ChargingStatus and IsAnomaly match the cs and is_anomaly columns.
+	ChargingStatus sql.NullInt64
+	IsAnomaly      sql.NullInt64
+	Search         string
+	// From and To bound the block start time, To excluded.
+	From sql.NullTime
+	To   sql.NullTime
+	// MinBlock and MaxBlock bound the block number, both included.
+	MinBlock sql.NullInt64
+	MaxBlock sql.NullInt64
+	// MinLevel and MaxLevel keep the blocks whose level was within the range at
+	// some point, both included.
+	MinLevel sql.NullFloat64
+	MaxLevel sql.NullFloat64
+	Page     int
+	PageSize int
+}
@@ -0,0 +1,10 @@
+package postgres
+
+// HeatmapCell is a projection counting transactions and anomalies in one hour of
+// one weekday. DayOfWeek is the ISO day, 1 for Monday to 7 for Sunday.
+type HeatmapCell struct {
+	DayOfWeek    int `gorm:"column:day_of_week"`
+	Hour         int `gorm:"column:hour"`
+	Transactions int `gorm:"column:transactions"`
+	Anomalies    int `gorm:"column:anomalies"`
+}
@@ -0,0 +1,22 @@
+package postgres
+
+import "database/sql"
+
+// Label maps to the 'labels' reference table describing every value that may
+// appear in anomaly_results.label.
+type Label struct {
+	Name        string `gorm:"column:name;primaryKey"`
+	DisplayName string `gorm:"column:display_name;not null"`
+	Severity    int    `gorm:"column:severity;not null;default:0"`
+	Color       string `gorm:"column:color;not null"`
+}
+
+func (Label) TableName() string {
+	return LabelsTable
+}
+
+// LabelCount is a projection for the per-label transaction count query.
+type LabelCount struct {
+	Label sql.NullString `gorm:"column:label"`
+	Count int            `gorm:"column:count"`
+}
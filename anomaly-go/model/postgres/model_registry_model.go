@@ -0,0 +1,34 @@
+package postgres
+
+import (
+	"database/sql"
+	"time"
+)
+
+// ModelVersion maps to the 'model_versions' registry table. At most one row is
+// active: it is the model used to score ingested transactions.
+type ModelVersion struct {
+	ID          uint         `gorm:"primaryKey"`
+	Name        string       `gorm:"column:name;not null;uniqueIndex:idx_model_versions_name_version"`
+	Version     string       `gorm:"column:version;not null;uniqueIndex:idx_model_versions_name_version"`
+	Parameters  string       `gorm:"column:parameters;type:text"`
+	Active      bool         `gorm:"column:active;not null;default:false"`
+	ActivatedAt sql.NullTime `gorm:"column:activated_at"`
+	CreatedAt   time.Time    `gorm:"column:created_at"`
+}
+
+func (ModelVersion) TableName() string {
+	return ModelVersionsTable
+}
+
+// ModelVersionStats is a projection comparing the results of each model version.
+type ModelVersionStats struct {
+	ModelVersion   sql.NullString `gorm:"column:model_version"`
+	Transactions   int            `gorm:"column:transactions"`
+	Flagged        int            `gorm:"column:flagged"`
+	AvgConfidence  float64        `gorm:"column:avg_confidence"`
+	TruePositives  int            `gorm:"column:tp"`
+	FalsePositives int            `gorm:"column:fp"`
+	TrueNegatives  int            `gorm:"column:tn"`
+	FalseNegatives int            `gorm:"column:fn"`
+}
@@ -0,0 +1,12 @@
+package postgres
+
+// DeviceActivity is a projection of the transaction profile of one device, used to
+// build peer groups.
+type DeviceActivity struct {
+	DeviceID     int64   `gorm:"column:device_id"`
+	Transactions int     `gorm:"column:transactions"`
+	Anomalies    int     `gorm:"column:anomalies"`
+	AvgAmount    float64 `gorm:"column:avg_amount"`
+	MedianAmount float64 `gorm:"column:median_amount"`
+	PeakHour     int     `gorm:"column:peak_hour"`
+}
@@ -0,0 +1,56 @@
+package postgres
+
+import (
+	"database/sql"
+	"time"
+)
+
+// RescoreJob maps to the 'rescore_jobs' table. The Last* columns are the keyset
+// checkpoint (txn_ts, device_id, txn_id) of the last processed row, so a paused or
+// interrupted job resumes where it stopped.
+type RescoreJob struct {
+	ID              uint         `gorm:"primaryKey"`
+	Status          string       `gorm:"column:status;not null;index"`
+	Target          string       `gorm:"column:target;not null"`
+	ModelName       string       `gorm:"column:model_name;not null"`
+	ModelVersion    string       `gorm:"column:model_version;not null"`
+	Threshold       float64      `gorm:"column:threshold"`
+	RangeFrom       sql.NullTime `gorm:"column:range_from"`
+	RangeTo         sql.NullTime `gorm:"column:range_to"`
+	ChunkSize       int          `gorm:"column:chunk_size;not null"`
+	LastTxnTime     sql.NullTime `gorm:"column:last_txn_ts"`
+	LastDeviceID    int64        `gorm:"column:last_device_id"`
+	LastTxnID       string       `gorm:"column:last_txn_id"`
+	Processed       int64        `gorm:"column:processed"`
+	Rescored        int64        `gorm:"column:rescored"`
+	SkippedReviewed int64        `gorm:"column:skipped_reviewed"`
+	Error           string       `gorm:"column:error"`
+	CreatedAt       time.Time    `gorm:"column:created_at"`
+	UpdatedAt       time.Time    `gorm:"column:updated_at"`
+	FinishedAt      sql.NullTime `gorm:"column:finished_at"`
+}
+
+func (RescoreJob) TableName() string {
+	return RescoreJobsTable
+}
+
+// RescoreBaseline maps to the 'rescore_baselines' table: the device baselines a
+// rescore job rebuilds while replaying history, kept apart from the live baselines.
+type RescoreBaseline struct {
+	JobID          uint `gorm:"column:job_id;primaryKey"`
+	DeviceBaseline `gorm:"embedded"`
+}
+
+func (RescoreBaseline) TableName() string {
+	return RescoreBaselinesTable
+}
+
+// RescoreScore is a new score computed by a rescore job for one transaction.
+type RescoreScore struct {
+	DeviceID      int64
+	TransactionID string
+	Confidence    float64
+	Label         string
+	ModelVersion  string
+	Reasons       []AnomalyReason
+}
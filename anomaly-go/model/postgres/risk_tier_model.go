@@ -0,0 +1,18 @@
+package postgres
+
+import "database/sql"
+
+// BatteryRiskTier maps to the 'battery_risk_tiers' table. Devices fall in the tier
+// with the lowest max_score at or above their bl_score; a NULL max_score marks the
+// catch-all tier above all others.
+type BatteryRiskTier struct {
+	Name        string          `gorm:"column:name;primaryKey"`
+	DisplayName string          `gorm:"column:display_name;not null"`
+	MaxScore    sql.NullFloat64 `gorm:"column:max_score"`
+	AtRisk      bool            `gorm:"column:at_risk;not null;default:false"`
+	Color       string          `gorm:"column:color;not null"`
+}
+
+func (BatteryRiskTier) TableName() string {
+	return BatteryRiskTiersTable
+}
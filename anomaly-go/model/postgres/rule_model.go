@@ -0,0 +1,37 @@
+package postgres
+
+import "time"
+
+// FraudRule maps to the 'fraud_rules' table. Definition holds the rule document as
+// JSON or YAML.
+type FraudRule struct {
+	ID         uint      `gorm:"primaryKey"`
+	Name       string    `gorm:"column:name;uniqueIndex;not null"`
+	Definition string    `gorm:"column:definition;type:text;not null"`
+	Enabled    bool      `gorm:"column:enabled;not null;default:true"`
+	CreatedAt  time.Time `gorm:"column:created_at"`
+	UpdatedAt  time.Time `gorm:"column:updated_at"`
+}
+
+func (FraudRule) TableName() string {
+	return FraudRulesTable
+}
+
+// RuleHit maps to the 'rule_hits' table recording every rule matched by a transaction.
+type RuleHit struct {
+	ID            uint      `gorm:"primaryKey"`
+	DeviceID      int64     `gorm:"column:device_id;not null"`
+	TransactionID string    `gorm:"column:txn_id;not null;index"`
+	RuleID        uint      `gorm:"column:rule_id;not null;index"`
+	RuleName      string    `gorm:"column:rule_name;not null"`
+	RuleType      string    `gorm:"column:rule_type;not null"`
+	Message       string    `gorm:"column:message"`
+	Feature       string    `gorm:"column:feature"`
+	Value         float64   `gorm:"column:value"`
+	Limit         float64   `gorm:"column:limit_value"`
+	CreatedAt     time.Time `gorm:"column:created_at"`
+}
+
+func (RuleHit) TableName() string {
+	return RuleHitsTable
+}
@@ -0,0 +1,71 @@
+package postgres
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// DeviceBaseline maps to the 'device_baselines' table holding the streaming scorer
+// state of each device, so baselines survive restarts.
+type DeviceBaseline struct {
+	DeviceID    int64           `gorm:"column:device_id;primaryKey"`
+	TxnCount    int64           `gorm:"column:txn_count"`
+	AmountMean  float64         `gorm:"column:amount_mean"`
+	AmountVar   float64         `gorm:"column:amount_var"`
+	GapMean     float64         `gorm:"column:gap_mean"`
+	GapVar      float64         `gorm:"column:gap_var"`
+	LastTxnTime time.Time       `gorm:"column:last_txn_ts"`
+	HourProfile pq.Float64Array `gorm:"column:hour_profile;type:double precision[]"`
+	UpdatedAt   time.Time       `gorm:"column:updated_at"`
+}
+
+func (DeviceBaseline) TableName() string {
+	return DeviceBaselinesTable
+}
+
+// DeviceSeasonality maps to the 'device_seasonality' table: the expected hourly
+// transaction count of a device per hour of the week (168 slots, Monday 00:00 first).
+type DeviceSeasonality struct {
+	DeviceID     int64           `gorm:"column:device_id;primaryKey"`
+	Expected     pq.Float64Array `gorm:"column:expected;type:double precision[]"`
+	Variance     pq.Float64Array `gorm:"column:variance;type:double precision[]"`
+	Observations pq.Int64Array   `gorm:"column:observations;type:bigint[]"`
+	LastHour     time.Time       `gorm:"column:last_hour"`
+	UpdatedAt    time.Time       `gorm:"column:updated_at"`
+}
+
+func (DeviceSeasonality) TableName() string {
+	return DeviceSeasonalityTable
+}
+
+// HourlyCount is a projection counting the transactions of a device in one hour.
+type HourlyCount struct {
+	DeviceID int64     `gorm:"column:device_id"`
+	Hour     time.Time `gorm:"column:hour"`
+	Count    int       `gorm:"column:count"`
+}
+
+// AnomalyReason maps to the 'anomaly_reasons' child table of anomaly_results. Each row
+// explains one contribution to a transaction's score.
+type AnomalyReason struct {
+	ID            uint    `gorm:"primaryKey"`
+	DeviceID      int64   `gorm:"column:device_id;not null"`
+	TransactionID string  `gorm:"column:txn_id;not null;index"`
+	Code          string  `gorm:"column:code;not null;index"`
+	Message       string  `gorm:"column:message"`
+	Feature       string  `gorm:"column:feature"`
+	Value         float64 `gorm:"column:value"`
+	Baseline      float64 `gorm:"column:baseline"`
+}
+
+func (AnomalyReason) TableName() string {
+	return AnomalyReasonsTable
+}
+
+// ReasonCount is a projection for the top reasons query.
+type ReasonCount struct {
+	Code         string `gorm:"column:code"`
+	Transactions int64  `gorm:"column:transactions"`
+	Occurrences  int64  `gorm:"column:occurrences"`
+}
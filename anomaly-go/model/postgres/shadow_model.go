@@ -0,0 +1,43 @@
+package postgres
+
+import (
+	"database/sql"
+	"time"
+)
+
+// ShadowCandidate maps to the 'shadow_candidates' table. The active row names the
+// model and/or threshold scored next to the live ones on ingest; an unset column
+// means the candidate keeps the live value.
+type ShadowCandidate struct {
+	ID             uint            `gorm:"primaryKey"`
+	ModelVersionID sql.NullInt64   `gorm:"column:model_version_id"`
+	Threshold      sql.NullFloat64 `gorm:"column:threshold"`
+	Active         bool            `gorm:"column:active;not null;default:false"`
+	CreatedAt      time.Time       `gorm:"column:created_at"`
+	StoppedAt      sql.NullTime    `gorm:"column:stopped_at"`
+}
+
+func (ShadowCandidate) TableName() string {
+	return ShadowCandidatesTable
+}
+
+// ShadowComparisonStats is a projection comparing live and shadow labels for one
+// shadow model version and threshold.
+type ShadowComparisonStats struct {
+	ShadowModelVersion   sql.NullString  `gorm:"column:shadow_model_version"`
+	ShadowThreshold      sql.NullFloat64 `gorm:"column:shadow_threshold"`
+	Transactions         int             `gorm:"column:transactions"`
+	LiveFlagged          int             `gorm:"column:live_flagged"`
+	ShadowFlagged        int             `gorm:"column:shadow_flagged"`
+	Agreements           int             `gorm:"column:agreements"`
+	ExtraFlags           int             `gorm:"column:extra_flags"`
+	MissedFlags          int             `gorm:"column:missed_flags"`
+	LiveTruePositives    int             `gorm:"column:live_tp"`
+	LiveFalsePositives   int             `gorm:"column:live_fp"`
+	LiveTrueNegatives    int             `gorm:"column:live_tn"`
+	LiveFalseNegatives   int             `gorm:"column:live_fn"`
+	ShadowTruePositives  int             `gorm:"column:shadow_tp"`
+	ShadowFalsePositives int             `gorm:"column:shadow_fp"`
+	ShadowTrueNegatives  int             `gorm:"column:shadow_tn"`
+	ShadowFalseNegatives int             `gorm:"column:shadow_fn"`
+}
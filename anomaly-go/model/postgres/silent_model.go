@@ -0,0 +1,53 @@
+package postgres
+
+import (
+	"database/sql"
+	"time"
+)
+
+// SilentDevice maps to the 'silent_devices' table. Each row is one period during
+// which a device reported far fewer transactions than its seasonal profile expects.
+// It stays open, with its latest figures, until the device is active again.
+type SilentDevice struct {
+	ID                uint            `gorm:"primaryKey"`
+	DeviceID          int64           `gorm:"column:device_id;not null;index"`
+	Cause             string          `gorm:"column:cause;not null"`
+	Expected          float64         `gorm:"column:expected"`
+	Actual            int             `gorm:"column:actual"`
+	WindowFrom        time.Time       `gorm:"column:window_from"`
+	WindowTo          time.Time       `gorm:"column:window_to"`
+	LastTxnAt         sql.NullTime    `gorm:"column:last_txn_at"`
+	BatteryLevel      sql.NullFloat64 `gorm:"column:battery_level"`
+	BatteryCharging   sql.NullBool    `gorm:"column:battery_charging"`
+	BatteryReportedAt sql.NullTime    `gorm:"column:battery_reported_at"`
+	DetectedAt        time.Time       `gorm:"column:detected_at;not null;index"`
+	UpdatedAt         time.Time       `gorm:"column:updated_at"`
+	ResolvedAt        sql.NullTime    `gorm:"column:resolved_at;index"`
+}
+
+func (SilentDevice) TableName() string {
+	return SilentDevicesTable
+}
+
+// BatteryReading is a projection of the latest battery_health block of a device.
+type BatteryReading struct {
+	DeviceID       int64           `gorm:"column:device_id"`
+	ChargingStatus sql.NullInt64   `gorm:"column:cs"`
+	Level          sql.NullFloat64 `gorm:"column:end_bl"`
+	ReportedAt     sql.NullTime    `gorm:"column:end_time"`
+}
+
+// DeviceLastTransaction is a projection of the time of the last transaction of a device.
+type DeviceLastTransaction struct {
+	DeviceID  int64     `gorm:"column:device_id"`
+	LastTxnAt time.Time `gorm:"column:last_txn_at"`
+}
+
+// SilentDeviceFilter selects silent device findings. Status is open, resolved or
+// all; Time takes the /fetchData time filter values and applies to detected_at.
+type SilentDeviceFilter struct {
+	Status   string
+	DeviceID int64
+	Cause    string
+	Time     string
+}
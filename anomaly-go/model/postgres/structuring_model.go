@@ -0,0 +1,13 @@
+package postgres
+
+import "time"
+
+// StructuringTransaction is a projection of a transaction with the start of the time
+// window it falls in.
+type StructuringTransaction struct {
+	DeviceID          int64     `gorm:"column:device_id"`
+	TransactionID     string    `gorm:"column:txn_id"`
+	TransactionTime   time.Time `gorm:"column:txn_ts"`
+	TransactionAmount float64   `gorm:"column:txn_amt"`
+	WindowStart       time.Time `gorm:"column:window_start"`
+}
@@ -1,8 +1,25 @@
 package postgres
 
 const (
-	ThresholdsTable     = "thresholds"
-	AnomalyResultsTable = "anomaly_results"
-	BatteryHealthTable  = "battery_health"
-	BLScoreTable        = "bl_score"
-)
\ No newline at end of file
+	ThresholdsTable        = "thresholds"
+	AnomalyResultsTable    = "anomaly_results"
+	BatteryHealthTable     = "battery_health"
+	BLScoreTable           = "bl_score"
+	LabelsTable            = "labels"
+	DeviceBaselinesTable   = "device_baselines"
+	AnomalyReasonsTable    = "anomaly_reasons"
+	FraudRulesTable        = "fraud_rules"
+	RuleHitsTable          = "rule_hits"
+	ModelVersionsTable     = "model_versions"
+	RescoreJobsTable       = "rescore_jobs"
+	RescoreBaselinesTable  = "rescore_baselines"
+	ShadowCandidatesTable  = "shadow_candidates"
+	DriftResultsTable      = "drift_results"
+	DeviceSeasonalityTable = "device_seasonality"
+	SilentDevicesTable     = "silent_devices"
+	BatteryRiskTiersTable  = "battery_risk_tiers"
+	BatteryReasonsTable    = "battery_anomaly_reasons"
+	BatteryWearTable       = "battery_wear"
+	BatteryKPITable        = "battery_kpi_snapshots"
+	BatteryKPITiersTable   = "battery_kpi_snapshot_tiers"
+)
@@ -46,44 +46,16 @@ func (r *Repository) UpdateConfidenceThreshold(threshold int) (int64, error) {
 }
 
 // FetchTransactions retrieves transactions with filters.
-func (r *Repository) FetchTransactions(timeFilter, anomalyCheck, deviceID, searchTerm string) ([]model.Transaction, error) {
+func (r *Repository) FetchTransactions(filter model.TransactionFilter) ([]model.Transaction, error) {
 	var transactions []model.Transaction
 	tx := r.DB.Model(&model.Transaction{})
 
-	tx = applyTransactionFilters(tx, timeFilter, anomalyCheck, deviceID, searchTerm)
+	tx = applyTransactionFilters(tx, filter)
 
 	err := tx.Find(&transactions).Error
 	return transactions, err
 }
 
-// CountTransactionMetrics counts anomaly metrics based on filters.
-type metricsResult struct {
-	TotalReviewRequired   int
-	TotalAnomalyDetected  int
-	TotalFraud            int
-	TotalNullAnomalyCheck int
-}
-
-func (r *Repository) CountTransactionMetrics(timeFilter, anomalyCheck, deviceID, searchTerm string) (int, int, int, int, error) {
-	var result metricsResult
-	tx := r.DB.Model(&model.Transaction{})
-
-	tx = applyTransactionFilters(tx, timeFilter, anomalyCheck, deviceID, searchTerm)
-
-	err := tx.Select(`
-		COALESCE(SUM(CASE WHEN LOWER(label) = 'review required' THEN 1 ELSE 0 END), 0) as total_review_required,
-		COALESCE(SUM(CASE WHEN LOWER(label) = 'anomaly detected' THEN 1 ELSE 0 END), 0) as total_anomaly_detected,
-		COALESCE(SUM(CASE WHEN LOWER(label) = 'yes' THEN 1 ELSE 0 END), 0) as total_fraud,
-		COALESCE(SUM(CASE WHEN label IS NULL THEN 1 ELSE 0 END), 0) as total_null_anomaly_check`).
-		Scan(&result).Error
-
-	if err != nil {
-		return 0, 0, 0, 0, err
-	}
-
-	return result.TotalReviewRequired, result.TotalAnomalyDetected, result.TotalFraud, result.TotalNullAnomalyCheck, nil
-}
-
 // GetAllDeviceIds fetches unique device IDs from anomaly_results.
 func (r *Repository) GetAllDeviceIds() ([]int64, error) {
 	var ids []int64
@@ -104,51 +76,66 @@ func (r *Repository) UpdateReview(txnID, review string) (int64, error) {
 	return res.RowsAffected, res.Error
 }
 
-// GetDeviceHealthData fetches battery health data with filters.
-func (r *Repository) GetDeviceHealthData(deviceIDStr, chargingStatus, isAnomaly, searchTerm string) ([]model.DeviceHealth, error) {
+// GetDeviceHealthData fetches one page of battery health data matching the filter,
+// in block order, with the total number of matching blocks. A zero PageSize
+// fetches every matching block.
+func (r *Repository) GetDeviceHealthData(filter model.DeviceHealthFilter) ([]model.DeviceHealth, int64, error) {
+	var total int64
+	if err := deviceHealthQuery(r.DB, filter).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
 	var data []model.DeviceHealth
-	tx := r.DB.Model(&model.DeviceHealth{})
+	tx := deviceHealthQuery(r.DB, filter).Order("block ASC, device_id ASC")
+	if filter.PageSize > 0 {
+		tx = tx.Offset((filter.Page - 1) * filter.PageSize).Limit(filter.PageSize)
+	}
+	err := tx.Find(&data).Error
+	return data, total, err
+}
 
-	if deviceIDStr != "" && deviceIDStr != "all" {
-		tx = tx.Where("device_id = ?", deviceIDStr)
-	}
-	if chargingStatus != "" && strings.ToLower(chargingStatus) != "all" {
-		var cs int
-		switch strings.ToLower(chargingStatus) {
-		case "charging":
-			cs = 1
-		case "discharging":
-			cs = 2
-		case "unknown":
-			cs = 0
-		default:
-			return nil, fmt.Errorf("invalid charging_status")
-		}
-		tx = tx.Where("CS = ?", cs)
-	}
-	if isAnomaly != "" && strings.ToLower(isAnomaly) != "all" {
-		var anomalyInt int
-		switch strings.ToLower(isAnomaly) {
-		case "yes":
-			anomalyInt = 1
-		case "no":
-			anomalyInt = 0
-		default:
-			return nil, fmt.Errorf("invalid is_anomaly")
-		}
-		tx = tx.Where("is_anomaly = ?", anomalyInt)
+// deviceHealthQuery applies the /getDeviceHealthData filters to battery_health.
+func deviceHealthQuery(db *gorm.DB, filter model.DeviceHealthFilter) *gorm.DB {
+	tx := db.Model(&model.DeviceHealth{})
+
+	if filter.DeviceID != 0 {
+		tx = tx.Where("device_id = ?", filter.DeviceID)
 	}
-	if searchTerm != "" {
-		searchPattern := "%" + strings.ToLower(searchTerm) + "%"
+	if filter.ChargingStatus.Valid {
+		tx = tx.Where("cs = ?", filter.ChargingStatus.Int64)
+	}
+	if filter.IsAnomaly.Valid {
+		tx = tx.Where("is_anomaly = ?", filter.IsAnomaly.Int64)
+	}
+	if filter.Search != "" {
+		searchPattern := "%" + strings.ToLower(filter.Search) + "%"
 		tx = tx.Where("CAST(device_id AS TEXT) ILIKE ?", searchPattern)
 	}
-
-	err := tx.Order("block ASC").Find(&data).Error
-	return data, err
+	if filter.From.Valid {
+		tx = tx.Where("start_time >= ?", filter.From.Time)
+	}
+	if filter.To.Valid {
+		tx = tx.Where("start_time < ?", filter.To.Time)
+	}
+	if filter.MinBlock.Valid {
+		tx = tx.Where("block >= ?", filter.MinBlock.Int64)
+	}
+	if filter.MaxBlock.Valid {
+		tx = tx.Where("block <= ?", filter.MaxBlock.Int64)
+	}
+	if filter.MinLevel.Valid {
+		tx = tx.Where("GREATEST(start_bl, end_bl) >= ?", filter.MinLevel.Float64)
+	}
+	if filter.MaxLevel.Valid {
+		tx = tx.Where("LEAST(start_bl, end_bl) <= ?", filter.MaxLevel.Float64)
+	}
+	return tx
 }
 
-// GetAtRiskKPIs fetches at-risk KPIs and devices.
-func (r *Repository) GetAtRiskKPIs(deviceIDStr, searchTerm string) (int, []model.AtRiskDevice, error) {
+// GetHealthDeviceScores fetches the total number of devices in battery_health and the
+// battery score of the devices matching the filters. Risk tiers are assigned by the
+// caller.
+func (r *Repository) GetHealthDeviceScores(deviceIDStr, searchTerm string) (int, []model.AtRiskDevice, error) {
 	var totalDevices int64
 	// Total devices (unfiltered)
 	err := r.DB.Model(&model.DeviceHealth{}).Distinct("device_id").Count(&totalDevices).Error
@@ -156,12 +143,11 @@ func (r *Repository) GetAtRiskKPIs(deviceIDStr, searchTerm string) (int, []model
 		return 0, nil, err
 	}
 
-	// At-risk devices
+	// Scored devices
 	var devices []model.AtRiskDevice
 	tx := r.DB.Model(&model.DeviceHealth{}).
-		Select("DISTINCT battery_health.device_id, bl_score.device_bs").
-		Joins("JOIN bl_score ON battery_health.device_id = bl_score.device_id").
-		Where("bl_score.device_bs <= 100")
+		Select("DISTINCT battery_health.device_id, bl_score.device_bs, bl_score.computed_at").
+		Joins("JOIN bl_score ON battery_health.device_id = bl_score.device_id")
 
 	if deviceIDStr != "" && deviceIDStr != "all" {
 		tx = tx.Where("battery_health.device_id = ?", deviceIDStr)
@@ -182,29 +168,53 @@ func (r *Repository) GetAtRiskKPIs(deviceIDStr, searchTerm string) (int, []model
 // --- Helper Functions ---
 
 // applyTransactionFilters applies common query conditions for transactions.
-func applyTransactionFilters(db *gorm.DB, timeFilter, anomalyCheck, deviceID, searchTerm string) *gorm.DB {
-	if timeFilter != "" && timeFilter != "all" {
-		if timeThreshold, err := getTimeThreshold(timeFilter); err == nil {
+func applyTransactionFilters(db *gorm.DB, filter model.TransactionFilter) *gorm.DB {
+	if filter.Time != "" && filter.Time != "all" {
+		if timeThreshold, err := getTimeThreshold(filter.Time); err == nil {
 			db = db.Where("txn_ts >= ?", timeThreshold)
 		}
 	}
-	if anomalyCheck != "" && anomalyCheck != "all" {
-		if anomalyCheck == "null" {
+	if filter.AnomalyCheck != "" && filter.AnomalyCheck != "all" {
+		if filter.AnomalyCheck == "null" {
 			db = db.Where("label IS NULL")
 		} else {
-			db = db.Where("LOWER(label) = ?", strings.ToLower(anomalyCheck))
+			db = db.Where("LOWER(label) = ?", strings.ToLower(filter.AnomalyCheck))
 		}
 	}
-	if deviceID != "" && deviceID != "all" {
-		db = db.Where("device_id = ?", deviceID)
+	if filter.DeviceID != "" && filter.DeviceID != "all" {
+		db = db.Where("device_id = ?", filter.DeviceID)
 	}
-	if searchTerm != "" {
-		searchPattern := "%" + strings.ToLower(searchTerm) + "%"
+	if filter.Search != "" {
+		searchPattern := "%" + strings.ToLower(filter.Search) + "%"
 		db = db.Where("LOWER(txn_id) LIKE ? OR LOWER(CAST(device_id AS TEXT)) LIKE ?", searchPattern, searchPattern)
 	}
+	if filter.ReasonCode != "" && filter.ReasonCode != "all" {
+		db = db.Where("EXISTS (SELECT 1 FROM anomaly_reasons WHERE anomaly_reasons.device_id = anomaly_results.device_id AND anomaly_reasons.txn_id = anomaly_results.txn_id AND anomaly_reasons.code = ?)",
+			strings.ToUpper(filter.ReasonCode))
+	}
+	if filter.ModelVersion != "" && filter.ModelVersion != "all" {
+		if filter.ModelVersion == "null" {
+			db = db.Where("model_version IS NULL")
+		} else {
+			db = db.Where("model_version = ?", filter.ModelVersion)
+		}
+	}
+	switch strings.ToLower(filter.Duplicate) {
+	case "yes":
+		db = db.Where("duplicate_of IS NOT NULL")
+	case "no":
+		db = db.Where("duplicate_of IS NULL")
+	}
 	return db
 }
 
+// TimeThreshold returns the start of the range selected by a /fetchData time
+// filter, or false for "all" and unknown filters.
+func TimeThreshold(filter string) (time.Time, bool) {
+	t, err := getTimeThreshold(filter)
+	return t, err == nil
+}
+
 // getTimeThreshold calculates the time threshold based on the filter string.
 func getTimeThreshold(filter string) (time.Time, error) {
 	now := time.Now()
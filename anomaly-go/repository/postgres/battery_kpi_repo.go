@@ -0,0 +1,70 @@
+package postgres
+
+import (
+	"database/sql"
+	"time"
+
+	model "anomaly-go/model/postgres"
+
+	"gorm.io/gorm"
+)
+
+// SaveBatteryKPISnapshot stores a snapshot with its tier counts.
+func (r *Repository) SaveBatteryKPISnapshot(snapshot *model.BatteryKPISnapshot, tiers []model.BatteryKPITier) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(snapshot).Error; err != nil {
+			return err
+		}
+		if len(tiers) == 0 {
+			return nil
+		}
+		for i := range tiers {
+			tiers[i].SnapshotID = snapshot.ID
+		}
+		return tx.Create(&tiers).Error
+	})
+}
+
+// GetLatestBatteryKPISnapshotTime fetches when the last snapshot was taken. It returns
+// false when there is none.
+func (r *Repository) GetLatestBatteryKPISnapshotTime() (time.Time, bool, error) {
+	var snapshots []model.BatteryKPISnapshot
+	err := r.DB.Select("taken_at").Order("taken_at DESC").Limit(1).Find(&snapshots).Error
+	if err != nil || len(snapshots) == 0 {
+		return time.Time{}, false, err
+	}
+	return snapshots[0].TakenAt, true, nil
+}
+
+// GetBatteryKPISnapshots fetches the snapshots taken within the optional range,
+// oldest first, with their tier counts keyed by snapshot ID.
+func (r *Repository) GetBatteryKPISnapshots(from, to sql.NullTime) ([]model.BatteryKPISnapshot, map[uint][]model.BatteryKPITier, error) {
+	tx := r.DB.Model(&model.BatteryKPISnapshot{})
+	if from.Valid {
+		tx = tx.Where("taken_at >= ?", from.Time)
+	}
+	if to.Valid {
+		tx = tx.Where("taken_at < ?", to.Time)
+	}
+	var snapshots []model.BatteryKPISnapshot
+	if err := tx.Order("taken_at ASC").Find(&snapshots).Error; err != nil {
+		return nil, nil, err
+	}
+
+	tiers := make(map[uint][]model.BatteryKPITier)
+	if len(snapshots) == 0 {
+		return snapshots, tiers, nil
+	}
+	ids := make([]uint, 0, len(snapshots))
+	for _, s := range snapshots {
+		ids = append(ids, s.ID)
+	}
+	var rows []model.BatteryKPITier
+	if err := r.DB.Where("snapshot_id IN ?", ids).Order("snapshot_id ASC, id ASC").Find(&rows).Error; err != nil {
+		return nil, nil, err
+	}
+	for _, t := range rows {
+		tiers[t.SnapshotID] = append(tiers[t.SnapshotID], t)
+	}
+	return snapshots, tiers, nil
+}
@@ -0,0 +1,366 @@
+package postgres
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	model "anomaly-go/model/postgres"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GetBatteryBlocks fetches the complete battery_health blocks of one device, or of
+// every device when deviceID is 0, optionally only one charging status (1 charging,
+// 2 discharging) and only blocks started within the /fetchData time filter.
+func (r *Repository) GetBatteryBlocks(deviceID int64, chargingStatus int, timeFilter string) ([]model.BatteryBlock, error) {
+	tx := r.batteryBlocks()
+	if deviceID != 0 {
+		tx = tx.Where("device_id = ?", deviceID)
+	}
+	if chargingStatus != 0 {
+		tx = tx.Where("cs = ?", chargingStatus)
+	}
+	if timeFilter != "" && timeFilter != "all" {
+		if timeThreshold, err := getTimeThreshold(timeFilter); err == nil {
+			tx = tx.Where("start_time >= ?", timeThreshold)
+		}
+	}
+
+	var blocks []model.BatteryBlock
+	err := tx.Order("device_id ASC, start_time ASC, block ASC").Scan(&blocks).Error
+	return blocks, err
+}
+
+// GetBatteryBlocksSince fetches the complete battery_health blocks of every device
+// started at or after since.
+func (r *Repository) GetBatteryBlocksSince(since time.Time) ([]model.BatteryBlock, error) {
+	var blocks []model.BatteryBlock
+	err := r.batteryBlocks().
+		Where("start_time >= ?", since).
+		Order("device_id ASC, start_time ASC, block ASC").
+		Scan(&blocks).Error
+	return blocks, err
+}
+
+// SaveBatteryScores upserts device battery scores into bl_score.
+func (r *Repository) SaveBatteryScores(scores []model.BlScore) error {
+	if len(scores) == 0 {
+		return nil
+	}
+	return r.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "device_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"device_bs", "computed_at"}),
+	}).CreateInBatches(&scores, 500).Error
+}
+
+// GetUnevaluatedBatteryBlocks fetches up to limit blocks the battery anomaly detector
+// has not checked yet, per device in time order.
+func (r *Repository) GetUnevaluatedBatteryBlocks(limit int) ([]model.BatteryBlock, error) {
+	var blocks []model.BatteryBlock
+	err := r.batteryBlocks().
+		Where("evaluated_at IS NULL").
+		Order("device_id ASC, start_time ASC, block ASC").
+		Limit(limit).
+		Scan(&blocks).Error
+	return blocks, err
+}
+
+// GetPreviousBatteryBlock fetches the last block of a device started before the given
+// time. It returns false when there is none.
+func (r *Repository) GetPreviousBatteryBlock(deviceID int64, before time.Time) (model.BatteryBlock, bool, error) {
+	var blocks []model.BatteryBlock
+	err := r.batteryBlocks().
+		Where("device_id = ? AND start_time < ?", deviceID, before).
+		Order("start_time DESC, block DESC").
+		Limit(1).
+		Scan(&blocks).Error
+	if err != nil || len(blocks) == 0 {
+		return model.BatteryBlock{}, false, err
+	}
+	return blocks[0], true, nil
+}
+
+// GetBatteryBlocksAround fetches the stored blocks of a device numbered from first
+// to last, together with the closest block before first and after last, in block
+// order.
+func (r *Repository) GetBatteryBlocksAround(deviceID int64, first, last int) ([]model.BatteryBlock, error) {
+	var blocks []model.BatteryBlock
+	err := r.batteryBlocks().
+		Where("device_id = ?", deviceID).
+		Where("block >= COALESCE((SELECT MAX(block) FROM "+model.BatteryHealthTable+" WHERE device_id = ? AND block < ?), ?)", deviceID, first, first).
+		Where("block <= COALESCE((SELECT MIN(block) FROM "+model.BatteryHealthTable+" WHERE device_id = ? AND block > ?), ?)", deviceID, last, last).
+		Order("block ASC").
+		Scan(&blocks).Error
+	return blocks, err
+}
+
+// SaveBatteryEvaluations replaces the anomaly reasons of the evaluated blocks and
+// records their anomaly flag, reason codes and evaluation time, in one transaction.
+func (r *Repository) SaveBatteryEvaluations(evals []model.BatteryEvaluation, at time.Time) error {
+	if len(evals) == 0 {
+		return nil
+	}
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		keys := make([][]interface{}, 0, len(evals))
+		var reasons []model.BatteryAnomalyReason
+		for _, e := range evals {
+			keys = append(keys, []interface{}{e.DeviceID, e.Block})
+			reasons = append(reasons, e.Reasons...)
+		}
+		if err := tx.Where("(device_id, block) IN ?", keys).Delete(&model.BatteryAnomalyReason{}).Error; err != nil {
+			return err
+		}
+		if len(reasons) > 0 {
+			if err := tx.CreateInBatches(&reasons, 500).Error; err != nil {
+				return err
+			}
+		}
+
+		for _, e := range evals {
+			codes := make([]string, 0, len(e.Reasons))
+			for _, reason := range e.Reasons {
+				codes = append(codes, reason.Code)
+			}
+			isAnomaly := 0
+			if len(codes) > 0 {
+				isAnomaly = 1
+			}
+			err := tx.Table(model.BatteryHealthTable).
+				Where("device_id = ? AND block = ?", e.DeviceID, e.Block).
+				Updates(map[string]interface{}{
+					"is_anomaly":     isAnomaly,
+					"anomaly_reason": strings.Join(codes, ","),
+					"evaluated_at":   at,
+				}).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ResetBatteryEvaluations queues the blocks of one device, or of every device when
+// deviceID is 0, started within the optional range for a new anomaly check.
+func (r *Repository) ResetBatteryEvaluations(deviceID int64, from, to sql.NullTime) (int64, error) {
+	tx := r.DB.Table(model.BatteryHealthTable).Where("evaluated_at IS NOT NULL")
+	if deviceID != 0 {
+		tx = tx.Where("device_id = ?", deviceID)
+	}
+	if from.Valid {
+		tx = tx.Where("start_time >= ?", from.Time)
+	}
+	if to.Valid {
+		tx = tx.Where("start_time < ?", to.Time)
+	}
+	res := tx.Update("evaluated_at", nil)
+	return res.RowsAffected, res.Error
+}
+
+// GetBatteryAnomalyReasons fetches the anomaly reasons of the given blocks, each
+// given as a (device_id, block) pair.
+func (r *Repository) GetBatteryAnomalyReasons(keys [][]interface{}) ([]model.BatteryAnomalyReason, error) {
+	var reasons []model.BatteryAnomalyReason
+	if len(keys) == 0 {
+		return reasons, nil
+	}
+	err := r.DB.Where("(device_id, block) IN ?", keys).Order("id ASC").Find(&reasons).Error
+	return reasons, err
+}
+
+// batteryBlockColumns are the battery_health columns replaced when a block is
+// ingested again.
+var batteryBlockColumns = []string{"cs", "start_time", "end_time", "start_bl", "end_bl", "is_anomaly"}
+
+func (r *Repository) batteryBlocks() *gorm.DB {
+	return r.DB.Table(model.BatteryHealthTable).
+		Select("device_id, block, cs, start_time, end_time, start_bl, end_bl, is_anomaly").
+		Where("start_time IS NOT NULL AND end_time IS NOT NULL AND start_bl IS NOT NULL AND end_bl IS NOT NULL")
+}
+
+// HasBatteryBlockKey reports whether battery_health has a unique index on exactly
+// (device_id, block), which UpsertBatteryBlocks needs to resolve conflicts.
+func (r *Repository) HasBatteryBlockKey() (bool, error) {
+	var keyed bool
+	err := r.DB.Raw(`SELECT EXISTS (
+		SELECT 1 FROM pg_index i
+		WHERE i.indrelid = ?::regclass AND i.indisunique AND i.indisvalid
+			AND i.indpred IS NULL AND i.indexprs IS NULL AND i.indnatts = 2
+			AND (SELECT array_agg(a.attname::text ORDER BY a.attname) FROM pg_attribute a
+				WHERE a.attrelid = i.indrelid AND a.attnum = ANY (i.indkey)) = ARRAY['block', 'device_id'])`,
+		model.BatteryHealthTable).Scan(&keyed).Error
+	return keyed, err
+}
+
+// UpsertBatteryBlocks inserts or replaces the given blocks of one device, keyed on
+// (device_id, block). Replaced blocks lose their anomaly verdict and are queued for
+// the battery anomaly detector and the wear accumulators again. It returns the blocks that already existed.
+func (r *Repository) UpsertBatteryBlocks(deviceID int64, blocks []model.BatteryBlock) (map[int]bool, error) {
+	existing := make(map[int]bool)
+	if len(blocks) == 0 {
+		return existing, nil
+	}
+	numbers := make([]int, 0, len(blocks))
+	for _, b := range blocks {
+		numbers = append(numbers, b.Block)
+	}
+
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		var found []int
+		err := tx.Table(model.BatteryHealthTable).
+			Where("device_id = ? AND block IN ?", deviceID, numbers).
+			Pluck("block", &found).Error
+		if err != nil {
+			return err
+		}
+		for _, b := range found {
+			existing[b] = true
+		}
+
+		set := clause.AssignmentColumns(batteryBlockColumns)
+		set = append(set,
+			clause.Assignment{Column: clause.Column{Name: "anomaly_reason"}, Value: ""},
+			clause.Assignment{Column: clause.Column{Name: "evaluated_at"}, Value: nil},
+			clause.Assignment{Column: clause.Column{Name: "accumulated_at"}, Value: nil},
+		)
+		return tx.Table(model.BatteryHealthTable).Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "device_id"}, {Name: "block"}},
+			DoUpdates: set,
+		}).CreateInBatches(&blocks, 500).Error
+	})
+	return existing, err
+}
+
+// GetBatteryTimeRange fetches the number of blocks of a device started within the
+// optional range and the span they cover.
+func (r *Repository) GetBatteryTimeRange(deviceID int64, from, to sql.NullTime) (model.BatteryTimeRange, error) {
+	var span model.BatteryTimeRange
+	tx := r.DB.Table(model.BatteryHealthTable).
+		Select("COUNT(*) AS blocks, MIN(start_time) AS first, MAX(end_time) AS last").
+		Where("device_id = ?", deviceID)
+	err := batteryTimeWindow(tx, from, to).Scan(&span).Error
+	return span, err
+}
+
+// GetBatteryTimeSeries downsamples the blocks of a device started within the optional
+// range into buckets of the given width. Levels are read at both ends of each block;
+// the average is weighted by block duration.
+func (r *Repository) GetBatteryTimeSeries(deviceID int64, from, to sql.NullTime, bucket time.Duration) ([]model.BatteryBucket, error) {
+	// Buckets are aligned on a fixed origin so the same range always yields the same buckets.
+	bucketExpr := "TIMESTAMP '2000-01-03 00:00:00' + FLOOR(EXTRACT(EPOCH FROM start_time - TIMESTAMP '2000-01-03 00:00:00') / @width) * @width * INTERVAL '1 second'"
+	duration := "GREATEST(EXTRACT(EPOCH FROM end_time - start_time), 0)"
+
+	tx := r.DB.Table(model.BatteryHealthTable).
+		Select(`
+			`+bucketExpr+` AS bucket,
+			COUNT(*) AS blocks,
+			MIN(LEAST(start_bl, end_bl)) AS min_level,
+			MAX(GREATEST(start_bl, end_bl)) AS max_level,
+			COALESCE(SUM((start_bl + end_bl) / 2 * `+duration+`) / NULLIF(SUM(`+duration+`), 0), AVG((start_bl + end_bl) / 2)) AS avg_level,
+			COALESCE(SUM(`+duration+`) FILTER (WHERE cs = 1), 0) AS charging_seconds,
+			COALESCE(SUM(`+duration+`) FILTER (WHERE cs = 2), 0) AS discharging_seconds,
+			COUNT(*) FILTER (WHERE is_anomaly = 1) AS anomalies`,
+			sql.Named("width", int64(bucket/time.Second))).
+		Where("device_id = ?", deviceID)
+
+	var buckets []model.BatteryBucket
+	err := batteryTimeWindow(tx, from, to).
+		Group("bucket").
+		Order("bucket ASC").
+		Scan(&buckets).Error
+	return buckets, err
+}
+
+func batteryTimeWindow(tx *gorm.DB, from, to sql.NullTime) *gorm.DB {
+	if from.Valid {
+		tx = tx.Where("start_time >= ?", from.Time)
+	}
+	if to.Valid {
+		tx = tx.Where("start_time < ?", to.Time)
+	}
+	return tx
+}
+
+// GetBatteryWear fetches the wear accumulators of the given devices, or of every
+// device when deviceIDs is nil.
+func (r *Repository) GetBatteryWear(deviceIDs []int64) ([]model.BatteryWear, error) {
+	var wear []model.BatteryWear
+	tx := r.DB.Model(&model.BatteryWear{})
+	if deviceIDs != nil {
+		tx = tx.Where("device_id IN ?", deviceIDs)
+	}
+	err := tx.Order("device_id ASC").Find(&wear).Error
+	return wear, err
+}
+
+// GetUnaccumulatedBatteryBlocks fetches up to limit blocks not folded into the wear
+// accumulators yet, per device in block order, with the version folded in before
+// they were replaced.
+func (r *Repository) GetUnaccumulatedBatteryBlocks(limit int) ([]model.UnaccumulatedBatteryBlock, error) {
+	var blocks []model.UnaccumulatedBatteryBlock
+	err := r.batteryBlocks().
+		Select("device_id, block, cs, start_time, end_time, start_bl, end_bl, is_anomaly, " +
+			"accumulated_cs, accumulated_start_time, accumulated_end_time, accumulated_start_bl, accumulated_end_bl").
+		Where("accumulated_at IS NULL").
+		Order("device_id ASC, block ASC").
+		Limit(limit).
+		Scan(&blocks).Error
+	return blocks, err
+}
+
+// SaveBatteryWear upserts wear accumulators and records the blocks folded into them
+// as accumulated, keeping the version folded in, in one transaction. A block
+// replaced since it was read keeps the version actually folded in and stays queued.
+func (r *Repository) SaveBatteryWear(wear []model.BatteryWear, blocks []model.BatteryBlock, at time.Time) error {
+	if len(wear) == 0 {
+		return nil
+	}
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{UpdateAll: true}).CreateInBatches(&wear, 500).Error; err != nil {
+			return err
+		}
+		if len(blocks) == 0 {
+			return nil
+		}
+
+		keys := make([][]interface{}, 0, len(blocks))
+		folded := make(map[[2]int64]model.BatteryBlock, len(blocks))
+		for _, b := range blocks {
+			keys = append(keys, []interface{}{b.DeviceID, b.Block})
+			folded[[2]int64{b.DeviceID, int64(b.Block)}] = b
+		}
+		var marked []model.BatteryBlock
+		err := tx.Raw(`UPDATE battery_health SET accumulated_at = ?, accumulated_cs = cs,
+			accumulated_start_time = start_time, accumulated_end_time = end_time,
+			accumulated_start_bl = start_bl, accumulated_end_bl = end_bl
+			WHERE (device_id, block) IN ?
+			RETURNING device_id, block, cs, start_time, end_time, start_bl, end_bl`, at, keys).
+			Scan(&marked).Error
+		if err != nil {
+			return err
+		}
+
+		for _, m := range marked {
+			b := folded[[2]int64{m.DeviceID, int64(m.Block)}]
+			if m.CS == b.CS && m.StartTime.Equal(b.StartTime) && m.EndTime.Equal(b.EndTime) && m.StartBL == b.StartBL && m.EndBL == b.EndBL {
+				continue
+			}
+			err := tx.Table(model.BatteryHealthTable).
+				Where("device_id = ? AND block = ?", b.DeviceID, b.Block).
+				Updates(map[string]interface{}{
+					"accumulated_at":         nil,
+					"accumulated_cs":         b.CS,
+					"accumulated_start_time": b.StartTime,
+					"accumulated_end_time":   b.EndTime,
+					"accumulated_start_bl":   b.StartBL,
+					"accumulated_end_bl":     b.EndBL,
+				}).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
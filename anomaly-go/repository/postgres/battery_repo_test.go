@@ -0,0 +1,83 @@
+package postgres
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	model "anomaly-go/model/postgres"
+
+	"gorm.io/gorm/schema"
+)
+
+// columns returns the column names the given model maps to.
+func columns(t *testing.T, value interface{}) map[string]bool {
+	t.Helper()
+	s, err := schema.Parse(value, &sync.Map{}, schema.NamingStrategy{})
+	if err != nil {
+		t.Fatalf("schema.Parse(%T) error = %v", value, err)
+	}
+	names := make(map[string]bool, len(s.DBNames))
+	for _, name := range s.DBNames {
+		names[name] = true
+	}
+	return names
+}
+
+func TestBatteryColumnsMatchDeviceHealth(t *testing.T) {
+	health := columns(t, &model.DeviceHealth{})
+	tests := []struct {
+		name  string
+		value interface{}
+	}{
+		{"BatteryBlock", &model.BatteryBlock{}},
+		{"BatteryReading", &model.BatteryReading{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for name := range columns(t, tt.value) {
+				if !health[name] {
+					t.Errorf("%s column %q is not a DeviceHealth column", tt.name, name)
+				}
+			}
+		})
+	}
+	for _, name := range batteryBlockColumns {
+		if !health[name] {
+			t.Errorf("upsert column %q is not a DeviceHealth column", name)
+		}
+	}
+}
+
+// TestBatteryBlockRoundTrip ingests a block and reads it back through
+// GetDeviceHealthData. It needs a scratch database, see testDB.
+func TestBatteryBlockRoundTrip(t *testing.T) {
+	db := testDB(t, &model.DeviceHealth{})
+
+	const deviceID = -1
+	t.Cleanup(func() { db.Where("device_id = ?", deviceID).Delete(&model.DeviceHealth{}) })
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	block := model.BatteryBlock{DeviceID: deviceID, Block: 1, CS: 2, StartTime: start, EndTime: start.Add(time.Hour), StartBL: 80, EndBL: 70}
+
+	r := NewRepository(db)
+	if keyed, err := r.HasBatteryBlockKey(); err != nil || !keyed {
+		t.Fatalf("HasBatteryBlockKey() = %v, %v; want true", keyed, err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := r.UpsertBatteryBlocks(deviceID, []model.BatteryBlock{block}); err != nil {
+			t.Fatalf("UpsertBatteryBlocks() error = %v", err)
+		}
+		block.EndBL = 65
+	}
+
+	data, total, err := r.GetDeviceHealthData(model.DeviceHealthFilter{DeviceID: deviceID, Page: 1})
+	if err != nil {
+		t.Fatalf("GetDeviceHealthData() error = %v", err)
+	}
+	if total != 1 || len(data) != 1 {
+		t.Fatalf("GetDeviceHealthData() = %d rows of %d; want 1 of 1", len(data), total)
+	}
+	if got := data[0]; got.CS != 2 || got.StartBL != 80 || got.EndBL != 65 || !got.StartTime.Equal(start) {
+		t.Errorf("GetDeviceHealthData() = %+v; want cs 2, levels 80 to 65 from %v", got, start)
+	}
+}
@@ -0,0 +1,64 @@
+package postgres
+
+import (
+	"time"
+
+	model "anomaly-go/model/postgres"
+)
+
+// CountConfidenceBuckets counts transactions per device in the reference window
+// [refFrom, curFrom) and the current window [curFrom, curTo), per tenth of the
+// 0-100 confidence scale.
+func (r *Repository) CountConfidenceBuckets(refFrom, curFrom, curTo time.Time) ([]model.DriftBucketCount, error) {
+	return r.countDriftBuckets("LEAST(GREATEST(FLOOR(confidence / 10), 0), 9)::int::text", refFrom, curFrom, curTo)
+}
+
+// CountLabelBuckets counts transactions per device and label in the reference and
+// current windows.
+func (r *Repository) CountLabelBuckets(refFrom, curFrom, curTo time.Time) ([]model.DriftBucketCount, error) {
+	return r.countDriftBuckets("COALESCE(LOWER(label), 'null')", refFrom, curFrom, curTo)
+}
+
+func (r *Repository) countDriftBuckets(bucketExpr string, refFrom, curFrom, curTo time.Time) ([]model.DriftBucketCount, error) {
+	var counts []model.DriftBucketCount
+	err := r.DB.Model(&model.Transaction{}).
+		Select("device_id, txn_ts >= @curFrom AS is_current, "+bucketExpr+" AS bucket, COUNT(*) AS count",
+			map[string]interface{}{"curFrom": curFrom}).
+		Where("txn_ts >= ? AND txn_ts < ?", refFrom, curTo).
+		Group("device_id, is_current, bucket").
+		Scan(&counts).Error
+	return counts, err
+}
+
+// InsertDriftResults stores the results of one drift run.
+func (r *Repository) InsertDriftResults(results []model.DriftResult) error {
+	if len(results) == 0 {
+		return nil
+	}
+	return r.DB.Create(&results).Error
+}
+
+// GetDriftResults fetches the drift series matching the filter, oldest first.
+func (r *Repository) GetDriftResults(filter model.DriftFilter) ([]model.DriftResult, error) {
+	tx := r.DB.Model(&model.DriftResult{})
+	if filter.DeviceID == 0 {
+		tx = tx.Where("device_id IS NULL")
+	} else {
+		tx = tx.Where("device_id = ?", filter.DeviceID)
+	}
+	if filter.Metric != "" && filter.Metric != "all" {
+		tx = tx.Where("metric = ?", filter.Metric)
+	}
+	if filter.Time != "" && filter.Time != "all" {
+		if timeThreshold, err := getTimeThreshold(filter.Time); err == nil {
+			tx = tx.Where("computed_at >= ?", timeThreshold)
+		}
+	}
+	if filter.AlertsOnly {
+		tx = tx.Where("alert = ?", true)
+	}
+
+	var results []model.DriftResult
+	err := tx.Order("computed_at ASC, device_id ASC, metric ASC").Find(&results).Error
+	return results, err
+}
@@ -0,0 +1,56 @@
+package postgres
+
+import (
+	"time"
+)
+
+// flagDuplicatesQuery links each not yet flagged transaction since @since to the
+// earliest earlier transaction of the same device within @window seconds and
+// @tolerance of its amount, and records a reason for every new link with the amount
+// of the original as baseline. Originals are never themselves duplicates, so a
+// burst of replays all point to the first one.
+const flagDuplicatesQuery = `
+WITH flagged AS (
+	UPDATE anomaly_results AS d
+	SET duplicate_of = (
+		SELECT o.txn_id FROM anomaly_results AS o
+		WHERE o.device_id = d.device_id
+			AND o.duplicate_of IS NULL
+			AND (o.txn_ts, o.txn_id) < (d.txn_ts, d.txn_id)
+			AND o.txn_ts >= d.txn_ts - make_interval(secs => @window)
+			AND ABS(o.txn_amt - d.txn_amt) <= @tolerance
+		ORDER BY o.txn_ts, o.txn_id
+		LIMIT 1
+	)
+	WHERE d.duplicate_of IS NULL
+		AND d.txn_ts >= @since
+		AND EXISTS (
+			SELECT 1 FROM anomaly_results AS o
+			WHERE o.device_id = d.device_id
+				AND o.duplicate_of IS NULL
+				AND (o.txn_ts, o.txn_id) < (d.txn_ts, d.txn_id)
+				AND o.txn_ts >= d.txn_ts - make_interval(secs => @window)
+				AND ABS(o.txn_amt - d.txn_amt) <= @tolerance
+		)
+	RETURNING d.device_id, d.txn_id, d.txn_amt, d.duplicate_of, (
+		SELECT o.txn_amt FROM anomaly_results AS o
+		WHERE o.device_id = d.device_id AND o.txn_id = d.duplicate_of
+		ORDER BY o.txn_ts
+		LIMIT 1
+	) AS original_amt
+)
+INSERT INTO anomaly_reasons (device_id, txn_id, code, message, feature, value, baseline)
+SELECT device_id, txn_id, @code, 'Likely duplicate of transaction ' || duplicate_of, 'amount', txn_amt, original_amt
+FROM flagged`
+
+// FlagDuplicateTransactions runs the duplicate detector over the transactions since
+// the given time and returns the number of newly flagged duplicates.
+func (r *Repository) FlagDuplicateTransactions(since time.Time, window time.Duration, tolerance float64, reasonCode string) (int64, error) {
+	res := r.DB.Exec(flagDuplicatesQuery, map[string]interface{}{
+		"since":     since,
+		"window":    window.Seconds(),
+		"tolerance": tolerance,
+		"code":      reasonCode,
+	})
+	return res.RowsAffected, res.Error
+}
@@ -0,0 +1,77 @@
+package postgres
+
+import (
+	"testing"
+	"time"
+
+	model "anomaly-go/model/postgres"
+)
+
+// TestFlagDuplicateTransactions runs the duplicate detector over a burst of replays.
+// It needs a scratch database, see testDB.
+func TestFlagDuplicateTransactions(t *testing.T) {
+	db := testDB(t, &model.Transaction{}, &model.AnomalyReason{})
+
+	const deviceID = -1
+	cleanup := func() {
+		db.Where("device_id = ?", deviceID).Delete(&model.AnomalyReason{})
+		db.Where("device_id = ?", deviceID).Delete(&model.Transaction{})
+	}
+	cleanup()
+	t.Cleanup(cleanup)
+
+	start := time.Now().Add(-time.Hour).Truncate(time.Second)
+	txns := []model.Transaction{
+		{DeviceID: deviceID, TransactionID: "original", TransactionTime: start, TransactionAmount: 10},
+		{DeviceID: deviceID, TransactionID: "retry", TransactionTime: start.Add(30 * time.Second), TransactionAmount: 10.01},
+		{DeviceID: deviceID, TransactionID: "replay", TransactionTime: start.Add(90 * time.Second), TransactionAmount: 9.99},
+		{DeviceID: deviceID, TransactionID: "other amount", TransactionTime: start.Add(40 * time.Second), TransactionAmount: 50},
+		{DeviceID: deviceID, TransactionID: "too late", TransactionTime: start.Add(10 * time.Minute), TransactionAmount: 10},
+	}
+	if err := db.Create(&txns).Error; err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	r := NewRepository(db)
+	flagged, err := r.FlagDuplicateTransactions(start.Add(-time.Minute), 2*time.Minute, 0.05, "DUPLICATE")
+	if err != nil {
+		t.Fatalf("FlagDuplicateTransactions() error = %v", err)
+	}
+	if flagged < 2 {
+		t.Errorf("FlagDuplicateTransactions() = %d; want at least the 2 duplicates of the test device", flagged)
+	}
+
+	var stored []model.Transaction
+	if err := db.Where("device_id = ?", deviceID).Find(&stored).Error; err != nil {
+		t.Fatalf("Find() error = %v", err)
+	}
+	want := map[string]string{"retry": "original", "replay": "original"}
+	for _, txn := range stored {
+		if got := txn.DuplicateOf.String; got != want[txn.TransactionID] {
+			t.Errorf("%s duplicate of %q; want %q", txn.TransactionID, got, want[txn.TransactionID])
+		}
+	}
+
+	var reasons []model.AnomalyReason
+	if err := db.Where("device_id = ?", deviceID).Order("txn_id").Find(&reasons).Error; err != nil {
+		t.Fatalf("Find() error = %v", err)
+	}
+	if len(reasons) != 2 || reasons[0].TransactionID != "replay" || reasons[1].TransactionID != "retry" {
+		t.Fatalf("reasons = %+v; want one for replay and one for retry", reasons)
+	}
+	for _, reason := range reasons {
+		if reason.Baseline != 10 {
+			t.Errorf("%s reason baseline = %v; want the original's amount 10", reason.TransactionID, reason.Baseline)
+		}
+	}
+
+	again, err := r.FlagDuplicateTransactions(start.Add(-time.Minute), 2*time.Minute, 0.05, "DUPLICATE")
+	if err != nil {
+		t.Fatalf("FlagDuplicateTransactions() error = %v", err)
+	}
+	var count int64
+	db.Model(&model.AnomalyReason{}).Where("device_id = ?", deviceID).Count(&count)
+	if count != 2 {
+		t.Errorf("second run left %d reasons (flagged %d); want the 2 from the first run", count, again)
+	}
+}
@@ -0,0 +1,86 @@
+package postgres
+
+import (
+	"fmt"
+
+	model "anomaly-go/model/postgres"
+	"anomaly-go/util/constants"
+
+	"gorm.io/gorm"
+)
+
+// confusionSelect classifies reviewed rows into the confusion matrix. A row is
+// predicted positive when its label has a non-zero severity in the taxonomy and is
+// actually positive when the reviewer confirmed it as fraud.
+var confusionSelect = confusionColumns("labels", "")
+
+// confusionColumns builds the confusion matrix columns for the labels joined as
+// alias, naming them prefix+tp, prefix+fp and so on.
+func confusionColumns(alias, prefix string) string {
+	return fmt.Sprintf(`
+	COALESCE(SUM(CASE WHEN COALESCE(%[1]s.severity, 0) > 0 AND LOWER(review) = @fraud THEN 1 ELSE 0 END), 0) AS %[2]stp,
+	COALESCE(SUM(CASE WHEN COALESCE(%[1]s.severity, 0) > 0 AND LOWER(review) = @notFraud THEN 1 ELSE 0 END), 0) AS %[2]sfp,
+	COALESCE(SUM(CASE WHEN COALESCE(%[1]s.severity, 0) = 0 AND LOWER(review) = @notFraud THEN 1 ELSE 0 END), 0) AS %[2]stn,
+	COALESCE(SUM(CASE WHEN COALESCE(%[1]s.severity, 0) = 0 AND LOWER(review) = @fraud THEN 1 ELSE 0 END), 0) AS %[2]sfn`, alias, prefix)
+}
+
+// CountConfusionByWindow builds the confusion matrix of reviewed transactions per time window.
+func (r *Repository) CountConfusionByWindow(filter model.TransactionFilter, window string) ([]model.ConfusionCounts, error) {
+	var counts []model.ConfusionCounts
+	tx := r.reviewedTransactions(filter).
+		Select("date_trunc(@window, txn_ts) AS bucket,"+confusionSelect, reviewVerdicts(map[string]interface{}{"window": window})).
+		Group("bucket").
+		Order("bucket ASC")
+
+	err := tx.Scan(&counts).Error
+	return counts, err
+}
+
+// CountConfusionByDevice builds the confusion matrix of reviewed transactions per device.
+func (r *Repository) CountConfusionByDevice(filter model.TransactionFilter) ([]model.ConfusionCounts, error) {
+	var counts []model.ConfusionCounts
+	tx := r.reviewedTransactions(filter).
+		Select("device_id,"+confusionSelect, reviewVerdicts(nil)).
+		Group("device_id").
+		Order("device_id ASC")
+
+	err := tx.Scan(&counts).Error
+	return counts, err
+}
+
+// CountReviewedByConfidence counts reviewed transactions per whole confidence point,
+// which is enough to evaluate any integer threshold without rescanning the table.
+func (r *Repository) CountReviewedByConfidence(filter model.TransactionFilter) ([]model.ConfidenceBucket, error) {
+	var buckets []model.ConfidenceBucket
+	tx := r.reviewedTransactions(filter).
+		Select(`FLOOR(confidence)::int AS confidence,
+			COALESCE(SUM(CASE WHEN LOWER(review) = @fraud THEN 1 ELSE 0 END), 0) AS positives,
+			COALESCE(SUM(CASE WHEN LOWER(review) = @notFraud THEN 1 ELSE 0 END), 0) AS negatives`, reviewVerdicts(nil)).
+		Group("FLOOR(confidence)::int").
+		Order("confidence ASC")
+
+	err := tx.Scan(&buckets).Error
+	return buckets, err
+}
+
+// reviewedTransactions scopes the query to transactions with a reviewer verdict,
+// joined with the label taxonomy.
+func (r *Repository) reviewedTransactions(filter model.TransactionFilter) *gorm.DB {
+	tx := r.DB.Model(&model.Transaction{}).
+		Joins("LEFT JOIN labels ON labels.name = LOWER(anomaly_results.label)").
+		Where("LOWER(review) IN ?", []string{constants.ReviewFraud, constants.ReviewNotFraud})
+
+	return applyTransactionFilters(tx, filter)
+}
+
+// reviewVerdicts returns the named arguments used by the verdict CASE expressions.
+func reviewVerdicts(extra map[string]interface{}) map[string]interface{} {
+	args := map[string]interface{}{
+		"fraud":    constants.ReviewFraud,
+		"notFraud": constants.ReviewNotFraud,
+	}
+	for k, v := range extra {
+		args[k] = v
+	}
+	return args
+}
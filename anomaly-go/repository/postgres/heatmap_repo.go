@@ -0,0 +1,30 @@
+package postgres
+
+import (
+	model "anomaly-go/model/postgres"
+)
+
+// GetAnomalyHeatmap counts the transactions matching the /fetchData filters and their
+// anomalies (labels with a non-zero severity) per weekday and hour. With a time zone,
+// transaction times are read in serverZone, the zone they are stored in, and
+// converted to it; otherwise they are bucketed as stored.
+func (r *Repository) GetAnomalyHeatmap(filter model.TransactionFilter, timeZone, serverZone string) ([]model.HeatmapCell, error) {
+	localTs := "txn_ts"
+	if timeZone != "" {
+		localTs = "timezone(@tz, timezone(@src, txn_ts))"
+	}
+
+	var cells []model.HeatmapCell
+	tx := r.DB.Model(&model.Transaction{}).
+		Joins("LEFT JOIN labels ON labels.name = LOWER(anomaly_results.label)")
+	tx = applyTransactionFilters(tx, filter).
+		Select(`EXTRACT(ISODOW FROM `+localTs+`)::int AS day_of_week,
+			EXTRACT(HOUR FROM `+localTs+`)::int AS hour,
+			COUNT(*) AS transactions,
+			COALESCE(SUM(CASE WHEN COALESCE(labels.severity, 0) > 0 THEN 1 ELSE 0 END), 0) AS anomalies`,
+			map[string]interface{}{"tz": timeZone, "src": serverZone}).
+		Group("day_of_week, hour")
+
+	err := tx.Scan(&cells).Error
+	return cells, err
+}
@@ -0,0 +1,49 @@
+package postgres
+
+import (
+	model "anomaly-go/model/postgres"
+
+	"gorm.io/gorm/clause"
+)
+
+// GetLabels fetches the whole label taxonomy, most severe first.
+func (r *Repository) GetLabels() ([]model.Label, error) {
+	var labels []model.Label
+	err := r.DB.Order("severity DESC, name ASC").Find(&labels).Error
+	return labels, err
+}
+
+// CreateLabel inserts a new label. Zero rows affected means the label already exists.
+func (r *Repository) CreateLabel(label model.Label) (int64, error) {
+	res := r.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&label)
+	return res.RowsAffected, res.Error
+}
+
+// UpdateLabel updates the display attributes of an existing label.
+func (r *Repository) UpdateLabel(label model.Label) (int64, error) {
+	res := r.DB.Model(&model.Label{}).Where("name = ?", label.Name).Updates(map[string]interface{}{
+		"display_name": label.DisplayName,
+		"severity":     label.Severity,
+		"color":        label.Color,
+	})
+	return res.RowsAffected, res.Error
+}
+
+// DeleteLabel removes a label from the taxonomy.
+func (r *Repository) DeleteLabel(name string) (int64, error) {
+	res := r.DB.Where("name = ?", name).Delete(&model.Label{})
+	return res.RowsAffected, res.Error
+}
+
+// CountTransactionsByLabel counts transactions per lower-cased label based on filters.
+func (r *Repository) CountTransactionsByLabel(filter model.TransactionFilter) ([]model.LabelCount, error) {
+	var counts []model.LabelCount
+	tx := r.DB.Model(&model.Transaction{})
+
+	tx = applyTransactionFilters(tx, filter)
+
+	err := tx.Select("LOWER(label) AS label, COUNT(*) AS count").
+		Group("LOWER(label)").
+		Scan(&counts).Error
+	return counts, err
+}
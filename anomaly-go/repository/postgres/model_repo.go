@@ -0,0 +1,89 @@
+package postgres
+
+import (
+	"time"
+
+	model "anomaly-go/model/postgres"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GetModelVersions fetches the model registry, newest first.
+func (r *Repository) GetModelVersions() ([]model.ModelVersion, error) {
+	var versions []model.ModelVersion
+	err := r.DB.Order("created_at DESC, id DESC").Find(&versions).Error
+	return versions, err
+}
+
+// GetModelVersion fetches one registry entry by ID.
+func (r *Repository) GetModelVersion(id uint) (model.ModelVersion, bool, error) {
+	var version model.ModelVersion
+	err := r.DB.First(&version, id).Error
+	if err == gorm.ErrRecordNotFound {
+		return model.ModelVersion{}, false, nil
+	}
+	return version, err == nil, err
+}
+
+// FindModelVersion fetches one registry entry by name and version.
+func (r *Repository) FindModelVersion(name, version string) (model.ModelVersion, bool, error) {
+	var mv model.ModelVersion
+	err := r.DB.Where("name = ? AND version = ?", name, version).First(&mv).Error
+	if err == gorm.ErrRecordNotFound {
+		return model.ModelVersion{}, false, nil
+	}
+	return mv, err == nil, err
+}
+
+// GetActiveModelVersion fetches the active registry entry, if any.
+func (r *Repository) GetActiveModelVersion() (model.ModelVersion, bool, error) {
+	var version model.ModelVersion
+	err := r.DB.Where("active = ?", true).First(&version).Error
+	if err == gorm.ErrRecordNotFound {
+		return model.ModelVersion{}, false, nil
+	}
+	return version, err == nil, err
+}
+
+// CreateModelVersion registers a model version. Zero rows affected means the
+// name/version pair is already registered.
+func (r *Repository) CreateModelVersion(version *model.ModelVersion) (int64, error) {
+	res := r.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(version)
+	return res.RowsAffected, res.Error
+}
+
+// ActivateModelVersion makes one registry entry the only active model.
+func (r *Repository) ActivateModelVersion(id uint) (int64, error) {
+	var rowsAffected int64
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.ModelVersion{}).Where("active = ? AND id <> ?", true, id).Update("active", false).Error; err != nil {
+			return err
+		}
+		res := tx.Model(&model.ModelVersion{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"active":       true,
+			"activated_at": time.Now(),
+		})
+		rowsAffected = res.RowsAffected
+		return res.Error
+	})
+	return rowsAffected, err
+}
+
+// CompareModelVersions aggregates the transactions matching the /fetchData filters per
+// model version, including the reviewer-confirmed confusion matrix.
+func (r *Repository) CompareModelVersions(filter model.TransactionFilter) ([]model.ModelVersionStats, error) {
+	var stats []model.ModelVersionStats
+	tx := r.DB.Model(&model.Transaction{}).
+		Joins("LEFT JOIN labels ON labels.name = LOWER(anomaly_results.label)")
+	tx = applyTransactionFilters(tx, filter).
+		Select(`model_version,
+			COUNT(*) AS transactions,
+			COALESCE(SUM(CASE WHEN COALESCE(labels.severity, 0) > 0 THEN 1 ELSE 0 END), 0) AS flagged,
+			COALESCE(AVG(confidence), 0) AS avg_confidence,`+confusionSelect, reviewVerdicts(nil)).
+		Group("model_version").
+		Order("model_version ASC")
+
+	err := tx.Scan(&stats).Error
+	return stats, err
+}
@@ -0,0 +1,33 @@
+package postgres
+
+import (
+	model "anomaly-go/model/postgres"
+)
+
+// GetDeviceActivity profiles every device among the transactions matching the
+// /fetchData filters: volume, anomalies (labels with a non-zero severity), average and
+// median amount, and the hour of day with the most transactions.
+func (r *Repository) GetDeviceActivity(filter model.TransactionFilter) ([]model.DeviceActivity, error) {
+	var activity []model.DeviceActivity
+	tx := r.DB.Model(&model.Transaction{}).
+		Joins("LEFT JOIN labels ON labels.name = LOWER(anomaly_results.label)")
+	tx = applyTransactionFilters(tx, filter).
+		Select(`device_id,
+			COUNT(*) AS transactions,
+			COALESCE(SUM(CASE WHEN COALESCE(labels.severity, 0) > 0 THEN 1 ELSE 0 END), 0) AS anomalies,
+			COALESCE(AVG(txn_amt), 0) AS avg_amount,
+			COALESCE(percentile_cont(0.5) WITHIN GROUP (ORDER BY txn_amt), 0) AS median_amount,
+			mode() WITHIN GROUP (ORDER BY EXTRACT(HOUR FROM txn_ts)::int) AS peak_hour`).
+		Group("device_id").
+		Order("device_id ASC")
+
+	err := tx.Scan(&activity).Error
+	return activity, err
+}
+
+// GetBatteryScores fetches the battery score of every device in bl_score.
+func (r *Repository) GetBatteryScores() ([]model.BlScore, error) {
+	var scores []model.BlScore
+	err := r.DB.Find(&scores).Error
+	return scores, err
+}
@@ -0,0 +1,37 @@
+package postgres
+
+import (
+	model "anomaly-go/model/postgres"
+)
+
+// GetReasons fetches the reason codes of every transaction matching the /fetchData filters.
+func (r *Repository) GetReasons(filter model.TransactionFilter) ([]model.AnomalyReason, error) {
+	var reasons []model.AnomalyReason
+	txnKeys := applyTransactionFilters(r.DB.Model(&model.Transaction{}).Select("device_id, txn_id"), filter)
+
+	err := r.DB.Where("(device_id, txn_id) IN (?)", txnKeys).Order("id ASC").Find(&reasons).Error
+	return reasons, err
+}
+
+// CountTopReasons counts, per reason code, the transactions matching the /fetchData
+// filters that carry it, most frequent first.
+func (r *Repository) CountTopReasons(filter model.TransactionFilter, limit int) ([]model.ReasonCount, error) {
+	var counts []model.ReasonCount
+	txnKeys := applyTransactionFilters(r.DB.Model(&model.Transaction{}).Select("device_id, txn_id"), filter)
+
+	err := r.DB.Model(&model.AnomalyReason{}).
+		Select("code, COUNT(DISTINCT (device_id, txn_id)) AS transactions, COUNT(*) AS occurrences").
+		Where("(device_id, txn_id) IN (?)", txnKeys).
+		Group("code").
+		Order("transactions DESC, code ASC").
+		Limit(limit).
+		Scan(&counts).Error
+	return counts, err
+}
+
+// CountTransactions counts the transactions matching the /fetchData filters.
+func (r *Repository) CountTransactions(filter model.TransactionFilter) (int64, error) {
+	var count int64
+	err := applyTransactionFilters(r.DB.Model(&model.Transaction{}), filter).Count(&count).Error
+	return count, err
+}
@@ -0,0 +1,177 @@
+package postgres
+
+import (
+	"errors"
+
+	model "anomaly-go/model/postgres"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CreateRescoreJob stores a new rescore job.
+func (r *Repository) CreateRescoreJob(job *model.RescoreJob) error {
+	return r.DB.Create(job).Error
+}
+
+// GetRescoreJob fetches a rescore job by ID.
+func (r *Repository) GetRescoreJob(id uint) (model.RescoreJob, bool, error) {
+	var job model.RescoreJob
+	err := r.DB.First(&job, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return job, false, nil
+	}
+	return job, err == nil, err
+}
+
+// GetLatestRescoreJob fetches the most recently created rescore job.
+func (r *Repository) GetLatestRescoreJob() (model.RescoreJob, bool, error) {
+	var job model.RescoreJob
+	err := r.DB.Order("id DESC").First(&job).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return job, false, nil
+	}
+	return job, err == nil, err
+}
+
+// GetRescoreJobsByStatus fetches the rescore jobs in the given status, oldest first.
+func (r *Repository) GetRescoreJobsByStatus(status string) ([]model.RescoreJob, error) {
+	var jobs []model.RescoreJob
+	err := r.DB.Where("status = ?", status).Order("id").Find(&jobs).Error
+	return jobs, err
+}
+
+// UpdateRescoreJobStatus sets the status of a rescore job, along with its error and
+// finish time when it ends.
+func (r *Repository) UpdateRescoreJobStatus(job *model.RescoreJob) error {
+	return r.DB.Model(job).Select("status", "error", "finished_at", "updated_at").Updates(job).Error
+}
+
+// FetchRescoreChunk streams anomaly_results in (txn_ts, device_id, txn_id) order,
+// returning the rows after the job checkpoint up to the end of its range.
+func (r *Repository) FetchRescoreChunk(job model.RescoreJob) ([]model.Transaction, error) {
+	var txns []model.Transaction
+	err := rescoreRemaining(r.DB, job).Order("txn_ts, device_id, txn_id").Limit(job.ChunkSize).Find(&txns).Error
+	return txns, err
+}
+
+// CountRescoreRemaining counts the rows a rescore job has not processed yet.
+func (r *Repository) CountRescoreRemaining(job model.RescoreJob) (int64, error) {
+	var count int64
+	err := rescoreRemaining(r.DB, job).Count(&count).Error
+	return count, err
+}
+
+// rescoreRemaining selects the anomaly_results after the job checkpoint.
+func rescoreRemaining(db *gorm.DB, job model.RescoreJob) *gorm.DB {
+	query := db.Model(&model.Transaction{})
+	if job.RangeTo.Valid {
+		query = query.Where("txn_ts < ?", job.RangeTo.Time)
+	}
+	if job.LastTxnTime.Valid {
+		query = query.Where("(txn_ts, device_id, txn_id) > (?, ?, ?)", job.LastTxnTime.Time, job.LastDeviceID, job.LastTxnID)
+	}
+	return query
+}
+
+// GetRescoreBaselines fetches the baselines a rescore job has built so far for the
+// given devices.
+func (r *Repository) GetRescoreBaselines(jobID uint, deviceIDs []int64) ([]model.RescoreBaseline, error) {
+	var baselines []model.RescoreBaseline
+	err := r.DB.Where("job_id = ? AND device_id IN ?", jobID, deviceIDs).Find(&baselines).Error
+	return baselines, err
+}
+
+// DeleteRescoreBaselines drops the baselines of a finished rescore job.
+func (r *Repository) DeleteRescoreBaselines(jobID uint) error {
+	return r.DB.Where("job_id = ?", jobID).Delete(&model.RescoreBaseline{}).Error
+}
+
+// GetRuleHitTransactionIDs returns, per device, the IDs among txnIDs that have at
+// least one rule hit.
+func (r *Repository) GetRuleHitTransactionIDs(txnIDs []string) (map[int64]map[string]bool, error) {
+	var rows []struct {
+		DeviceID      int64
+		TransactionID string `gorm:"column:txn_id"`
+	}
+	err := r.DB.Model(&model.RuleHit{}).
+		Distinct("device_id", "txn_id").
+		Where("txn_id IN ?", txnIDs).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	ids := map[int64]map[string]bool{}
+	for _, row := range rows {
+		if ids[row.DeviceID] == nil {
+			ids[row.DeviceID] = map[string]bool{}
+		}
+		ids[row.DeviceID][row.TransactionID] = true
+	}
+	return ids, nil
+}
+
+// SaveRescoreChunk writes the scores of one chunk, the job baselines and the job
+// checkpoint in a single database transaction, so an interrupted job resumes from the
+// last saved chunk. Live scores replace confidence, label, model_version and the
+// scorer reasons, keeping the reasons whose code matches one of the keptReasons LIKE
+// patterns, but never on rows that have a reviewer verdict; shadow scores only touch
+// the shadow columns. It returns the number of rows written.
+func (r *Repository) SaveRescoreChunk(job *model.RescoreJob, scores []model.RescoreScore, shadow bool, baselines []model.RescoreBaseline, keptReasons []string) (int64, error) {
+	var written int64
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		for _, sc := range scores {
+			row := tx.Model(&model.Transaction{}).Where("device_id = ? AND txn_id = ?", sc.DeviceID, sc.TransactionID)
+
+			if shadow {
+				res := row.Updates(map[string]interface{}{
+					"shadow_confidence":    sc.Confidence,
+					"shadow_label":         sc.Label,
+					"shadow_model_version": sc.ModelVersion,
+					"shadow_threshold":     job.Threshold,
+				})
+				if res.Error != nil {
+					return res.Error
+				}
+				written += res.RowsAffected
+				continue
+			}
+
+			res := row.Where("(review IS NULL OR review = '')").Updates(map[string]interface{}{
+				"confidence":    sc.Confidence,
+				"label":         sc.Label,
+				"model_version": sc.ModelVersion,
+			})
+			if res.Error != nil {
+				return res.Error
+			}
+			if res.RowsAffected == 0 {
+				continue
+			}
+			written++
+
+			stale := tx.Where("device_id = ? AND txn_id = ?", sc.DeviceID, sc.TransactionID)
+			for _, pattern := range keptReasons {
+				stale = stale.Where("code NOT LIKE ?", pattern)
+			}
+			if err := stale.Delete(&model.AnomalyReason{}).Error; err != nil {
+				return err
+			}
+			if len(sc.Reasons) > 0 {
+				if err := tx.Create(&sc.Reasons).Error; err != nil {
+					return err
+				}
+			}
+		}
+
+		if len(baselines) > 0 {
+			if err := tx.Clauses(clause.OnConflict{UpdateAll: true}).Create(&baselines).Error; err != nil {
+				return err
+			}
+		}
+		job.Rescored += written
+		return tx.Save(job).Error
+	})
+	return written, err
+}
@@ -0,0 +1,38 @@
+package postgres
+
+import (
+	model "anomaly-go/model/postgres"
+
+	"gorm.io/gorm/clause"
+)
+
+// GetRiskTiers fetches the battery risk tiers, lowest boundary first.
+func (r *Repository) GetRiskTiers() ([]model.BatteryRiskTier, error) {
+	var tiers []model.BatteryRiskTier
+	err := r.DB.Order("max_score ASC NULLS LAST, name ASC").Find(&tiers).Error
+	return tiers, err
+}
+
+// CreateRiskTier inserts a new tier. Zero rows affected means the name or the
+// boundary is already taken.
+func (r *Repository) CreateRiskTier(tier model.BatteryRiskTier) (int64, error) {
+	res := r.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&tier)
+	return res.RowsAffected, res.Error
+}
+
+// UpdateRiskTier updates the boundary and display attributes of an existing tier.
+func (r *Repository) UpdateRiskTier(tier model.BatteryRiskTier) (int64, error) {
+	res := r.DB.Model(&model.BatteryRiskTier{}).Where("name = ?", tier.Name).Updates(map[string]interface{}{
+		"display_name": tier.DisplayName,
+		"max_score":    tier.MaxScore,
+		"at_risk":      tier.AtRisk,
+		"color":        tier.Color,
+	})
+	return res.RowsAffected, res.Error
+}
+
+// DeleteRiskTier removes a tier.
+func (r *Repository) DeleteRiskTier(name string) (int64, error) {
+	res := r.DB.Where("name = ?", name).Delete(&model.BatteryRiskTier{})
+	return res.RowsAffected, res.Error
+}
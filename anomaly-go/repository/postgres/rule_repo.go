@@ -0,0 +1,88 @@
+package postgres
+
+import (
+	"time"
+
+	model "anomaly-go/model/postgres"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GetFraudRules fetches all fraud rules, optionally only the enabled ones.
+func (r *Repository) GetFraudRules(enabledOnly bool) ([]model.FraudRule, error) {
+	var rules []model.FraudRule
+	tx := r.DB.Model(&model.FraudRule{})
+	if enabledOnly {
+		tx = tx.Where("enabled = ?", true)
+	}
+	err := tx.Order("id ASC").Find(&rules).Error
+	return rules, err
+}
+
+// CreateFraudRule inserts a new rule. Zero rows affected means the name is already taken.
+func (r *Repository) CreateFraudRule(rule *model.FraudRule) (int64, error) {
+	res := r.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(rule)
+	return res.RowsAffected, res.Error
+}
+
+// UpdateFraudRule updates the definition and enabled flag of a rule.
+func (r *Repository) UpdateFraudRule(rule model.FraudRule) (int64, error) {
+	res := r.DB.Model(&model.FraudRule{}).Where("id = ?", rule.ID).Updates(map[string]interface{}{
+		"name":       rule.Name,
+		"definition": rule.Definition,
+		"enabled":    rule.Enabled,
+		"updated_at": time.Now(),
+	})
+	return res.RowsAffected, res.Error
+}
+
+// DeleteFraudRule removes a rule. Past hits are kept with the rule name they were recorded under.
+func (r *Repository) DeleteFraudRule(id uint) (int64, error) {
+	res := r.DB.Delete(&model.FraudRule{}, id)
+	return res.RowsAffected, res.Error
+}
+
+// GetRuleHits fetches the rule hits of every transaction matching the /fetchData filters.
+func (r *Repository) GetRuleHits(filter model.TransactionFilter) ([]model.RuleHit, error) {
+	var hits []model.RuleHit
+	txnKeys := applyTransactionFilters(r.DB.Model(&model.Transaction{}).Select("device_id, txn_id"), filter)
+
+	err := r.DB.Where("(device_id, txn_id) IN (?)", txnKeys).Order("id ASC").Find(&hits).Error
+	return hits, err
+}
+
+// --- Window queries used by the rule engine ---
+
+// CountDeviceTransactions counts a device's transactions with from < txn_ts <= to.
+func (r *Repository) CountDeviceTransactions(deviceID int64, from, to time.Time) (int64, error) {
+	var count int64
+	err := deviceWindow(r.DB, deviceID, from, to).Count(&count).Error
+	return count, err
+}
+
+// CountDeviceAmount counts a device's transactions of exactly the given amount with from < txn_ts <= to.
+func (r *Repository) CountDeviceAmount(deviceID int64, amount float64, from, to time.Time) (int64, error) {
+	var count int64
+	err := deviceWindow(r.DB, deviceID, from, to).Where("txn_amt = ?", amount).Count(&count).Error
+	return count, err
+}
+
+// MedianDeviceAmount returns the median amount of a device's transactions with
+// from < txn_ts <= to, together with the number of transactions it is based on.
+func (r *Repository) MedianDeviceAmount(deviceID int64, from, to time.Time) (float64, int64, error) {
+	var result struct {
+		Median  float64
+		Samples int64
+	}
+	err := deviceWindow(r.DB, deviceID, from, to).
+		Select("COALESCE(percentile_cont(0.5) WITHIN GROUP (ORDER BY txn_amt), 0) AS median, COUNT(*) AS samples").
+		Scan(&result).Error
+	return result.Median, result.Samples, err
+}
+
+// deviceWindow scopes anomaly_results to one device and a half-open time window.
+func deviceWindow(db *gorm.DB, deviceID int64, from, to time.Time) *gorm.DB {
+	return db.Model(&model.Transaction{}).
+		Where("device_id = ? AND txn_ts > ? AND txn_ts <= ?", deviceID, from, to)
+}
@@ -0,0 +1,45 @@
+package postgres
+
+import (
+	model "anomaly-go/model/postgres"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GetDeviceBaselines fetches the stored scorer baselines of the given devices.
+func (r *Repository) GetDeviceBaselines(deviceIDs []int64) ([]model.DeviceBaseline, error) {
+	var baselines []model.DeviceBaseline
+	err := r.DB.Where("device_id IN ?", deviceIDs).Find(&baselines).Error
+	return baselines, err
+}
+
+// InsertScoredTransaction stores a newly scored transaction together with its reasons,
+// rule hits and the device baseline that includes it, in a single database transaction. It
+// returns false without touching anything when the transaction already exists.
+func (r *Repository) InsertScoredTransaction(txn model.Transaction, reasons []model.AnomalyReason, hits []model.RuleHit, baseline model.DeviceBaseline) (bool, error) {
+	inserted := false
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&txn)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return nil
+		}
+		inserted = true
+
+		if len(reasons) > 0 {
+			if err := tx.Create(&reasons).Error; err != nil {
+				return err
+			}
+		}
+		if len(hits) > 0 {
+			if err := tx.Create(&hits).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Clauses(clause.OnConflict{UpdateAll: true}).Create(&baseline).Error
+	})
+	return inserted, err
+}
@@ -0,0 +1,43 @@
+package postgres
+
+import (
+	"time"
+
+	model "anomaly-go/model/postgres"
+
+	"gorm.io/gorm/clause"
+)
+
+// GetDeviceSeasonality fetches the seasonal profiles of the given devices, or of
+// every device when deviceIDs is nil.
+func (r *Repository) GetDeviceSeasonality(deviceIDs []int64) ([]model.DeviceSeasonality, error) {
+	var profiles []model.DeviceSeasonality
+	tx := r.DB.Model(&model.DeviceSeasonality{})
+	if deviceIDs != nil {
+		tx = tx.Where("device_id IN ?", deviceIDs)
+	}
+	err := tx.Find(&profiles).Error
+	return profiles, err
+}
+
+// SaveDeviceSeasonality upserts seasonal profiles.
+func (r *Repository) SaveDeviceSeasonality(profiles []model.DeviceSeasonality) error {
+	if len(profiles) == 0 {
+		return nil
+	}
+	return r.DB.Clauses(clause.OnConflict{UpdateAll: true}).CreateInBatches(&profiles, 100).Error
+}
+
+// CountHourlyTransactions counts the transactions per device and hour in [from, to),
+// optionally for a single device.
+func (r *Repository) CountHourlyTransactions(from, to time.Time, deviceID int64) ([]model.HourlyCount, error) {
+	var counts []model.HourlyCount
+	tx := r.DB.Model(&model.Transaction{}).
+		Select("device_id, date_trunc('hour', txn_ts) AS hour, COUNT(*) AS count").
+		Where("txn_ts >= ? AND txn_ts < ?", from, to)
+	if deviceID != 0 {
+		tx = tx.Where("device_id = ?", deviceID)
+	}
+	err := tx.Group("device_id, hour").Order("device_id, hour").Scan(&counts).Error
+	return counts, err
+}
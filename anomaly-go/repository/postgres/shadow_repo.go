@@ -0,0 +1,68 @@
+package postgres
+
+import (
+	"time"
+
+	model "anomaly-go/model/postgres"
+
+	"gorm.io/gorm"
+)
+
+// GetActiveShadowCandidate fetches the candidate currently run in shadow mode, if any.
+func (r *Repository) GetActiveShadowCandidate() (model.ShadowCandidate, bool, error) {
+	var candidate model.ShadowCandidate
+	err := r.DB.Where("active = ?", true).First(&candidate).Error
+	if err == gorm.ErrRecordNotFound {
+		return model.ShadowCandidate{}, false, nil
+	}
+	return candidate, err == nil, err
+}
+
+// StartShadowCandidate stores a candidate as the only active one.
+func (r *Repository) StartShadowCandidate(candidate *model.ShadowCandidate) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := stopShadowCandidates(tx).Error; err != nil {
+			return err
+		}
+		candidate.Active = true
+		return tx.Create(candidate).Error
+	})
+}
+
+// StopShadowCandidate ends shadow mode. Zero rows affected means no candidate was active.
+func (r *Repository) StopShadowCandidate() (int64, error) {
+	res := stopShadowCandidates(r.DB)
+	return res.RowsAffected, res.Error
+}
+
+func stopShadowCandidates(db *gorm.DB) *gorm.DB {
+	return db.Model(&model.ShadowCandidate{}).Where("active = ?", true).Updates(map[string]interface{}{
+		"active":     false,
+		"stopped_at": time.Now(),
+	})
+}
+
+// CompareShadow aggregates live against shadow labels among the transactions matching
+// the /fetchData filters, per shadow model version and threshold. A side flags a
+// transaction when its label has a non-zero severity in the taxonomy.
+func (r *Repository) CompareShadow(filter model.TransactionFilter) ([]model.ShadowComparisonStats, error) {
+	var stats []model.ShadowComparisonStats
+	tx := r.DB.Model(&model.Transaction{}).
+		Joins("LEFT JOIN labels AS live ON live.name = LOWER(anomaly_results.label)").
+		Joins("LEFT JOIN labels AS shadow ON shadow.name = LOWER(anomaly_results.shadow_label)").
+		Where("anomaly_results.shadow_label IS NOT NULL")
+	tx = applyTransactionFilters(tx, filter).
+		Select(`shadow_model_version, shadow_threshold,
+			COUNT(*) AS transactions,
+			COALESCE(SUM(CASE WHEN COALESCE(live.severity, 0) > 0 THEN 1 ELSE 0 END), 0) AS live_flagged,
+			COALESCE(SUM(CASE WHEN COALESCE(shadow.severity, 0) > 0 THEN 1 ELSE 0 END), 0) AS shadow_flagged,
+			COALESCE(SUM(CASE WHEN (COALESCE(live.severity, 0) > 0) = (COALESCE(shadow.severity, 0) > 0) THEN 1 ELSE 0 END), 0) AS agreements,
+			COALESCE(SUM(CASE WHEN COALESCE(live.severity, 0) = 0 AND COALESCE(shadow.severity, 0) > 0 THEN 1 ELSE 0 END), 0) AS extra_flags,
+			COALESCE(SUM(CASE WHEN COALESCE(live.severity, 0) > 0 AND COALESCE(shadow.severity, 0) = 0 THEN 1 ELSE 0 END), 0) AS missed_flags,`+
+			confusionColumns("live", "live_")+","+confusionColumns("shadow", "shadow_"), reviewVerdicts(nil)).
+		Group("shadow_model_version, shadow_threshold").
+		Order("shadow_model_version ASC, shadow_threshold ASC")
+
+	err := tx.Scan(&stats).Error
+	return stats, err
+}
@@ -0,0 +1,88 @@
+package postgres
+
+import (
+	"time"
+
+	model "anomaly-go/model/postgres"
+)
+
+// GetOpenSilentDevices fetches the silent device findings not resolved yet.
+func (r *Repository) GetOpenSilentDevices() ([]model.SilentDevice, error) {
+	var findings []model.SilentDevice
+	err := r.DB.Where("resolved_at IS NULL").Find(&findings).Error
+	return findings, err
+}
+
+// SaveSilentDevices inserts new findings and updates existing ones.
+func (r *Repository) SaveSilentDevices(findings []model.SilentDevice) error {
+	if len(findings) == 0 {
+		return nil
+	}
+	return r.DB.Save(&findings).Error
+}
+
+// ResolveSilentDevices closes the given open findings.
+func (r *Repository) ResolveSilentDevices(ids []uint, at time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.DB.Model(&model.SilentDevice{}).
+		Where("id IN ? AND resolved_at IS NULL", ids).
+		Updates(map[string]interface{}{"resolved_at": at, "updated_at": at}).Error
+}
+
+// GetSilentDevices fetches the findings matching the filter, latest first.
+func (r *Repository) GetSilentDevices(filter model.SilentDeviceFilter) ([]model.SilentDevice, error) {
+	tx := r.DB.Model(&model.SilentDevice{})
+	switch filter.Status {
+	case "open":
+		tx = tx.Where("resolved_at IS NULL")
+	case "resolved":
+		tx = tx.Where("resolved_at IS NOT NULL")
+	}
+	if filter.DeviceID != 0 {
+		tx = tx.Where("device_id = ?", filter.DeviceID)
+	}
+	if filter.Cause != "" && filter.Cause != "all" {
+		tx = tx.Where("cause = ?", filter.Cause)
+	}
+	if filter.Time != "" && filter.Time != "all" {
+		if timeThreshold, err := getTimeThreshold(filter.Time); err == nil {
+			tx = tx.Where("detected_at >= ?", timeThreshold)
+		}
+	}
+
+	var findings []model.SilentDevice
+	err := tx.Order("detected_at DESC, device_id ASC").Find(&findings).Error
+	return findings, err
+}
+
+// GetLatestBatteryReadings fetches the most recent battery_health block of each of
+// the given devices.
+func (r *Repository) GetLatestBatteryReadings(deviceIDs []int64) ([]model.BatteryReading, error) {
+	var readings []model.BatteryReading
+	if len(deviceIDs) == 0 {
+		return readings, nil
+	}
+	err := r.DB.Raw(`SELECT DISTINCT ON (device_id) device_id, cs, end_bl, end_time
+		FROM battery_health
+		WHERE device_id IN ?
+		ORDER BY device_id, end_time DESC NULLS LAST, block DESC`, deviceIDs).
+		Scan(&readings).Error
+	return readings, err
+}
+
+// GetLastTransactionTimes fetches the time of the last transaction of each of the
+// given devices.
+func (r *Repository) GetLastTransactionTimes(deviceIDs []int64) ([]model.DeviceLastTransaction, error) {
+	var last []model.DeviceLastTransaction
+	if len(deviceIDs) == 0 {
+		return last, nil
+	}
+	err := r.DB.Model(&model.Transaction{}).
+		Select("device_id, MAX(txn_ts) AS last_txn_at").
+		Where("device_id IN ?", deviceIDs).
+		Group("device_id").
+		Scan(&last).Error
+	return last, err
+}
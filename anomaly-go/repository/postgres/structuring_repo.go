@@ -0,0 +1,18 @@
+package postgres
+
+import (
+	model "anomaly-go/model/postgres"
+)
+
+// FetchStructuringTransactions fetches the amounts of the transactions matching the
+// /fetchData filters, ordered by device and time window.
+func (r *Repository) FetchStructuringTransactions(filter model.TransactionFilter, window string) ([]model.StructuringTransaction, error) {
+	var txns []model.StructuringTransaction
+	tx := applyTransactionFilters(r.DB.Model(&model.Transaction{}), filter).
+		Select("device_id, txn_id, txn_ts, txn_amt, date_trunc(@window, txn_ts) AS window_start",
+			map[string]interface{}{"window": window}).
+		Order("device_id ASC, window_start ASC, txn_ts ASC")
+
+	err := tx.Scan(&txns).Error
+	return txns, err
+}
@@ -0,0 +1,27 @@
+package postgres
+
+import (
+	"os"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// testDB opens the scratch database given as a DSN in ANOMALY_TEST_POSTGRES_DSN and
+// migrates the given models. Tests using it are skipped when the variable is not set.
+func testDB(t *testing.T, models ...interface{}) *gorm.DB {
+	t.Helper()
+	dsn := os.Getenv("ANOMALY_TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("ANOMALY_TEST_POSTGRES_DSN not set")
+	}
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("gorm.Open() error = %v", err)
+	}
+	if err := db.AutoMigrate(models...); err != nil {
+		t.Fatalf("AutoMigrate() error = %v", err)
+	}
+	return db
+}
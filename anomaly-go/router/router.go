@@ -55,10 +55,60 @@ func SetupRouter(api *controller.API, jwtSecret string) *gin.Engine {
 		protected.POST("/updateReview", api.UpdateReviewHandler)
 		protected.GET("/getDeviceHealthData", api.GetDeviceHealthDataHandler)
 		protected.GET("/getAtRiskKPIs", api.GetAtRiskKPIsHandler)
+		protected.GET("/getBatteryKPITrend", api.GetBatteryKPITrendHandler)
+		protected.GET("/getRiskTiers", api.GetRiskTiersHandler)
+		protected.GET("/getBatteryForecast", api.GetBatteryForecastHandler)
+		protected.GET("/getBatteryTimeSeries", api.GetBatteryTimeSeriesHandler)
+		protected.GET("/getBatteryWear", api.GetBatteryWearHandler)
+		protected.GET("/getFleetBatteryWear", api.GetFleetBatteryWearHandler)
+		protected.GET("/getBatteryDataQuality", api.GetBatteryDataQualityHandler)
+		protected.GET("/getFleetDataQuality", api.GetFleetDataQualityHandler)
+		protected.GET("/getLabels", api.GetLabelsHandler)
+		protected.GET("/getModelEvaluation", api.GetModelEvaluationHandler)
+		protected.POST("/ingestTransactions", api.IngestTransactionsHandler)
+		protected.POST("/ingestBatteryBlocks", api.IngestBatteryBlocksHandler)
+		protected.GET("/getRules", api.GetRulesHandler)
+		protected.GET("/getTopReasons", api.GetTopReasonsHandler)
+		protected.GET("/getModels", api.GetModelsHandler)
+		protected.GET("/compareModelVersions", api.CompareModelVersionsHandler)
+		protected.GET("/getDriftSeries", api.GetDriftSeriesHandler)
+		protected.GET("/getStructuringFindings", api.GetStructuringFindingsHandler)
+		protected.GET("/getPeerBenchmark", api.GetPeerBenchmarkHandler)
+		protected.GET("/getSeasonalityProfile", api.GetSeasonalityProfileHandler)
+		protected.GET("/getSilentDevices", api.GetSilentDevicesHandler)
+		protected.GET("/getAnomalyHeatmap", api.GetAnomalyHeatmapHandler)
 	}
 
 	log.WriteLog.Info("Registered protected routes (JWT required)", zap.String("group", "/"))
 
+	// -----------------------------
+	// ADMIN ROUTES (JWT + ADMIN USER REQUIRED)
+	// -----------------------------
+	admin := r.Group("/admin").
+		Use(auth.JWTMiddleware(jwtSecret), auth.AdminMiddleware())
+	{
+		admin.POST("/createLabel", api.CreateLabelHandler)
+		admin.POST("/updateLabel", api.UpdateLabelHandler)
+		admin.DELETE("/deleteLabel", api.DeleteLabelHandler)
+		admin.POST("/createRule", api.CreateRuleHandler)
+		admin.POST("/updateRule", api.UpdateRuleHandler)
+		admin.DELETE("/deleteRule", api.DeleteRuleHandler)
+		admin.POST("/registerModel", api.RegisterModelHandler)
+		admin.POST("/activateModel", api.ActivateModelHandler)
+		admin.POST("/startRescore", api.StartRescoreHandler)
+		admin.POST("/pauseRescore", api.PauseRescoreHandler)
+		admin.GET("/getRescoreStatus", api.GetRescoreStatusHandler)
+		admin.POST("/startShadow", api.StartShadowHandler)
+		admin.POST("/stopShadow", api.StopShadowHandler)
+		admin.GET("/compareShadow", api.CompareShadowHandler)
+		admin.POST("/createRiskTier", api.CreateRiskTierHandler)
+		admin.POST("/updateRiskTier", api.UpdateRiskTierHandler)
+		admin.DELETE("/deleteRiskTier", api.DeleteRiskTierHandler)
+		admin.POST("/reevaluateBatteryAnomalies", api.ReevaluateBatteryAnomaliesHandler)
+	}
+
+	log.WriteLog.Info("Registered admin routes (JWT + admin required)", zap.String("group", "/admin"))
+
 	// -----------------------------
 	// DEFAULT HEALTH CHECK (optional)
 	// -----------------------------
@@ -1,13 +1,19 @@
 package service
 
 import (
+	"context"
 	"fmt"
+	"sync"
 
 	"anomaly-go/config/readenv"
 	"anomaly-go/log"
 	"anomaly-go/middleware/auth"
 	jsonmodel "anomaly-go/model/json"
+	model "anomaly-go/model/postgres"
 	repo "anomaly-go/repository/postgres"
+	"anomaly-go/service/rules"
+	"anomaly-go/service/scoring"
+	"anomaly-go/util/constants"
 
 	"go.uber.org/zap"
 	"gorm.io/gorm"
@@ -17,13 +23,40 @@ import (
 type Service struct {
 	Repo   *repo.Repository
 	Config *readenv.AppConfig
+	Rules  *rules.Engine
+
+	// scorer is the active model from the registry, swapped at runtime on activation.
+	// shadow is the candidate scored next to it, if any. Both are guarded by scorerMu.
+	scorer   scoring.Scorer
+	shadow   *shadowCandidate
+	scorerMu sync.RWMutex
+
+	// scoreMu serializes ingestion so device baselines are read and written by one
+	// batch at a time.
+	scoreMu sync.Mutex
+
+	// jobsCtx is the context given to StartBackgroundJobs; on-demand jobs such as
+	// rescoring stop with it.
+	jobsCtx context.Context
+
+	// rescore tracks the rescore job running in the background, if any.
+	rescoreMu     sync.Mutex
+	rescoreJobID  uint
+	rescoreCancel context.CancelFunc
+	rescorePause  bool
+
+	// batteryAnomalyMu keeps one battery anomaly detection run at a time.
+	batteryAnomalyMu sync.Mutex
 }
 
 // NewService creates a new anomaly service.
 func NewService(db *gorm.DB, cfg *readenv.AppConfig) *Service {
 	return &Service{
-		Repo:   repo.NewRepository(db),
-		Config: cfg,
+		Repo:    repo.NewRepository(db),
+		Config:  cfg,
+		Rules:   rules.NewEngine(),
+		scorer:  scoring.Default(),
+		jobsCtx: context.Background(),
 	}
 }
 
@@ -58,19 +91,40 @@ func (s *Service) UpdateConfidenceThreshold(threshold int) (int64, error) {
 }
 
 // FetchData retrieves transactions and metrics with filters.
-func (s *Service) FetchData(timeFilter, anomalyCheck, deviceID, searchTerm string) (jsonmodel.FetchDataResponse, error) {
-	transactions, err := s.Repo.FetchTransactions(timeFilter, anomalyCheck, deviceID, searchTerm)
+func (s *Service) FetchData(filter model.TransactionFilter) (jsonmodel.FetchDataResponse, error) {
+	transactions, err := s.Repo.FetchTransactions(filter)
 	if err != nil {
 		log.WriteLog.Error("Failed to fetch transactions", zap.Error(err))
 		return jsonmodel.FetchDataResponse{}, fmt.Errorf("500:could not fetch transaction data: %w", err)
 	}
 
-	reviewRequired, anomalyDetected, fraud, nullAnomaly, err := s.Repo.CountTransactionMetrics(timeFilter, anomalyCheck, deviceID, searchTerm)
+	labelCounts, err := s.Repo.CountTransactionsByLabel(filter)
 	if err != nil {
 		log.WriteLog.Error("Failed to count transaction metrics", zap.Error(err))
 		return jsonmodel.FetchDataResponse{}, fmt.Errorf("500:could not fetch transaction metrics: %w", err)
 	}
 
+	labels, err := s.Repo.GetLabels()
+	if err != nil {
+		log.WriteLog.Error("Failed to fetch labels", zap.Error(err))
+		return jsonmodel.FetchDataResponse{}, fmt.Errorf("500:could not fetch labels: %w", err)
+	}
+	labelMetrics := buildLabelMetrics(labelCounts, labels)
+
+	ruleHits, err := s.Repo.GetRuleHits(filter)
+	if err != nil {
+		log.WriteLog.Error("Failed to fetch rule hits", zap.Error(err))
+		return jsonmodel.FetchDataResponse{}, fmt.Errorf("500:could not fetch rule hits: %w", err)
+	}
+	hitsByTxn := groupRuleHits(ruleHits)
+
+	reasons, err := s.Repo.GetReasons(filter)
+	if err != nil {
+		log.WriteLog.Error("Failed to fetch reason codes", zap.Error(err))
+		return jsonmodel.FetchDataResponse{}, fmt.Errorf("500:could not fetch reason codes: %w", err)
+	}
+	reasonsByTxn := groupReasons(reasons)
+
 	// Convert DB transactions to JSON model
 	var jsonTransactions []jsonmodel.Transaction
 	for _, t := range transactions {
@@ -80,6 +134,8 @@ func (s *Service) FetchData(timeFilter, anomalyCheck, deviceID, searchTerm strin
 			TransactionTime:   t.TransactionTime.Format("2006-01-02 15:04:05"),
 			TransactionAmount: t.TransactionAmount,
 			ConfidenceScore:   t.ConfidenceScore,
+			RuleHits:          hitsByTxn[txnKey{t.DeviceID, t.TransactionID}],
+			Reasons:           reasonsByTxn[txnKey{t.DeviceID, t.TransactionID}],
 		}
 		if t.AnomalyCheck.Valid {
 			jsonT.AnomalyCheck = &t.AnomalyCheck.String
@@ -87,15 +143,22 @@ func (s *Service) FetchData(timeFilter, anomalyCheck, deviceID, searchTerm strin
 		if t.Review.Valid {
 			jsonT.Review = &t.Review.String
 		}
+		if t.ModelVersion.Valid {
+			jsonT.ModelVersion = &t.ModelVersion.String
+		}
+		if t.DuplicateOf.Valid {
+			jsonT.DuplicateOf = &t.DuplicateOf.String
+		}
 		jsonTransactions = append(jsonTransactions, jsonT)
 	}
 
 	return jsonmodel.FetchDataResponse{
 		Transactions:          jsonTransactions,
-		TotalReviewRequired:   reviewRequired,
-		TotalAnomalyDetected:  anomalyDetected,
-		TotalFraud:            fraud,
-		TotalNullAnomalyCheck: nullAnomaly,
+		TotalReviewRequired:   labelMetrics[constants.LabelReviewRequired].Count,
+		TotalAnomalyDetected:  labelMetrics[constants.LabelAnomalyDetected].Count,
+		TotalFraud:            labelMetrics[constants.LabelFraud].Count,
+		TotalNullAnomalyCheck: labelMetrics[constants.LabelNullKey].Count,
+		LabelMetrics:          labelMetrics,
 	}, nil
 }
 
@@ -129,59 +192,49 @@ func (s *Service) UpdateReview(txnID, review string) (int64, error) {
 	return rowsAffected, nil
 }
 
-// GetDeviceHealthData fetches battery health data with filters.
-func (s *Service) GetDeviceHealthData(deviceIDStr, chargingStatus, isAnomaly, searchTerm string) ([]jsonmodel.DeviceHealth, error) {
-	data, err := s.Repo.GetDeviceHealthData(deviceIDStr, chargingStatus, isAnomaly, searchTerm)
+// GetDeviceHealthData fetches one page of battery health data with filters. Invalid
+// filters are reported as ErrInvalidFilter.
+func (s *Service) GetDeviceHealthData(query jsonmodel.DeviceHealthQuery) (jsonmodel.DeviceHealthResponse, error) {
+	filter, err := parseDeviceHealthQuery(query)
 	if err != nil {
-		log.WriteLog.Error("Failed to fetch device health data", zap.Error(err))
-		return nil, fmt.Errorf("500:could not fetch device health data: %w", err)
+		return jsonmodel.DeviceHealthResponse{}, err
 	}
 
-	var jsonData []jsonmodel.DeviceHealth
-	for _, d := range data {
-		jsonD := jsonmodel.DeviceHealth{
-			Block:     d.Block,
-			DeviceID:  d.DeviceID,
-			StartBL:   d.StartBL,
-			EndBL:     d.EndBL,
-			StartTime: d.StartTime.Format("2006-01-02 15:04:05"),
-			EndTime:   d.EndTime.Format("2006-01-02 15:04:05"),
-			Charging:  map[int]string{0: "Unknown", 1: "Charging", 2: "Discharging"}[d.CS],
-			IsAnomaly: map[int]string{0: "No", 1: "Yes"}[d.IsAnomaly],
-		}
-		jsonData = append(jsonData, jsonD)
-	}
-	return jsonData, nil
-}
-
-// GetAtRiskKPIs fetches at-risk KPIs and devices.
-func (s *Service) GetAtRiskKPIs(deviceIDStr, searchTerm string) (jsonmodel.AtRiskResponse, error) {
-	totalDevices, devices, err := s.Repo.GetAtRiskKPIs(deviceIDStr, searchTerm)
+	data, total, err := s.Repo.GetDeviceHealthData(filter)
 	if err != nil {
-		log.WriteLog.Error("Failed to fetch at-risk KPIs", zap.Error(err))
-		return jsonmodel.AtRiskResponse{}, fmt.Errorf("500:could not fetch at-risk KPIs: %w", err)
+		log.WriteLog.Error("Failed to fetch device health data", zap.Error(err))
+		return jsonmodel.DeviceHealthResponse{}, fmt.Errorf("500:could not fetch device health data: %w", err)
 	}
 
-	var jsonDevices []jsonmodel.AtRiskDevice
-	for _, d := range devices {
-		jsonDevices = append(jsonDevices, jsonmodel.AtRiskDevice{
-			DeviceID: d.DeviceID,
-			DeviceBS: d.DeviceBS,
-		})
+	reasons, err := s.batteryReasonsByBlock(data)
+	if err != nil {
+		log.WriteLog.Error("Failed to fetch battery anomaly reasons", zap.Error(err))
+		return jsonmodel.DeviceHealthResponse{}, fmt.Errorf("500:could not fetch battery anomaly reasons: %w", err)
 	}
 
-	riskCount := len(devices)
-	percent := 0.0
-	if totalDevices > 0 {
-		percent = (float64(riskCount) / float64(totalDevices)) * 100
+	jsonData := make([]jsonmodel.DeviceHealth, 0, len(data))
+	for _, d := range data {
+		jsonD := jsonmodel.DeviceHealth{
+			Block:         d.Block,
+			DeviceID:      d.DeviceID,
+			StartBL:       d.StartBL,
+			EndBL:         d.EndBL,
+			StartTime:     d.StartTime.Format("2006-01-02 15:04:05"),
+			EndTime:       d.EndTime.Format("2006-01-02 15:04:05"),
+			Charging:      map[int]string{0: "Unknown", 1: "Charging", 2: "Discharging"}[d.CS],
+			IsAnomaly:     map[int]string{0: "No", 1: "Yes"}[d.IsAnomaly],
+			AnomalyReason: d.AnomalyReason,
+			Reasons:       reasons[[2]int64{int64(d.DeviceID), int64(d.Block)}],
+		}
+		if jsonD.Reasons == nil {
+			jsonD.Reasons = []jsonmodel.BatteryReason{}
+		}
+		jsonData = append(jsonData, jsonD)
 	}
-
-	return jsonmodel.AtRiskResponse{
-		KPI: jsonmodel.AtRiskKPI{
-			TotalDevices:  totalDevices,
-			AtRisk:        riskCount,
-			AtRiskPercent: percent,
-		},
-		Devices: jsonDevices,
+	return jsonmodel.DeviceHealthResponse{
+		BatteryHealth: jsonData,
+		Total:         total,
+		Page:          filter.Page,
+		PageSize:      filter.PageSize,
 	}, nil
 }
@@ -0,0 +1,167 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"anomaly-go/log"
+	jsonmodel "anomaly-go/model/json"
+	model "anomaly-go/model/postgres"
+	"anomaly-go/service/battery"
+
+	"go.uber.org/zap"
+)
+
+// batteryAnomalyBatch is the number of blocks checked per round of a detection run.
+const batteryAnomalyBatch = 5000
+
+// DetectBatteryAnomalies checks every battery_health block not evaluated yet for
+// abnormally fast discharge, charging without gain, level jumps and impossible
+// levels, and records the reasons of each flagged block. A run already in progress
+// makes it return immediately.
+func (s *Service) DetectBatteryAnomalies() error {
+	if !s.batteryAnomalyMu.TryLock() {
+		return nil
+	}
+	defer s.batteryAnomalyMu.Unlock()
+
+	var fleet battery.Fleet
+	fleetLoaded := false
+	evaluated, flagged := 0, 0
+	for {
+		rows, err := s.Repo.GetUnevaluatedBatteryBlocks(batteryAnomalyBatch)
+		if err != nil {
+			return fmt.Errorf("500:could not fetch unevaluated battery blocks: %w", err)
+		}
+		if len(rows) == 0 {
+			break
+		}
+		if !fleetLoaded {
+			if fleet, err = s.batteryFleet(); err != nil {
+				return err
+			}
+			fleetLoaded = true
+		}
+
+		now := time.Now()
+		evals := make([]model.BatteryEvaluation, 0, len(rows))
+		var prev *battery.Block
+		for i, r := range rows {
+			if i == 0 || rows[i-1].DeviceID != r.DeviceID {
+				prev = nil
+				p, found, err := s.Repo.GetPreviousBatteryBlock(r.DeviceID, r.StartTime)
+				if err != nil {
+					return fmt.Errorf("500:could not fetch previous battery block: %w", err)
+				}
+				if found {
+					b := toBatteryBlock(p)
+					prev = &b
+				}
+			}
+
+			b := toBatteryBlock(r)
+			eval := model.BatteryEvaluation{DeviceID: r.DeviceID, Block: r.Block}
+			for _, f := range battery.Check(b, prev, fleet) {
+				eval.Reasons = append(eval.Reasons, model.BatteryAnomalyReason{
+					DeviceID:   r.DeviceID,
+					Block:      r.Block,
+					Code:       f.Code,
+					Message:    f.Message,
+					Value:      f.Value,
+					Threshold:  f.Threshold,
+					DetectedAt: now,
+				})
+			}
+			if len(eval.Reasons) > 0 {
+				flagged++
+			}
+			evals = append(evals, eval)
+			prev = &b
+		}
+
+		if err := s.Repo.SaveBatteryEvaluations(evals, now); err != nil {
+			return fmt.Errorf("500:could not save battery anomalies: %w", err)
+		}
+		evaluated += len(evals)
+		if len(rows) < batteryAnomalyBatch {
+			break
+		}
+	}
+
+	if evaluated > 0 {
+		log.WriteLog.Info("Battery blocks checked for anomalies", zap.Int("evaluated", evaluated), zap.Int("flagged", flagged))
+	}
+	return nil
+}
+
+// batteryFleet computes the fleet reference rates over the battery scoring window.
+func (s *Service) batteryFleet() (battery.Fleet, error) {
+	rows, err := s.Repo.GetBatteryBlocksSince(time.Now().Add(-s.Config.AnalyticsConfig.BatteryScoreWindow))
+	if err != nil {
+		return battery.Fleet{}, fmt.Errorf("500:could not fetch battery blocks: %w", err)
+	}
+	devices := map[int64][]battery.Block{}
+	for _, r := range rows {
+		devices[r.DeviceID] = append(devices[r.DeviceID], toBatteryBlock(r))
+	}
+	return battery.Reference(devices), nil
+}
+
+// ValidateReevaluateBatteryRequest checks the range of a re-evaluation request.
+func (s *Service) ValidateReevaluateBatteryRequest(req jsonmodel.ReevaluateBatteryRequest) error {
+	from, to, err := parseTimeRange(req.From, req.To)
+	if err != nil {
+		return err
+	}
+	if from.Valid && to.Valid && !from.Time.Before(to.Time) {
+		return fmt.Errorf("'from' must be before 'to'")
+	}
+	return nil
+}
+
+// ReevaluateBatteryAnomalies queues the matching blocks for a new anomaly check and
+// starts a detection run in the background. It returns the number of blocks queued.
+func (s *Service) ReevaluateBatteryAnomalies(req jsonmodel.ReevaluateBatteryRequest) (int64, error) {
+	from, to, err := parseTimeRange(req.From, req.To)
+	if err != nil {
+		return 0, err
+	}
+	queued, err := s.Repo.ResetBatteryEvaluations(req.DeviceID, from, to)
+	if err != nil {
+		log.WriteLog.Error("Failed to queue battery blocks", zap.Error(err))
+		return 0, fmt.Errorf("500:could not queue battery blocks: %w", err)
+	}
+
+	go func() {
+		if err := s.DetectBatteryAnomalies(); err != nil {
+			log.WriteLog.Error("Battery anomaly re-evaluation failed", zap.Error(err))
+		}
+	}()
+	return queued, nil
+}
+
+// batteryReasonsByBlock fetches the anomaly reasons of the given blocks, keyed by
+// device and block.
+func (s *Service) batteryReasonsByBlock(data []model.DeviceHealth) (map[[2]int64][]jsonmodel.BatteryReason, error) {
+	var keys [][]interface{}
+	for _, d := range data {
+		if d.IsAnomaly == 1 {
+			keys = append(keys, []interface{}{d.DeviceID, d.Block})
+		}
+	}
+	reasons, err := s.Repo.GetBatteryAnomalyReasons(keys)
+	if err != nil {
+		return nil, err
+	}
+	byBlock := make(map[[2]int64][]jsonmodel.BatteryReason)
+	for _, r := range reasons {
+		key := [2]int64{r.DeviceID, int64(r.Block)}
+		byBlock[key] = append(byBlock[key], jsonmodel.BatteryReason{
+			Code:      r.Code,
+			Message:   r.Message,
+			Value:     r.Value,
+			Threshold: r.Threshold,
+		})
+	}
+	return byBlock, nil
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"anomaly-go/log"
+	jsonmodel "anomaly-go/model/json"
+	model "anomaly-go/model/postgres"
+	"anomaly-go/service/battery"
+
+	"go.uber.org/zap"
+)
+
+// battery_health charging statuses.
+const (
+	batteryCharging    = 1
+	batteryDischarging = 2
+)
+
+// ErrNoAtRiskTier is returned when no bounded at-risk tier defines the score to
+// forecast against.
+var ErrNoAtRiskTier = errors.New("no at-risk battery tier with a max_score is configured")
+
+// ForecastBatteries projects, from the discharge blocks started within timeFilter,
+// when each device will cross the highest at-risk tier boundary. A deviceID of 0
+// forecasts every device. Devices already at risk come first, then the earliest
+// forecast crossings.
+func (s *Service) ForecastBatteries(deviceID int64, timeFilter string) (jsonmodel.BatteryForecastResponse, bool, error) {
+	tiers, _, err := s.loadRiskTiers()
+	if err != nil {
+		return jsonmodel.BatteryForecastResponse{}, false, err
+	}
+	atRiskScore, ok := battery.AtRiskScore(tiers)
+	if !ok {
+		return jsonmodel.BatteryForecastResponse{}, false, ErrNoAtRiskTier
+	}
+
+	rows, err := s.Repo.GetBatteryBlocks(deviceID, batteryDischarging, timeFilter)
+	if err != nil {
+		log.WriteLog.Error("Failed to fetch discharge blocks", zap.Error(err))
+		return jsonmodel.BatteryForecastResponse{}, false, fmt.Errorf("500:could not fetch discharge blocks: %w", err)
+	}
+	scores, err := s.Repo.GetBatteryScores()
+	if err != nil {
+		log.WriteLog.Error("Failed to fetch battery scores", zap.Error(err))
+		return jsonmodel.BatteryForecastResponse{}, false, fmt.Errorf("500:could not fetch battery scores: %w", err)
+	}
+	batteryScores := make(map[int64]model.BlScore, len(scores))
+	for _, sc := range scores {
+		batteryScores[int64(sc.DeviceID)] = sc
+	}
+
+	blocks := map[int64][]battery.Block{}
+	var deviceIDs []int64
+	for _, r := range rows {
+		if _, seen := blocks[r.DeviceID]; !seen {
+			deviceIDs = append(deviceIDs, r.DeviceID)
+		}
+		blocks[r.DeviceID] = append(blocks[r.DeviceID], toBatteryBlock(r))
+	}
+	if deviceID != 0 && len(deviceIDs) == 0 {
+		return jsonmodel.BatteryForecastResponse{}, false, nil
+	}
+
+	fleet, err := s.batteryFleet()
+	if err != nil {
+		log.WriteLog.Error("Failed to compute the fleet battery reference", zap.Error(err))
+		return jsonmodel.BatteryForecastResponse{}, false, err
+	}
+
+	now := time.Now()
+	resp := jsonmodel.BatteryForecastResponse{
+		AtRiskScore: atRiskScore,
+		Forecasts:   make([]jsonmodel.BatteryForecast, 0, len(deviceIDs)),
+	}
+	for _, id := range deviceIDs {
+		score, scored := batteryScores[id]
+		f := battery.Forecast{Status: battery.ForecastUnscored}
+		if scored {
+			f = battery.ForecastCrossing(blocks[id], score.DeviceBS, fleet, atRiskScore, now)
+		}
+		j := toBatteryForecastJSON(id, f)
+		if scored {
+			j.CurrentScore = &score.DeviceBS
+			j.ScoreComputedAt, j.ScoreAgeHours = scoreAge(score.ComputedAt, now)
+		}
+		resp.Forecasts = append(resp.Forecasts, j)
+	}
+
+	sort.SliceStable(resp.Forecasts, func(a, b int) bool {
+		fa, fb := resp.Forecasts[a], resp.Forecasts[b]
+		if ra, rb := forecastRank(fa.Status), forecastRank(fb.Status); ra != rb {
+			return ra < rb
+		}
+		return fa.EstimatedDate != nil && fb.EstimatedDate != nil && *fa.EstimatedDate < *fb.EstimatedDate
+	})
+	return resp, true, nil
+}
+
+// forecastRank orders forecasts by urgency.
+func forecastRank(status string) int {
+	switch status {
+	case battery.ForecastAtRisk:
+		return 0
+	case battery.ForecastDegrading:
+		return 1
+	case battery.ForecastStable:
+		return 2
+	default:
+		return 3
+	}
+}
+
+// toBatteryBlock converts a battery_health row, reading its naive timestamps on the
+// local clock.
+func toBatteryBlock(r model.BatteryBlock) battery.Block {
+	return battery.Block{
+		Number:      r.Block,
+		Start:       wallClock(r.StartTime),
+		End:         wallClock(r.EndTime),
+		StartLevel:  r.StartBL,
+		EndLevel:    r.EndBL,
+		Charging:    r.CS == batteryCharging,
+		Discharging: r.CS == batteryDischarging,
+		Anomaly:     r.IsAnomaly == 1,
+	}
+}
+
+func toBatteryForecastJSON(deviceID int64, f battery.Forecast) jsonmodel.BatteryForecast {
+	j := jsonmodel.BatteryForecast{
+		DeviceID:             deviceID,
+		Status:               f.Status,
+		DischargeBlocks:      f.Blocks,
+		CurrentDischargeRate: f.CurrentRate,
+		RateSlopePerDay:      f.SlopePerDay,
+	}
+	j.EstimatedDate = formatDate(f.Estimate)
+	j.EarliestDate = formatDate(f.Lower)
+	j.LatestDate = formatDate(f.Upper)
+	return j
+}
+
+func formatDate(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	s := t.Format("2006-01-02")
+	return &s
+}
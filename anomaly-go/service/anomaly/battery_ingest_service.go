@@ -0,0 +1,192 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"anomaly-go/log"
+	jsonmodel "anomaly-go/model/json"
+	model "anomaly-go/model/postgres"
+
+	"go.uber.org/zap"
+)
+
+// Battery ingest result statuses.
+const (
+	BatteryIngestStatusCreated = "created"
+	BatteryIngestStatusUpdated = "updated"
+	BatteryIngestStatusInvalid = "invalid"
+)
+
+// ErrBatteryIngestUnavailable is returned when battery_health has no unique index on
+// (device_id, block) for ingested blocks to be upserted on. Schema bootstrap skips
+// the index while duplicate blocks are stored.
+var ErrBatteryIngestUnavailable = errors.New("battery ingest is unavailable: battery_health has no unique index on (device_id, block); remove the duplicate blocks and restart the service")
+
+// IngestBatteryBlocks validates a batch of battery blocks of one device and upserts
+// the valid ones into battery_health on (device_id, block). Blocks must not overlap
+// the blocks numbered around them, in the batch or already stored, and a block
+// number repeated in the batch is rejected. Stored blocks are queued for the battery anomaly detector, which is
+// started right away.
+func (s *Service) IngestBatteryBlocks(req jsonmodel.IngestBatteryBlocksRequest) (jsonmodel.IngestBatteryBlocksResponse, error) {
+	resp := jsonmodel.IngestBatteryBlocksResponse{
+		DeviceID: req.DeviceID,
+		Results:  make([]jsonmodel.IngestBatteryResult, len(req.Blocks)),
+	}
+
+	type pending struct {
+		index int
+		block model.BatteryBlock
+	}
+	var valid []pending
+	seen := make(map[int]bool, len(req.Blocks))
+	for i, b := range req.Blocks {
+		resp.Results[i] = jsonmodel.IngestBatteryResult{Block: b.Block}
+		block, err := parseIngestBatteryBlock(req.DeviceID, b)
+		if err == nil && seen[b.Block] {
+			err = fmt.Errorf("block %d appears more than once in the batch", b.Block)
+		}
+		if err != nil {
+			resp.Results[i].Status = BatteryIngestStatusInvalid
+			resp.Results[i].Error = err.Error()
+			resp.Invalid++
+			continue
+		}
+		seen[b.Block] = true
+		valid = append(valid, pending{index: i, block: block})
+	}
+
+	if len(valid) == 0 {
+		return resp, nil
+	}
+	sort.SliceStable(valid, func(i, j int) bool { return valid[i].block.Block < valid[j].block.Block })
+
+	// Stored blocks the batch replaces are left out of the neighbours.
+	stored, err := s.Repo.GetBatteryBlocksAround(req.DeviceID, valid[0].block.Block, valid[len(valid)-1].block.Block)
+	if err != nil {
+		log.WriteLog.Error("Failed to fetch stored battery blocks", zap.Int64("device_id", req.DeviceID), zap.Error(err))
+		return jsonmodel.IngestBatteryBlocksResponse{}, fmt.Errorf("500:could not fetch stored battery blocks: %w", err)
+	}
+	var neighbours []model.BatteryBlock
+	for _, b := range stored {
+		if !seen[b.Block] {
+			neighbours = append(neighbours, b)
+		}
+	}
+
+	// Blocks are numbered in time order, so each must start after the block before it
+	// ended and end before the block after it starts.
+	var ordered []pending
+	var prev *model.BatteryBlock
+	next := 0
+	for i := range valid {
+		p := &valid[i]
+		for next < len(neighbours) && neighbours[next].Block < p.block.Block {
+			prev = &neighbours[next]
+			next++
+		}
+		res := &resp.Results[p.index]
+		switch {
+		case prev != nil && p.block.StartTime.Before(prev.EndTime):
+			res.Error = fmt.Sprintf("start_time is before the end of block %d", prev.Block)
+		case next < len(neighbours) && p.block.EndTime.After(neighbours[next].StartTime):
+			res.Error = fmt.Sprintf("end_time is after the start of block %d", neighbours[next].Block)
+		default:
+			ordered = append(ordered, *p)
+			prev = &p.block
+			continue
+		}
+		res.Status = BatteryIngestStatusInvalid
+		resp.Invalid++
+	}
+
+	if len(ordered) == 0 {
+		return resp, nil
+	}
+
+	keyed, err := s.Repo.HasBatteryBlockKey()
+	if err != nil {
+		log.WriteLog.Error("Failed to check the battery_health block key", zap.Error(err))
+		return jsonmodel.IngestBatteryBlocksResponse{}, fmt.Errorf("500:could not check the battery_health block key: %w", err)
+	}
+	if !keyed {
+		return jsonmodel.IngestBatteryBlocksResponse{}, ErrBatteryIngestUnavailable
+	}
+
+	blocks := make([]model.BatteryBlock, 0, len(ordered))
+	for _, p := range ordered {
+		blocks = append(blocks, p.block)
+	}
+	existing, err := s.Repo.UpsertBatteryBlocks(req.DeviceID, blocks)
+	if err != nil {
+		log.WriteLog.Error("Failed to store battery blocks", zap.Int64("device_id", req.DeviceID), zap.Error(err))
+		return jsonmodel.IngestBatteryBlocksResponse{}, fmt.Errorf("500:could not store battery blocks: %w", err)
+	}
+	for _, p := range ordered {
+		res := &resp.Results[p.index]
+		if existing[p.block.Block] {
+			res.Status = BatteryIngestStatusUpdated
+			resp.Updated++
+		} else {
+			res.Status = BatteryIngestStatusCreated
+			resp.Created++
+		}
+	}
+
+	go func() {
+		if err := s.DetectBatteryAnomalies(); err != nil {
+			log.WriteLog.Error("Battery anomaly detection after ingest failed", zap.Error(err))
+		}
+	}()
+
+	log.WriteLog.Info("Ingested battery blocks",
+		zap.Int64("device_id", req.DeviceID),
+		zap.Int("created", resp.Created),
+		zap.Int("updated", resp.Updated),
+		zap.Int("invalid", resp.Invalid),
+	)
+	return resp, nil
+}
+
+// parseIngestBatteryBlock validates one ingested battery block.
+func parseIngestBatteryBlock(deviceID int64, b jsonmodel.IngestBatteryBlock) (model.BatteryBlock, error) {
+	if b.Block < 0 {
+		return model.BatteryBlock{}, fmt.Errorf("block must be a non-negative integer")
+	}
+	if b.CS != 0 && b.CS != batteryCharging && b.CS != batteryDischarging {
+		return model.BatteryBlock{}, fmt.Errorf("cs must be 0 (unknown), 1 (charging) or 2 (discharging)")
+	}
+	if !validBatteryLevel(b.StartBL) {
+		return model.BatteryBlock{}, fmt.Errorf("start_bl must be between 0 and 100")
+	}
+	if !validBatteryLevel(b.EndBL) {
+		return model.BatteryBlock{}, fmt.Errorf("end_bl must be between 0 and 100")
+	}
+	start, err := parseTimestamp(b.StartTime)
+	if err != nil {
+		return model.BatteryBlock{}, fmt.Errorf("start_time must be 'YYYY-MM-DD HH:MM:SS' or RFC3339")
+	}
+	end, err := parseTimestamp(b.EndTime)
+	if err != nil {
+		return model.BatteryBlock{}, fmt.Errorf("end_time must be 'YYYY-MM-DD HH:MM:SS' or RFC3339")
+	}
+	if !end.After(start) {
+		return model.BatteryBlock{}, fmt.Errorf("end_time must be after start_time")
+	}
+
+	return model.BatteryBlock{
+		DeviceID:  deviceID,
+		Block:     b.Block,
+		CS:        b.CS,
+		StartTime: start,
+		EndTime:   end,
+		StartBL:   b.StartBL,
+		EndBL:     b.EndBL,
+	}, nil
+}
+
+func validBatteryLevel(level float64) bool {
+	return !math.IsNaN(level) && level >= 0 && level <= 100
+}
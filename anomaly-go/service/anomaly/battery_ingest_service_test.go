@@ -0,0 +1,80 @@
+package service
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	jsonmodel "anomaly-go/model/json"
+)
+
+func TestParseIngestBatteryBlock(t *testing.T) {
+	valid := jsonmodel.IngestBatteryBlock{Block: 3, CS: 2, StartTime: "2026-03-02 09:00:00", EndTime: "2026-03-02 10:00:00", StartBL: 80, EndBL: 72.5}
+	with := func(change func(*jsonmodel.IngestBatteryBlock)) jsonmodel.IngestBatteryBlock {
+		b := valid
+		change(&b)
+		return b
+	}
+	tests := []struct {
+		name    string
+		req     jsonmodel.IngestBatteryBlock
+		wantErr string
+	}{
+		{"valid", valid, ""},
+		{"unknown charging status", with(func(b *jsonmodel.IngestBatteryBlock) { b.CS = 0 }), ""},
+		{"charging", with(func(b *jsonmodel.IngestBatteryBlock) { b.CS, b.EndBL = 1, 95 }), ""},
+		{"RFC3339 times", with(func(b *jsonmodel.IngestBatteryBlock) {
+			b.StartTime, b.EndTime = "2026-03-02T09:00:00Z", "2026-03-02T10:00:00Z"
+		}), ""},
+		{"negative block", with(func(b *jsonmodel.IngestBatteryBlock) { b.Block = -1 }), "block must be a non-negative integer"},
+		{"bad charging status", with(func(b *jsonmodel.IngestBatteryBlock) { b.CS = 3 }), "cs must be 0 (unknown), 1 (charging) or 2 (discharging)"},
+		{"start level above 100", with(func(b *jsonmodel.IngestBatteryBlock) { b.StartBL = 100.5 }), "start_bl must be between 0 and 100"},
+		{"negative end level", with(func(b *jsonmodel.IngestBatteryBlock) { b.EndBL = -1 }), "end_bl must be between 0 and 100"},
+		{"bad start time", with(func(b *jsonmodel.IngestBatteryBlock) { b.StartTime = "02/03/2026 09:00" }), "start_time must be 'YYYY-MM-DD HH:MM:SS' or RFC3339"},
+		{"missing end time", with(func(b *jsonmodel.IngestBatteryBlock) { b.EndTime = "" }), "end_time must be 'YYYY-MM-DD HH:MM:SS' or RFC3339"},
+		{"empty block", with(func(b *jsonmodel.IngestBatteryBlock) { b.EndTime = b.StartTime }), "end_time must be after start_time"},
+		{"reversed times", with(func(b *jsonmodel.IngestBatteryBlock) {
+			b.StartTime, b.EndTime = b.EndTime, b.StartTime
+		}), "end_time must be after start_time"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseIngestBatteryBlock(7, tt.req)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("parseIngestBatteryBlock() error = %v; want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseIngestBatteryBlock() error = %v", err)
+			}
+			if got.DeviceID != 7 || got.Block != tt.req.Block || got.CS != tt.req.CS || got.StartBL != tt.req.StartBL || got.EndBL != tt.req.EndBL {
+				t.Errorf("parseIngestBatteryBlock() = %+v; want the fields of %+v on device 7", got, tt.req)
+			}
+			if d := got.EndTime.Sub(got.StartTime); d != time.Hour {
+				t.Errorf("parseIngestBatteryBlock() lasts %v; want 1h", d)
+			}
+		})
+	}
+}
+
+func TestValidBatteryLevel(t *testing.T) {
+	tests := []struct {
+		level float64
+		want  bool
+	}{
+		{0, true},
+		{55.5, true},
+		{100, true},
+		{-0.1, false},
+		{100.1, false},
+		{math.NaN(), false},
+		{math.Inf(1), false},
+	}
+	for _, tt := range tests {
+		if got := validBatteryLevel(tt.level); got != tt.want {
+			t.Errorf("validBatteryLevel(%v) = %v; want %v", tt.level, got, tt.want)
+		}
+	}
+}
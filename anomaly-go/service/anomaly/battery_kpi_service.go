@@ -0,0 +1,178 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"anomaly-go/log"
+	jsonmodel "anomaly-go/model/json"
+	model "anomaly-go/model/postgres"
+	"anomaly-go/service/battery"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// batteryKPICheckInterval is how often the snapshot job checks whether a new
+	// snapshot is due, so restarts do not postpone snapshots indefinitely.
+	batteryKPICheckInterval = time.Hour
+	// defaultKPITrendDays is the range of the KPI trend without 'from'.
+	defaultKPITrendDays = 90
+)
+
+// ErrInvalidDateRange reports a bad 'from' or 'to' in a trend request.
+var ErrInvalidDateRange = errors.New("invalid date range")
+
+// SnapshotBatteryKPIs records the fleet battery KPIs, the device count of each risk
+// tier and the battery score distribution once the last snapshot is older than the
+// configured interval.
+func (s *Service) SnapshotBatteryKPIs() error {
+	now := time.Now()
+	latest, found, err := s.Repo.GetLatestBatteryKPISnapshotTime()
+	if err != nil {
+		return fmt.Errorf("500:could not fetch last battery KPI snapshot: %w", err)
+	}
+	if found && now.Sub(latest) < s.Config.AnalyticsConfig.BatteryKPIInterval {
+		return nil
+	}
+
+	current, err := s.GetAtRiskKPIs("", "", "all")
+	if err != nil {
+		return err
+	}
+	scores := make([]float64, 0, len(current.Devices))
+	for _, d := range current.Devices {
+		scores = append(scores, d.DeviceBS)
+	}
+	dist := battery.Distribute(scores)
+
+	snapshot := model.BatteryKPISnapshot{
+		TakenAt:       now,
+		TotalDevices:  current.KPI.TotalDevices,
+		ScoredDevices: len(scores),
+		AtRisk:        current.KPI.AtRisk,
+		AtRiskPercent: current.KPI.AtRiskPercent,
+		ScoreMean:     dist.Mean,
+		ScoreMin:      dist.Min,
+		ScoreP10:      dist.P10,
+		ScoreP25:      dist.P25,
+		ScoreP50:      dist.P50,
+		ScoreP75:      dist.P75,
+		ScoreP90:      dist.P90,
+		ScoreMax:      dist.Max,
+	}
+	tiers := make([]model.BatteryKPITier, 0, len(current.KPI.Tiers))
+	for _, t := range current.KPI.Tiers {
+		tier := model.BatteryKPITier{
+			Name:        t.Name,
+			DisplayName: t.DisplayName,
+			AtRisk:      t.AtRisk,
+			Color:       t.Color,
+			Devices:     t.Count,
+			Percent:     t.Percent,
+		}
+		if t.MaxScore != nil {
+			tier.MaxScore = sql.NullFloat64{Float64: *t.MaxScore, Valid: true}
+		}
+		tiers = append(tiers, tier)
+	}
+
+	if err := s.Repo.SaveBatteryKPISnapshot(&snapshot, tiers); err != nil {
+		return fmt.Errorf("500:could not save battery KPI snapshot: %w", err)
+	}
+	log.WriteLog.Info("Battery KPI snapshot taken",
+		zap.Int("total_devices", snapshot.TotalDevices),
+		zap.Int("at_risk", snapshot.AtRisk),
+		zap.Float64("score_p50", snapshot.ScoreP50),
+	)
+	return nil
+}
+
+// GetBatteryKPITrend returns the battery KPI snapshots taken between from and to,
+// oldest first. Both accept a date or a timestamp; a date 'to' includes that day.
+// Without them the range is the last defaultKPITrendDays days.
+func (s *Service) GetBatteryKPITrend(fromStr, toStr string) (jsonmodel.BatteryKPITrendResponse, error) {
+	to := time.Now()
+	if toStr != "" {
+		t, err := parseDateBound(toStr, true)
+		if err != nil {
+			return jsonmodel.BatteryKPITrendResponse{}, fmt.Errorf("%w: 'to' must be 'YYYY-MM-DD', 'YYYY-MM-DD HH:MM:SS' or RFC3339", ErrInvalidDateRange)
+		}
+		to = t
+	}
+	from := to.AddDate(0, 0, -defaultKPITrendDays)
+	if fromStr != "" {
+		t, err := parseDateBound(fromStr, false)
+		if err != nil {
+			return jsonmodel.BatteryKPITrendResponse{}, fmt.Errorf("%w: 'from' must be 'YYYY-MM-DD', 'YYYY-MM-DD HH:MM:SS' or RFC3339", ErrInvalidDateRange)
+		}
+		from = t
+	}
+	if !from.Before(to) {
+		return jsonmodel.BatteryKPITrendResponse{}, fmt.Errorf("%w: 'from' must be before 'to'", ErrInvalidDateRange)
+	}
+
+	snapshots, tiers, err := s.Repo.GetBatteryKPISnapshots(sql.NullTime{Time: from, Valid: true}, sql.NullTime{Time: to, Valid: true})
+	if err != nil {
+		log.WriteLog.Error("Failed to fetch battery KPI snapshots", zap.Error(err))
+		return jsonmodel.BatteryKPITrendResponse{}, fmt.Errorf("500:could not fetch battery KPI snapshots: %w", err)
+	}
+
+	resp := jsonmodel.BatteryKPITrendResponse{
+		From:      from.Format("2006-01-02 15:04:05"),
+		To:        to.Format("2006-01-02 15:04:05"),
+		Snapshots: make([]jsonmodel.BatteryKPISnapshot, 0, len(snapshots)),
+	}
+	for _, snap := range snapshots {
+		resp.Snapshots = append(resp.Snapshots, toBatteryKPISnapshotJSON(snap, tiers[snap.ID]))
+	}
+	return resp, nil
+}
+
+// parseDateBound parses a date or a timestamp. A date is read as the start of the
+// day, or as the start of the next day when it ends a range.
+func parseDateBound(value string, end bool) (time.Time, error) {
+	if day, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		if end {
+			return day.AddDate(0, 0, 1), nil
+		}
+		return day, nil
+	}
+	return parseTimestamp(value)
+}
+
+func toBatteryKPISnapshotJSON(snap model.BatteryKPISnapshot, tiers []model.BatteryKPITier) jsonmodel.BatteryKPISnapshot {
+	j := jsonmodel.BatteryKPISnapshot{
+		TakenAt:       snap.TakenAt.Format("2006-01-02 15:04:05"),
+		TotalDevices:  snap.TotalDevices,
+		ScoredDevices: snap.ScoredDevices,
+		AtRisk:        snap.AtRisk,
+		AtRiskPercent: snap.AtRiskPercent,
+		Score: jsonmodel.Distribution{
+			Devices: snap.ScoredDevices,
+			Mean:    round2(snap.ScoreMean),
+			Min:     round2(snap.ScoreMin),
+			P10:     round2(snap.ScoreP10),
+			P25:     round2(snap.ScoreP25),
+			P50:     round2(snap.ScoreP50),
+			P75:     round2(snap.ScoreP75),
+			P90:     round2(snap.ScoreP90),
+			Max:     round2(snap.ScoreMax),
+		},
+		Tiers: make([]jsonmodel.RiskTierCount, 0, len(tiers)),
+	}
+	for _, t := range tiers {
+		j.Tiers = append(j.Tiers, jsonmodel.RiskTierCount{
+			Name:        t.Name,
+			DisplayName: t.DisplayName,
+			MaxScore:    nullFloatPtr(t.MaxScore),
+			AtRisk:      t.AtRisk,
+			Color:       t.Color,
+			Count:       t.Devices,
+			Percent:     t.Percent,
+		})
+	}
+	return j
+}
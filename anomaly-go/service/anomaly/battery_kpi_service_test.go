@@ -0,0 +1,100 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	model "anomaly-go/model/postgres"
+)
+
+func TestParseDateBound(t *testing.T) {
+	day := time.Date(2026, 3, 2, 0, 0, 0, 0, time.Local)
+	tests := []struct {
+		name    string
+		value   string
+		end     bool
+		want    time.Time
+		wantErr bool
+	}{
+		{"date starting a range", "2026-03-02", false, day, false},
+		{"date ending a range", "2026-03-02", true, day.AddDate(0, 0, 1), false},
+		{"timestamp ending a range", "2026-03-02 15:30:00", true, day.Add(15*time.Hour + 30*time.Minute), false},
+		{"RFC3339", "2026-03-02T10:00:00Z", false, time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC), false},
+		{"invalid", "March 2nd", false, time.Time{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseDateBound(tt.value, tt.end)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseDateBound(%q, %v) error = %v; wantErr %v", tt.value, tt.end, err, tt.wantErr)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("parseDateBound(%q, %v) = %v; want %v", tt.value, tt.end, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetBatteryKPITrendInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to string
+	}{
+		{"bad from", "last month", ""},
+		{"bad to", "", "today"},
+		{"reversed dates", "2026-03-03", "2026-03-02"},
+		{"empty range", "2026-03-02 00:00:00", "2026-03-02 00:00:00"},
+	}
+
+	s := &Service{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := s.GetBatteryKPITrend(tt.from, tt.to); !errors.Is(err, ErrInvalidDateRange) {
+				t.Errorf("GetBatteryKPITrend(%q, %q) error = %v; want %v", tt.from, tt.to, err, ErrInvalidDateRange)
+			}
+		})
+	}
+}
+
+func TestToBatteryKPISnapshotJSON(t *testing.T) {
+	snap := model.BatteryKPISnapshot{
+		ID:            1,
+		TakenAt:       time.Date(2026, 3, 2, 6, 0, 0, 0, time.UTC),
+		TotalDevices:  120,
+		ScoredDevices: 100,
+		AtRisk:        12,
+		AtRiskPercent: 10,
+		ScoreMean:     71.236,
+		ScoreMin:      12.5,
+		ScoreP50:      74.999,
+		ScoreMax:      99.1,
+	}
+	tiers := []model.BatteryKPITier{
+		{SnapshotID: 1, Name: "critical", DisplayName: "Critical", MaxScore: sql.NullFloat64{Float64: 40, Valid: true}, AtRisk: true, Devices: 12, Percent: 12},
+		{SnapshotID: 1, Name: "healthy", DisplayName: "Healthy", Devices: 88, Percent: 88},
+	}
+
+	got := toBatteryKPISnapshotJSON(snap, tiers)
+
+	if got.TakenAt != "2026-03-02 06:00:00" || got.TotalDevices != 120 || got.AtRisk != 12 {
+		t.Errorf("toBatteryKPISnapshotJSON() = %+v; want the snapshot KPIs", got)
+	}
+	if got.Score.Devices != 100 || got.Score.Mean != 71.24 || got.Score.P50 != 75 || got.Score.Max != 99.1 {
+		t.Errorf("toBatteryKPISnapshotJSON() score = %+v; want 100 devices with rounded figures", got.Score)
+	}
+	if len(got.Tiers) != 2 {
+		t.Fatalf("toBatteryKPISnapshotJSON() tiers = %+v; want 2", got.Tiers)
+	}
+	if critical := got.Tiers[0]; critical.MaxScore == nil || *critical.MaxScore != 40 || !critical.AtRisk || critical.Count != 12 {
+		t.Errorf("critical tier = %+v; want up to 40, at risk, 12 devices", critical)
+	}
+	if healthy := got.Tiers[1]; healthy.MaxScore != nil || healthy.AtRisk || healthy.Count != 88 {
+		t.Errorf("healthy tier = %+v; want unbounded, not at risk, 88 devices", healthy)
+	}
+
+	if empty := toBatteryKPISnapshotJSON(snap, nil); empty.Tiers == nil || len(empty.Tiers) != 0 {
+		t.Errorf("toBatteryKPISnapshotJSON() without tiers = %#v; want an empty list", empty.Tiers)
+	}
+}
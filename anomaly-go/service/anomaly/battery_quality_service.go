@@ -0,0 +1,154 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"anomaly-go/log"
+	jsonmodel "anomaly-go/model/json"
+	repo "anomaly-go/repository/postgres"
+	"anomaly-go/service/battery"
+
+	"go.uber.org/zap"
+)
+
+// worstQualityDevices is the number of least complete devices in the fleet report.
+const worstQualityDevices = 10
+
+// GetBatteryDataQuality checks the battery blocks started within the /fetchData time
+// filter for gaps, overlaps, duplicates, out-of-order blocks and level
+// discontinuities, for one device with its issues or for every device, least
+// complete first. It returns false when the device has no blocks in the range.
+func (s *Service) GetBatteryDataQuality(deviceID int64, timeFilter string) (jsonmodel.BatteryDataQualityResponse, bool, error) {
+	qualities, err := s.assessBatteryQuality(deviceID, timeFilter)
+	if err != nil {
+		return jsonmodel.BatteryDataQualityResponse{}, false, err
+	}
+	if deviceID != 0 && len(qualities) == 0 {
+		return jsonmodel.BatteryDataQualityResponse{}, false, nil
+	}
+
+	resp := jsonmodel.BatteryDataQualityResponse{Devices: make([]jsonmodel.BatteryDataQuality, 0, len(qualities))}
+	for _, q := range qualities {
+		resp.Devices = append(resp.Devices, toBatteryDataQualityJSON(q, deviceID != 0))
+	}
+	return resp, true, nil
+}
+
+// GetFleetDataQuality summarizes battery data quality over every device with blocks
+// started within the /fetchData time filter.
+func (s *Service) GetFleetDataQuality(timeFilter string) (jsonmodel.FleetDataQualityResponse, error) {
+	qualities, err := s.assessBatteryQuality(0, timeFilter)
+	if err != nil {
+		return jsonmodel.FleetDataQualityResponse{}, err
+	}
+
+	resp := jsonmodel.FleetDataQualityResponse{Devices: len(qualities), Worst: []jsonmodel.BatteryDataQuality{}}
+	completeness := make([]float64, 0, len(qualities))
+	for _, q := range qualities {
+		completeness = append(completeness, q.Completeness())
+		resp.Gaps += q.Gaps
+		resp.Overlaps += q.Overlaps
+		resp.Duplicates += q.Duplicates
+		resp.OutOfOrder += q.OutOfOrder
+		resp.Discontinuities += q.Discontinuities
+		if q.Gaps > 0 {
+			resp.DevicesWithGaps++
+		}
+		if q.Overlaps > 0 {
+			resp.DevicesWithOverlaps++
+		}
+		if q.Duplicates > 0 {
+			resp.DevicesWithDuplicates++
+		}
+		if q.OutOfOrder > 0 {
+			resp.DevicesOutOfOrder++
+		}
+		if q.Discontinuities > 0 {
+			resp.DevicesWithDiscontinuities++
+		}
+		if len(resp.Worst) < worstQualityDevices {
+			resp.Worst = append(resp.Worst, toBatteryDataQualityJSON(q, false))
+		}
+	}
+	resp.Completeness = toDistributionJSON(battery.Distribute(completeness))
+	return resp, nil
+}
+
+// deviceQuality is the data quality of the blocks of one device.
+type deviceQuality struct {
+	battery.Quality
+	DeviceID     int64
+	LastBlockEnd time.Time
+}
+
+// assessBatteryQuality assesses the blocks of one device, or of every device when
+// deviceID is 0, least complete first.
+func (s *Service) assessBatteryQuality(deviceID int64, timeFilter string) ([]deviceQuality, error) {
+	rows, err := s.Repo.GetBatteryBlocks(deviceID, 0, timeFilter)
+	if err != nil {
+		log.WriteLog.Error("Failed to fetch battery blocks", zap.Error(err))
+		return nil, fmt.Errorf("500:could not fetch battery blocks: %w", err)
+	}
+
+	var order []int64
+	devices := map[int64][]battery.Block{}
+	for _, r := range rows {
+		if _, ok := devices[r.DeviceID]; !ok {
+			order = append(order, r.DeviceID)
+		}
+		devices[r.DeviceID] = append(devices[r.DeviceID], toBatteryBlock(r))
+	}
+
+	// Completeness is measured from the start of the time filter, so a device silent
+	// at the start of the range is not reported complete.
+	now := time.Now()
+	from, _ := repo.TimeThreshold(timeFilter)
+	qualities := make([]deviceQuality, 0, len(order))
+	for _, id := range order {
+		blocks := devices[id]
+		q := deviceQuality{Quality: battery.AssessQuality(blocks, from, now), DeviceID: id}
+		for _, b := range blocks {
+			if b.End.After(q.LastBlockEnd) {
+				q.LastBlockEnd = b.End
+			}
+		}
+		qualities = append(qualities, q)
+	}
+	sort.SliceStable(qualities, func(i, j int) bool {
+		return qualities[i].Completeness() < qualities[j].Completeness()
+	})
+	return qualities, nil
+}
+
+func toBatteryDataQualityJSON(q deviceQuality, withIssues bool) jsonmodel.BatteryDataQuality {
+	j := jsonmodel.BatteryDataQuality{
+		DeviceID:        q.DeviceID,
+		Completeness:    round2(q.Completeness()),
+		Blocks:          q.Blocks,
+		SpanHours:       round2(q.Span.Hours()),
+		CoveredHours:    round2(q.Covered.Hours()),
+		Gaps:            q.Gaps,
+		GapHours:        round2(q.GapTime.Hours()),
+		LongestGapHours: round2(q.LongestGap.Hours()),
+		Overlaps:        q.Overlaps,
+		Duplicates:      q.Duplicates,
+		OutOfOrder:      q.OutOfOrder,
+		Discontinuities: q.Discontinuities,
+		LastBlockEnd:    q.LastBlockEnd.Format("2006-01-02 15:04:05"),
+	}
+	if withIssues {
+		j.Issues = make([]jsonmodel.BatteryDataIssue, 0, len(q.Issues))
+		for _, issue := range q.Issues {
+			j.Issues = append(j.Issues, jsonmodel.BatteryDataIssue{
+				Type:     issue.Type,
+				Block:    issue.Block,
+				Previous: issue.Previous,
+				At:       issue.At.Format("2006-01-02 15:04:05"),
+				Value:    round2(issue.Value),
+			})
+		}
+	}
+	return j
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"anomaly-go/log"
+	model "anomaly-go/model/postgres"
+	"anomaly-go/service/battery"
+
+	"go.uber.org/zap"
+)
+
+// ComputeBatteryScores scores every device from its battery_health blocks of the
+// configured window against the fleet reference, and writes the scores to bl_score
+// with the time they were computed. Devices with too few blocks keep their previous
+// score, whose age shows it is stale.
+func (s *Service) ComputeBatteryScores() error {
+	now := time.Now()
+	rows, err := s.Repo.GetBatteryBlocksSince(now.Add(-s.Config.AnalyticsConfig.BatteryScoreWindow))
+	if err != nil {
+		return fmt.Errorf("500:could not fetch battery blocks: %w", err)
+	}
+
+	devices := map[int64][]battery.Block{}
+	for _, r := range rows {
+		devices[r.DeviceID] = append(devices[r.DeviceID], toBatteryBlock(r))
+	}
+	fleet := battery.Reference(devices)
+
+	scores := make([]model.BlScore, 0, len(devices))
+	for id, blocks := range devices {
+		score, ok := battery.Compute(blocks, fleet)
+		if !ok {
+			continue
+		}
+		scores = append(scores, model.BlScore{
+			DeviceID:   int(id),
+			DeviceBS:   score.Value,
+			ComputedAt: sql.NullTime{Time: now, Valid: true},
+		})
+	}
+
+	if err := s.Repo.SaveBatteryScores(scores); err != nil {
+		return fmt.Errorf("500:could not save battery scores: %w", err)
+	}
+	log.WriteLog.Info("Battery scores computed",
+		zap.Int("scored", len(scores)),
+		zap.Int("skipped", len(devices)-len(scores)),
+		zap.Float64("fleet_discharge_rate", fleet.DischargeRate),
+		zap.Float64("fleet_charge_rate", fleet.ChargeRate),
+	)
+	return nil
+}
+
+// scoreAge returns when a score was computed and its age in hours, or nils for
+// scores without a computation time.
+func scoreAge(computedAt sql.NullTime, now time.Time) (*string, *float64) {
+	if !computedAt.Valid {
+		return nil, nil
+	}
+	at := computedAt.Time.Format("2006-01-02 15:04:05")
+	age := now.Sub(computedAt.Time).Hours()
+	return &at, &age
+}
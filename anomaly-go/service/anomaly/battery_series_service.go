@@ -0,0 +1,134 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"anomaly-go/log"
+	jsonmodel "anomaly-go/model/json"
+
+	"go.uber.org/zap"
+)
+
+// MaxSeriesPoints is the most points a battery time series returns. Requests that
+// would exceed it get a coarser resolution.
+const MaxSeriesPoints = 500
+
+// ErrInvalidTimeSeries reports a battery time series request with a bad range or
+// resolution.
+var ErrInvalidTimeSeries = errors.New("invalid time series request")
+
+// seriesResolutions are the supported bucket widths, finest first.
+var seriesResolutions = []struct {
+	name  string
+	width time.Duration
+}{
+	{"1m", time.Minute},
+	{"5m", 5 * time.Minute},
+	{"15m", 15 * time.Minute},
+	{"30m", 30 * time.Minute},
+	{"1h", time.Hour},
+	{"3h", 3 * time.Hour},
+	{"6h", 6 * time.Hour},
+	{"12h", 12 * time.Hour},
+	{"1d", 24 * time.Hour},
+	{"1w", 7 * 24 * time.Hour},
+}
+
+// GetBatteryTimeSeries downsamples the battery level of a device into buckets of the
+// requested resolution, over the blocks started between from and to (both optional,
+// the device's whole history by default). With resolution "auto", or when the
+// requested one would yield more than MaxSeriesPoints buckets, the finest resolution
+// that fits is used. It returns false when the device has no blocks in the range.
+func (s *Service) GetBatteryTimeSeries(deviceID int64, fromStr, toStr, resolution string) (jsonmodel.BatteryTimeSeriesResponse, bool, error) {
+	from, to, err := parseTimeRange(fromStr, toStr)
+	if err != nil {
+		return jsonmodel.BatteryTimeSeriesResponse{}, false, fmt.Errorf("%w: %s", ErrInvalidTimeSeries, err)
+	}
+	if from.Valid && to.Valid && !from.Time.Before(to.Time) {
+		return jsonmodel.BatteryTimeSeriesResponse{}, false, fmt.Errorf("%w: 'from' must be before 'to'", ErrInvalidTimeSeries)
+	}
+	requested := -1
+	if resolution != "" && resolution != "auto" {
+		for i, r := range seriesResolutions {
+			if r.name == resolution {
+				requested = i
+			}
+		}
+		if requested < 0 {
+			return jsonmodel.BatteryTimeSeriesResponse{}, false, fmt.Errorf("%w: 'resolution' must be auto, 1m, 5m, 15m, 30m, 1h, 3h, 6h, 12h, 1d or 1w", ErrInvalidTimeSeries)
+		}
+	}
+
+	span, err := s.Repo.GetBatteryTimeRange(deviceID, from, to)
+	if err != nil {
+		log.WriteLog.Error("Failed to fetch battery time range", zap.Error(err))
+		return jsonmodel.BatteryTimeSeriesResponse{}, false, fmt.Errorf("500:could not fetch battery time range: %w", err)
+	}
+	if span.Blocks == 0 {
+		return jsonmodel.BatteryTimeSeriesResponse{}, false, nil
+	}
+	start, end := span.First.Time, span.Last.Time
+	if from.Valid {
+		start = from.Time
+	}
+	if to.Valid {
+		end = to.Time
+	}
+
+	chosen := seriesResolution(end.Sub(start), requested)
+	res := seriesResolutions[chosen]
+	buckets, err := s.Repo.GetBatteryTimeSeries(deviceID, from, to, res.width)
+	if err != nil {
+		log.WriteLog.Error("Failed to fetch battery time series", zap.Error(err))
+		return jsonmodel.BatteryTimeSeriesResponse{}, false, fmt.Errorf("500:could not fetch battery time series: %w", err)
+	}
+
+	resp := jsonmodel.BatteryTimeSeriesResponse{
+		DeviceID:       deviceID,
+		From:           start.Format("2006-01-02 15:04:05"),
+		To:             end.Format("2006-01-02 15:04:05"),
+		Resolution:     res.name,
+		AutoResolution: chosen != requested,
+		Points:         make([]jsonmodel.BatteryPoint, 0, len(buckets)),
+	}
+	for _, b := range buckets {
+		state := "unknown"
+		switch {
+		case b.ChargingSeconds > b.DischargingSeconds:
+			state = "charging"
+		case b.DischargingSeconds > 0:
+			state = "discharging"
+		}
+		resp.Points = append(resp.Points, jsonmodel.BatteryPoint{
+			Time:               b.Bucket.Format("2006-01-02 15:04:05"),
+			Blocks:             b.Blocks,
+			MinLevel:           b.MinLevel,
+			MaxLevel:           b.MaxLevel,
+			AvgLevel:           math.Round(b.AvgLevel*100) / 100,
+			State:              state,
+			ChargingMinutes:    math.Round(b.ChargingSeconds/60*10) / 10,
+			DischargingMinutes: math.Round(b.DischargingSeconds/60*10) / 10,
+			Anomalies:          b.Anomalies,
+		})
+	}
+	return resp, true, nil
+}
+
+// seriesResolution returns the index of the resolution to use for a range: the
+// requested one (-1 for none) unless it yields more than MaxSeriesPoints buckets, in
+// which case the finest resolution that fits, and the coarsest one at worst.
+func seriesResolution(span time.Duration, requested int) int {
+	first := requested
+	if first < 0 {
+		first = 0
+	}
+	for i := first; i < len(seriesResolutions); i++ {
+		if span/seriesResolutions[i].width < MaxSeriesPoints {
+			return i
+		}
+	}
+	return len(seriesResolutions) - 1
+}
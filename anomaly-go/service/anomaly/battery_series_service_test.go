@@ -0,0 +1,56 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSeriesResolution(t *testing.T) {
+	day := 24 * time.Hour
+	tests := []struct {
+		name      string
+		span      time.Duration
+		requested int
+		want      string
+	}{
+		{"auto, short range", 499 * time.Minute, -1, "1m"},
+		{"auto, at the point limit", 500 * time.Minute, -1, "5m"},
+		{"auto, ten days", 10 * day, -1, "30m"},
+		{"auto, empty range", 0, -1, "1m"},
+		{"requested fits", day, 4, "1h"},
+		{"requested coarser than needed", time.Hour, 8, "1d"},
+		{"requested too fine", 10 * day, 0, "30m"},
+		{"too long for any resolution", 20 * 365 * day, -1, "1w"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := seriesResolutions[seriesResolution(tt.span, tt.requested)].name; got != tt.want {
+				t.Errorf("seriesResolution(%v, %d) = %s; want %s", tt.span, tt.requested, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetBatteryTimeSeriesInvalid(t *testing.T) {
+	tests := []struct {
+		name                 string
+		from, to, resolution string
+	}{
+		{"bad from", "yesterday", "", "auto"},
+		{"bad to", "", "2026-03-02", ""},
+		{"empty range", "2026-03-02 00:00:00", "2026-03-02 00:00:00", ""},
+		{"reversed range", "2026-03-03 00:00:00", "2026-03-02 00:00:00", ""},
+		{"unknown resolution", "", "", "2h"},
+	}
+
+	s := &Service{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := s.GetBatteryTimeSeries(7, tt.from, tt.to, tt.resolution)
+			if !errors.Is(err, ErrInvalidTimeSeries) {
+				t.Errorf("GetBatteryTimeSeries(%q, %q, %q) error = %v; want %v", tt.from, tt.to, tt.resolution, err, ErrInvalidTimeSeries)
+			}
+		})
+	}
+}
@@ -0,0 +1,231 @@
+package service
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"time"
+
+	"anomaly-go/log"
+	jsonmodel "anomaly-go/model/json"
+	model "anomaly-go/model/postgres"
+	"anomaly-go/service/battery"
+
+	"go.uber.org/zap"
+)
+
+// batteryWearBatch is the number of blocks folded in per round of a wear update.
+const batteryWearBatch = 5000
+
+// UpdateBatteryWear folds the battery_health blocks not folded in yet into the charge
+// cycle and charging habit accumulators of their device, so history is scanned only
+// once. Late and backfilled blocks are folded in whatever their number; a block
+// replaced by ingest is queued again, like for the anomaly detector, and the version
+// folded in before is taken back out.
+func (s *Service) UpdateBatteryWear() error {
+	stored, err := s.Repo.GetBatteryWear(nil)
+	if err != nil {
+		return fmt.Errorf("500:could not fetch battery wear: %w", err)
+	}
+	wear := make(map[int64]*battery.Wear, len(stored))
+	for _, w := range stored {
+		wear[w.DeviceID] = fromBatteryWearModel(w)
+	}
+
+	added := 0
+	for {
+		rows, err := s.Repo.GetUnaccumulatedBatteryBlocks(batteryWearBatch)
+		if err != nil {
+			return fmt.Errorf("500:could not fetch battery blocks: %w", err)
+		}
+		if len(rows) == 0 {
+			break
+		}
+
+		touched := map[int64]bool{}
+		blocks := make([]model.BatteryBlock, 0, len(rows))
+		for _, r := range rows {
+			w, ok := wear[r.DeviceID]
+			if !ok {
+				w = battery.NewWear()
+				wear[r.DeviceID] = w
+			}
+			if old, ok := accumulatedBlock(r); ok {
+				w.Remove(toBatteryBlock(old))
+			}
+			w.Add(toBatteryBlock(r.BatteryBlock))
+			touched[r.DeviceID] = true
+			blocks = append(blocks, r.BatteryBlock)
+		}
+
+		now := time.Now()
+		updated := make([]model.BatteryWear, 0, len(touched))
+		for id := range touched {
+			updated = append(updated, toBatteryWearModel(id, wear[id], now))
+		}
+		if err := s.Repo.SaveBatteryWear(updated, blocks, now); err != nil {
+			return fmt.Errorf("500:could not save battery wear: %w", err)
+		}
+		added += len(rows)
+		if len(rows) < batteryWearBatch {
+			break
+		}
+	}
+
+	if added > 0 {
+		log.WriteLog.Info("Battery wear updated", zap.Int("blocks", added))
+	}
+	return nil
+}
+
+// accumulatedBlock returns the version of a queued block folded into the wear
+// accumulators before ingest replaced it, if any.
+func accumulatedBlock(r model.UnaccumulatedBatteryBlock) (model.BatteryBlock, bool) {
+	if !r.AccumulatedStartTime.Valid {
+		return model.BatteryBlock{}, false
+	}
+	return model.BatteryBlock{
+		DeviceID:  r.DeviceID,
+		Block:     r.Block,
+		CS:        int(r.AccumulatedCS.Int64),
+		StartTime: r.AccumulatedStartTime.Time,
+		EndTime:   r.AccumulatedEndTime.Time,
+		StartBL:   r.AccumulatedStartBL.Float64,
+		EndBL:     r.AccumulatedEndBL.Float64,
+	}, true
+}
+
+// GetBatteryWear returns the wear metrics of one device, or of every device when
+// deviceID is 0.
+func (s *Service) GetBatteryWear(deviceID int64) (jsonmodel.BatteryWearResponse, error) {
+	var ids []int64
+	if deviceID != 0 {
+		ids = []int64{deviceID}
+	}
+	stored, err := s.Repo.GetBatteryWear(ids)
+	if err != nil {
+		log.WriteLog.Error("Failed to fetch battery wear", zap.Error(err))
+		return jsonmodel.BatteryWearResponse{}, fmt.Errorf("500:could not fetch battery wear: %w", err)
+	}
+
+	resp := jsonmodel.BatteryWearResponse{Devices: make([]jsonmodel.BatteryWear, 0, len(stored))}
+	for _, w := range stored {
+		resp.Devices = append(resp.Devices, toBatteryWearJSON(w))
+	}
+	return resp, nil
+}
+
+// GetFleetBatteryWear returns the distribution over devices of each wear metric.
+// Devices never seen discharging or charging are left out of the metrics that need it.
+func (s *Service) GetFleetBatteryWear() (jsonmodel.FleetBatteryWearResponse, error) {
+	stored, err := s.Repo.GetBatteryWear(nil)
+	if err != nil {
+		log.WriteLog.Error("Failed to fetch battery wear", zap.Error(err))
+		return jsonmodel.FleetBatteryWearResponse{}, fmt.Errorf("500:could not fetch battery wear: %w", err)
+	}
+
+	var cycles, depth, low, overnight, topUps []float64
+	for _, m := range stored {
+		w := fromBatteryWearModel(m)
+		cycles = append(cycles, w.Cycles())
+		if w.DischargeBlocks > 0 {
+			depth = append(depth, w.AvgDepthOfDischarge())
+		}
+		if w.ObservedSeconds > 0 {
+			low = append(low, w.LowShare()*100)
+		}
+		if w.ChargeBlocks > 0 {
+			overnight = append(overnight, w.OvernightShare()*100)
+			topUps = append(topUps, w.TopUpShare()*100)
+		}
+	}
+
+	return jsonmodel.FleetBatteryWearResponse{
+		Devices:                len(stored),
+		Cycles:                 toDistributionJSON(battery.Distribute(cycles)),
+		AvgDepthOfDischarge:    toDistributionJSON(battery.Distribute(depth)),
+		LowBatteryPercent:      toDistributionJSON(battery.Distribute(low)),
+		OvernightChargePercent: toDistributionJSON(battery.Distribute(overnight)),
+		TopUpPercent:           toDistributionJSON(battery.Distribute(topUps)),
+	}, nil
+}
+
+func toBatteryWearModel(deviceID int64, w *battery.Wear, updatedAt time.Time) model.BatteryWear {
+	return model.BatteryWear{
+		DeviceID:          deviceID,
+		Blocks:            w.Blocks,
+		ChargedPercent:    w.ChargedPercent,
+		DischargedPercent: w.DischargedPercent,
+		DischargeBlocks:   w.DischargeBlocks,
+		ChargeBlocks:      w.ChargeBlocks,
+		OvernightCharges:  w.OvernightCharges,
+		TopUps:            w.TopUps,
+		LowSeconds:        w.LowSeconds,
+		ObservedSeconds:   w.ObservedSeconds,
+		FirstTime:         sql.NullTime{Time: w.First, Valid: !w.First.IsZero()},
+		LastTime:          sql.NullTime{Time: w.Last, Valid: !w.Last.IsZero()},
+		UpdatedAt:         updatedAt,
+	}
+}
+
+func fromBatteryWearModel(m model.BatteryWear) *battery.Wear {
+	return &battery.Wear{
+		Blocks:            m.Blocks,
+		ChargedPercent:    m.ChargedPercent,
+		DischargedPercent: m.DischargedPercent,
+		DischargeBlocks:   m.DischargeBlocks,
+		ChargeBlocks:      m.ChargeBlocks,
+		OvernightCharges:  m.OvernightCharges,
+		TopUps:            m.TopUps,
+		LowSeconds:        m.LowSeconds,
+		ObservedSeconds:   m.ObservedSeconds,
+		First:             m.FirstTime.Time,
+		Last:              m.LastTime.Time,
+	}
+}
+
+func toBatteryWearJSON(m model.BatteryWear) jsonmodel.BatteryWear {
+	w := fromBatteryWearModel(m)
+	j := jsonmodel.BatteryWear{
+		DeviceID:               m.DeviceID,
+		Cycles:                 round2(w.Cycles()),
+		ChargedPercent:         round2(w.ChargedPercent),
+		DischargedPercent:      round2(w.DischargedPercent),
+		AvgDepthOfDischarge:    round2(w.AvgDepthOfDischarge()),
+		LowBatteryHours:        round2(w.LowSeconds / 3600),
+		LowBatteryPercent:      round2(w.LowShare() * 100),
+		Charges:                w.ChargeBlocks,
+		OvernightChargePercent: round2(w.OvernightShare() * 100),
+		TopUpPercent:           round2(w.TopUpShare() * 100),
+		Blocks:                 w.Blocks,
+		ObservedHours:          round2(w.ObservedSeconds / 3600),
+		UpdatedAt:              m.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+	if m.FirstTime.Valid {
+		first := m.FirstTime.Time.Format("2006-01-02 15:04:05")
+		j.FirstTime = &first
+	}
+	if m.LastTime.Valid {
+		last := m.LastTime.Time.Format("2006-01-02 15:04:05")
+		j.LastTime = &last
+	}
+	return j
+}
+
+func toDistributionJSON(d battery.Distribution) jsonmodel.Distribution {
+	return jsonmodel.Distribution{
+		Devices: d.Devices,
+		Mean:    round2(d.Mean),
+		Min:     round2(d.Min),
+		P10:     round2(d.P10),
+		P25:     round2(d.P25),
+		P50:     round2(d.P50),
+		P75:     round2(d.P75),
+		P90:     round2(d.P90),
+		Max:     round2(d.Max),
+	}
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,198 @@
+package service
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"time"
+
+	"anomaly-go/log"
+	jsonmodel "anomaly-go/model/json"
+	model "anomaly-go/model/postgres"
+
+	"go.uber.org/zap"
+)
+
+// Drift segments and metrics.
+const (
+	DriftSegmentFleet  = "fleet"
+	DriftSegmentDevice = "device"
+
+	DriftMetricConfidence = "confidence"
+	DriftMetricLabelMix   = "label_mix"
+)
+
+// driftEpsilon replaces empty bucket shares so PSI and KL stay finite.
+const driftEpsilon = 1e-4
+
+// driftHistograms are the bucket counts of one segment in both windows.
+type driftHistograms struct {
+	reference map[string]int
+	current   map[string]int
+}
+
+// ComputeDrift compares the confidence distribution and label mix of the current
+// window with the reference window before it, for the whole fleet and for each
+// device, and stores the population stability index and KL divergence of each.
+func (s *Service) ComputeDrift() error {
+	cfg := s.Config.AnalyticsConfig
+	curTo := time.Now()
+	curFrom := curTo.Add(-cfg.DriftCurrentWindow)
+	refFrom := curFrom.Add(-cfg.DriftReferenceWindow)
+
+	confidence, err := s.Repo.CountConfidenceBuckets(refFrom, curFrom, curTo)
+	if err != nil {
+		return fmt.Errorf("500:could not count confidence buckets: %w", err)
+	}
+	labels, err := s.Repo.CountLabelBuckets(refFrom, curFrom, curTo)
+	if err != nil {
+		return fmt.Errorf("500:could not count label buckets: %w", err)
+	}
+
+	base := model.DriftResult{ComputedAt: curTo, ReferenceFrom: refFrom, CurrentFrom: curFrom, CurrentTo: curTo}
+	results := append(s.driftResults(base, DriftMetricConfidence, confidence), s.driftResults(base, DriftMetricLabelMix, labels)...)
+
+	alerts := 0
+	for _, r := range results {
+		if !r.Alert {
+			continue
+		}
+		alerts++
+		log.WriteLog.Warn("Score drift above limit",
+			zap.String("segment", r.Segment),
+			zap.Int64("device_id", r.DeviceID.Int64),
+			zap.String("metric", r.Metric),
+			zap.Float64("psi", r.PSI),
+			zap.Float64("kl", r.KL),
+		)
+	}
+
+	if err := s.Repo.InsertDriftResults(results); err != nil {
+		return fmt.Errorf("500:could not store drift results: %w", err)
+	}
+	log.WriteLog.Info("Drift computed", zap.Int("results", len(results)), zap.Int("alerts", alerts))
+	return nil
+}
+
+// GetDriftSeries fetches the stored drift results of a segment.
+func (s *Service) GetDriftSeries(filter model.DriftFilter) (jsonmodel.DriftSeriesResponse, error) {
+	results, err := s.Repo.GetDriftResults(filter)
+	if err != nil {
+		log.WriteLog.Error("Failed to fetch drift results", zap.Error(err))
+		return jsonmodel.DriftSeriesResponse{}, fmt.Errorf("500:could not fetch drift results: %w", err)
+	}
+
+	resp := jsonmodel.DriftSeriesResponse{
+		PSIAlert: s.Config.AnalyticsConfig.DriftPSIAlert,
+		KLAlert:  s.Config.AnalyticsConfig.DriftKLAlert,
+		Series:   []jsonmodel.DriftPoint{},
+	}
+	for _, r := range results {
+		p := jsonmodel.DriftPoint{
+			ComputedAt:     r.ComputedAt.Format("2006-01-02 15:04:05"),
+			Segment:        r.Segment,
+			Metric:         r.Metric,
+			PSI:            r.PSI,
+			KL:             r.KL,
+			ReferenceCount: r.ReferenceCount,
+			CurrentCount:   r.CurrentCount,
+			ReferenceFrom:  r.ReferenceFrom.Format("2006-01-02 15:04:05"),
+			CurrentFrom:    r.CurrentFrom.Format("2006-01-02 15:04:05"),
+			CurrentTo:      r.CurrentTo.Format("2006-01-02 15:04:05"),
+			Alert:          r.Alert,
+		}
+		if r.DeviceID.Valid {
+			p.DeviceID = &r.DeviceID.Int64
+		}
+		resp.Series = append(resp.Series, p)
+	}
+	return resp, nil
+}
+
+// driftResults builds the fleet and per-device results of one metric. Segments with
+// too few transactions in either window are skipped.
+func (s *Service) driftResults(base model.DriftResult, metric string, counts []model.DriftBucketCount) []model.DriftResult {
+	cfg := s.Config.AnalyticsConfig
+
+	fleet := newDriftHistograms()
+	devices := map[int64]driftHistograms{}
+	var deviceIDs []int64
+	for _, c := range counts {
+		h, ok := devices[c.DeviceID]
+		if !ok {
+			h = newDriftHistograms()
+			devices[c.DeviceID] = h
+			deviceIDs = append(deviceIDs, c.DeviceID)
+		}
+		h.add(c)
+		fleet.add(c)
+	}
+
+	var results []model.DriftResult
+	appendResult := func(segment string, deviceID sql.NullInt64, h driftHistograms) {
+		refCount, curCount := histogramTotal(h.reference), histogramTotal(h.current)
+		if refCount < cfg.DriftMinSamples || curCount < cfg.DriftMinSamples {
+			return
+		}
+		psi, kl := driftDivergence(h.reference, h.current)
+		r := base
+		r.Segment = segment
+		r.DeviceID = deviceID
+		r.Metric = metric
+		r.PSI = psi
+		r.KL = kl
+		r.ReferenceCount = refCount
+		r.CurrentCount = curCount
+		r.Alert = psi > cfg.DriftPSIAlert || kl > cfg.DriftKLAlert
+		results = append(results, r)
+	}
+
+	appendResult(DriftSegmentFleet, sql.NullInt64{}, fleet)
+	for _, id := range deviceIDs {
+		appendResult(DriftSegmentDevice, sql.NullInt64{Int64: id, Valid: true}, devices[id])
+	}
+	return results
+}
+
+func newDriftHistograms() driftHistograms {
+	return driftHistograms{reference: map[string]int{}, current: map[string]int{}}
+}
+
+func (h driftHistograms) add(c model.DriftBucketCount) {
+	if c.Current {
+		h.current[c.Bucket] += c.Count
+	} else {
+		h.reference[c.Bucket] += c.Count
+	}
+}
+
+// driftDivergence returns the population stability index and the KL divergence of
+// the current distribution from the reference one.
+func driftDivergence(reference, current map[string]int) (float64, float64) {
+	refTotal, curTotal := float64(histogramTotal(reference)), float64(histogramTotal(current))
+
+	buckets := map[string]bool{}
+	for b := range reference {
+		buckets[b] = true
+	}
+	for b := range current {
+		buckets[b] = true
+	}
+
+	var psi, kl float64
+	for b := range buckets {
+		r := math.Max(float64(reference[b])/refTotal, driftEpsilon)
+		c := math.Max(float64(current[b])/curTotal, driftEpsilon)
+		psi += (c - r) * math.Log(c/r)
+		kl += c * math.Log(c/r)
+	}
+	return psi, math.Max(kl, 0)
+}
+
+func histogramTotal(counts map[string]int) int {
+	n := 0
+	for _, v := range counts {
+		n += v
+	}
+	return n
+}
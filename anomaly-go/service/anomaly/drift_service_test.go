@@ -0,0 +1,111 @@
+package service
+
+import (
+	"math"
+	"testing"
+
+	"anomaly-go/config/readenv"
+	model "anomaly-go/model/postgres"
+)
+
+func TestDriftDivergence(t *testing.T) {
+	tests := []struct {
+		name               string
+		reference, current map[string]int
+		wantPSI, wantKL    float64
+	}{
+		{
+			name:      "same distribution",
+			reference: map[string]int{"0-10": 30, "10-20": 70},
+			current:   map[string]int{"0-10": 3, "10-20": 7},
+			wantPSI:   0,
+			wantKL:    0,
+		},
+		{
+			name:      "shifted distribution",
+			reference: map[string]int{"a": 50, "b": 50},
+			current:   map[string]int{"a": 80, "b": 20},
+			wantPSI:   0.3*math.Log(0.8/0.5) - 0.3*math.Log(0.2/0.5),
+			wantKL:    0.8*math.Log(0.8/0.5) + 0.2*math.Log(0.2/0.5),
+		},
+		{
+			name:      "bucket missing from the reference",
+			reference: map[string]int{"a": 100},
+			current:   map[string]int{"a": 50, "b": 50},
+			wantPSI:   -0.5*math.Log(0.5) + (0.5-driftEpsilon)*math.Log(0.5/driftEpsilon),
+			wantKL:    0.5*math.Log(0.5) + 0.5*math.Log(0.5/driftEpsilon),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			psi, kl := driftDivergence(tt.reference, tt.current)
+			if math.Abs(psi-tt.wantPSI) > 1e-9 || math.Abs(kl-tt.wantKL) > 1e-9 {
+				t.Errorf("driftDivergence() = %v, %v; want %v, %v", psi, kl, tt.wantPSI, tt.wantKL)
+			}
+		})
+	}
+}
+
+func TestHistogramTotal(t *testing.T) {
+	if got := histogramTotal(map[string]int{"a": 3, "b": 4}); got != 7 {
+		t.Errorf("histogramTotal() = %d; want 7", got)
+	}
+	if got := histogramTotal(nil); got != 0 {
+		t.Errorf("histogramTotal(nil) = %d; want 0", got)
+	}
+}
+
+func TestDriftResults(t *testing.T) {
+	s := &Service{Config: &readenv.AppConfig{AnalyticsConfig: readenv.AnalyticsConfiguration{
+		DriftMinSamples: 10,
+		DriftPSIAlert:   0.25,
+		DriftKLAlert:    0.1,
+	}}}
+	counts := []model.DriftBucketCount{
+		// Device 1 is stable.
+		{DeviceID: 1, Current: false, Bucket: "a", Count: 50},
+		{DeviceID: 1, Current: false, Bucket: "b", Count: 50},
+		{DeviceID: 1, Current: true, Bucket: "a", Count: 10},
+		{DeviceID: 1, Current: true, Bucket: "b", Count: 10},
+		// Device 2 shifted to bucket a.
+		{DeviceID: 2, Current: false, Bucket: "a", Count: 10},
+		{DeviceID: 2, Current: false, Bucket: "b", Count: 10},
+		{DeviceID: 2, Current: true, Bucket: "a", Count: 19},
+		{DeviceID: 2, Current: true, Bucket: "b", Count: 1},
+		// Device 3 has too few current transactions.
+		{DeviceID: 3, Current: false, Bucket: "a", Count: 40},
+		{DeviceID: 3, Current: true, Bucket: "a", Count: 9},
+	}
+
+	results := s.driftResults(model.DriftResult{}, DriftMetricLabelMix, counts)
+
+	type segment struct {
+		name     string
+		deviceID int64
+	}
+	got := map[segment]model.DriftResult{}
+	for _, r := range results {
+		if r.Metric != DriftMetricLabelMix {
+			t.Errorf("driftResults() metric = %q; want %q", r.Metric, DriftMetricLabelMix)
+		}
+		got[segment{r.Segment, r.DeviceID.Int64}] = r
+	}
+	if len(got) != 3 {
+		t.Fatalf("driftResults() = %+v; want the fleet and devices 1 and 2", results)
+	}
+
+	fleet := got[segment{DriftSegmentFleet, 0}]
+	if fleet.DeviceID.Valid || fleet.ReferenceCount != 160 || fleet.CurrentCount != 49 {
+		t.Errorf("fleet result = %+v; want no device and counts 160/49", fleet)
+	}
+	if stable := got[segment{DriftSegmentDevice, 1}]; stable.Alert || stable.PSI > 1e-9 {
+		t.Errorf("device 1 result = %+v; want no drift", stable)
+	}
+	if shifted := got[segment{DriftSegmentDevice, 2}]; !shifted.Alert || !shifted.DeviceID.Valid {
+		t.Errorf("device 2 result = %+v; want an alert", shifted)
+	}
+	if _, ok := got[segment{DriftSegmentDevice, 3}]; ok {
+		t.Error("driftResults() kept device 3; want it skipped below the minimum samples")
+	}
+}
@@ -0,0 +1,30 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"anomaly-go/log"
+
+	"go.uber.org/zap"
+)
+
+// ReasonDuplicate is the reason code recorded on transactions flagged as duplicates.
+const ReasonDuplicate = "DUPLICATE"
+
+// DetectDuplicates flags likely replays and gateway retries among recent
+// transactions: same device, amount within the configured tolerance and an earlier
+// transaction within the configured window. Each duplicate is linked to its original.
+func (s *Service) DetectDuplicates() error {
+	cfg := s.Config.AnalyticsConfig
+	since := time.Now().Add(-cfg.DuplicateLookback)
+
+	flagged, err := s.Repo.FlagDuplicateTransactions(since, cfg.DuplicateWindow, cfg.DuplicateAmountTolerance, ReasonDuplicate)
+	if err != nil {
+		return fmt.Errorf("500:could not flag duplicate transactions: %w", err)
+	}
+	if flagged > 0 {
+		log.WriteLog.Info("Duplicate transactions flagged", zap.Int64("count", flagged))
+	}
+	return nil
+}
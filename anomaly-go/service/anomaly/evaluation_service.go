@@ -0,0 +1,120 @@
+package service
+
+import (
+	"fmt"
+
+	"anomaly-go/log"
+	jsonmodel "anomaly-go/model/json"
+	model "anomaly-go/model/postgres"
+
+	"go.uber.org/zap"
+)
+
+// EvaluationWindows are the accepted time windows for model evaluation, as understood by date_trunc.
+var EvaluationWindows = map[string]bool{"hour": true, "day": true, "week": true, "month": true}
+
+// DefaultEvaluationThresholds are the confidence thresholds used for the precision-recall curve
+// when the caller does not supply any.
+var DefaultEvaluationThresholds = []int{10, 20, 30, 40, 50, 60, 70, 80, 90}
+
+// EvaluateModel measures the model against reviewer verdicts, treating reviewed
+// transactions as ground truth.
+func (s *Service) EvaluateModel(filter model.TransactionFilter, window string, thresholds []int) (jsonmodel.ModelEvaluationResponse, error) {
+	byWindow, err := s.Repo.CountConfusionByWindow(filter, window)
+	if err != nil {
+		log.WriteLog.Error("Failed to count confusion matrix by window", zap.Error(err))
+		return jsonmodel.ModelEvaluationResponse{}, fmt.Errorf("500:could not evaluate model: %w", err)
+	}
+
+	byDevice, err := s.Repo.CountConfusionByDevice(filter)
+	if err != nil {
+		log.WriteLog.Error("Failed to count confusion matrix by device", zap.Error(err))
+		return jsonmodel.ModelEvaluationResponse{}, fmt.Errorf("500:could not evaluate model: %w", err)
+	}
+
+	buckets, err := s.Repo.CountReviewedByConfidence(filter)
+	if err != nil {
+		log.WriteLog.Error("Failed to count reviewed transactions by confidence", zap.Error(err))
+		return jsonmodel.ModelEvaluationResponse{}, fmt.Errorf("500:could not evaluate model: %w", err)
+	}
+
+	var overall model.ConfusionCounts
+	windows := []jsonmodel.WindowQuality{}
+	for _, c := range byWindow {
+		overall.TruePositives += c.TruePositives
+		overall.FalsePositives += c.FalsePositives
+		overall.TrueNegatives += c.TrueNegatives
+		overall.FalseNegatives += c.FalseNegatives
+		windows = append(windows, jsonmodel.WindowQuality{
+			WindowStart:    c.Bucket.Format("2006-01-02 15:04:05"),
+			QualityMetrics: newQualityMetrics(c),
+		})
+	}
+
+	devices := []jsonmodel.DeviceQuality{}
+	for _, c := range byDevice {
+		devices = append(devices, jsonmodel.DeviceQuality{
+			DeviceID:       c.DeviceID,
+			QualityMetrics: newQualityMetrics(c),
+		})
+	}
+
+	return jsonmodel.ModelEvaluationResponse{
+		Window:  window,
+		Overall: newQualityMetrics(overall),
+		Windows: windows,
+		Devices: devices,
+		Curve:   precisionRecallCurve(buckets, thresholds),
+	}, nil
+}
+
+// newQualityMetrics derives precision, recall and false positive rate from a confusion
+// matrix. Ratios with an empty denominator are reported as 0.
+func newQualityMetrics(c model.ConfusionCounts) jsonmodel.QualityMetrics {
+	return jsonmodel.QualityMetrics{
+		Reviewed:          c.TruePositives + c.FalsePositives + c.TrueNegatives + c.FalseNegatives,
+		Precision:         ratio(c.TruePositives, c.TruePositives+c.FalsePositives),
+		Recall:            ratio(c.TruePositives, c.TruePositives+c.FalseNegatives),
+		FalsePositiveRate: ratio(c.FalsePositives, c.FalsePositives+c.TrueNegatives),
+		ConfusionMatrix: jsonmodel.ConfusionMatrix{
+			TruePositives:  c.TruePositives,
+			FalsePositives: c.FalsePositives,
+			TrueNegatives:  c.TrueNegatives,
+			FalseNegatives: c.FalseNegatives,
+		},
+	}
+}
+
+// precisionRecallCurve evaluates "confidence >= threshold" as the prediction for every
+// threshold, using the per-point confidence histogram of reviewed transactions.
+func precisionRecallCurve(buckets []model.ConfidenceBucket, thresholds []int) []jsonmodel.ThresholdPoint {
+	totalPositives := 0
+	for _, b := range buckets {
+		totalPositives += b.Positives
+	}
+
+	curve := []jsonmodel.ThresholdPoint{}
+	for _, t := range thresholds {
+		tp, fp := 0, 0
+		for _, b := range buckets {
+			if b.Confidence >= t {
+				tp += b.Positives
+				fp += b.Negatives
+			}
+		}
+		curve = append(curve, jsonmodel.ThresholdPoint{
+			Threshold: t,
+			Precision: ratio(tp, tp+fp),
+			Recall:    ratio(tp, totalPositives),
+			Flagged:   tp + fp,
+		})
+	}
+	return curve
+}
+
+func ratio(num, den int) float64 {
+	if den == 0 {
+		return 0
+	}
+	return float64(num) / float64(den)
+}
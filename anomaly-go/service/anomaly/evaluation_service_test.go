@@ -0,0 +1,88 @@
+package service
+
+import (
+	"math"
+	"testing"
+
+	jsonmodel "anomaly-go/model/json"
+	model "anomaly-go/model/postgres"
+)
+
+func TestNewQualityMetrics(t *testing.T) {
+	tests := []struct {
+		name   string
+		counts model.ConfusionCounts
+		want   jsonmodel.QualityMetrics
+	}{
+		{"nothing reviewed", model.ConfusionCounts{}, jsonmodel.QualityMetrics{}},
+		{"mixed verdicts", model.ConfusionCounts{TruePositives: 6, FalsePositives: 2, TrueNegatives: 8, FalseNegatives: 4}, jsonmodel.QualityMetrics{
+			Reviewed:          20,
+			Precision:         0.75,
+			Recall:            0.6,
+			FalsePositiveRate: 0.2,
+			ConfusionMatrix:   jsonmodel.ConfusionMatrix{TruePositives: 6, FalsePositives: 2, TrueNegatives: 8, FalseNegatives: 4},
+		}},
+		{"no positives flagged", model.ConfusionCounts{TrueNegatives: 5, FalseNegatives: 5}, jsonmodel.QualityMetrics{
+			Reviewed:        10,
+			ConfusionMatrix: jsonmodel.ConfusionMatrix{TrueNegatives: 5, FalseNegatives: 5},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newQualityMetrics(tt.counts); got != tt.want {
+				t.Errorf("newQualityMetrics() = %+v; want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrecisionRecallCurve(t *testing.T) {
+	buckets := []model.ConfidenceBucket{
+		{Confidence: 10, Positives: 1, Negatives: 5},
+		{Confidence: 50, Positives: 2, Negatives: 2},
+		{Confidence: 90, Positives: 3, Negatives: 0},
+	}
+	tests := []struct {
+		name       string
+		buckets    []model.ConfidenceBucket
+		thresholds []int
+		want       []jsonmodel.ThresholdPoint
+	}{
+		{"no thresholds", buckets, nil, []jsonmodel.ThresholdPoint{}},
+		{"no reviews", nil, []int{50}, []jsonmodel.ThresholdPoint{{Threshold: 50}}},
+		{"every bucket flagged", buckets, []int{0}, []jsonmodel.ThresholdPoint{{Threshold: 0, Precision: 6.0 / 13, Recall: 1, Flagged: 13}}},
+		{"threshold on a bucket", buckets, []int{50}, []jsonmodel.ThresholdPoint{{Threshold: 50, Precision: 5.0 / 7, Recall: 5.0 / 6, Flagged: 7}}},
+		{"above every bucket", buckets, []int{95}, []jsonmodel.ThresholdPoint{{Threshold: 95}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := precisionRecallCurve(tt.buckets, tt.thresholds)
+			if len(got) != len(tt.want) {
+				t.Fatalf("precisionRecallCurve() = %+v; want %+v", got, tt.want)
+			}
+			for i := range got {
+				g, w := got[i], tt.want[i]
+				if g.Threshold != w.Threshold || g.Flagged != w.Flagged || math.Abs(g.Precision-w.Precision) > 1e-9 || math.Abs(g.Recall-w.Recall) > 1e-9 {
+					t.Errorf("precisionRecallCurve()[%d] = %+v; want %+v", i, g, w)
+				}
+			}
+		})
+	}
+}
+
+func TestRatio(t *testing.T) {
+	tests := []struct {
+		num, den int
+		want     float64
+	}{
+		{0, 0, 0},
+		{3, 0, 0},
+		{1, 4, 0.25},
+		{4, 4, 1},
+	}
+	for _, tt := range tests {
+		if got := ratio(tt.num, tt.den); got != tt.want {
+			t.Errorf("ratio(%d, %d) = %v; want %v", tt.num, tt.den, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	jsonmodel "anomaly-go/model/json"
+	model "anomaly-go/model/postgres"
+)
+
+const (
+	// DefaultHealthPageSize and MaxHealthPageSize bound the page size of
+	// /getDeviceHealthData. Without page or page_size every matching block is
+	// returned, as before pagination.
+	DefaultHealthPageSize = 100
+	MaxHealthPageSize     = 1000
+)
+
+// ErrInvalidFilter reports a query parameter that cannot be applied as a filter.
+var ErrInvalidFilter = errors.New("invalid filter")
+
+// parseDeviceHealthQuery validates the /getDeviceHealthData query parameters.
+func parseDeviceHealthQuery(q jsonmodel.DeviceHealthQuery) (model.DeviceHealthFilter, error) {
+	filter := model.DeviceHealthFilter{Search: q.Search, Page: 1}
+
+	if filterGiven(q.DeviceID) {
+		id, err := strconv.ParseInt(q.DeviceID, 10, 64)
+		if err != nil || id <= 0 {
+			return filter, fmt.Errorf("%w: 'device_id' must be a positive integer or 'all'", ErrInvalidFilter)
+		}
+		filter.DeviceID = id
+	}
+	if filterGiven(q.ChargingStatus) {
+		cs, ok := map[string]int64{"unknown": 0, "charging": batteryCharging, "discharging": batteryDischarging}[strings.ToLower(q.ChargingStatus)]
+		if !ok {
+			return filter, fmt.Errorf("%w: 'charging_status' must be charging, discharging, unknown or all", ErrInvalidFilter)
+		}
+		filter.ChargingStatus = sql.NullInt64{Int64: cs, Valid: true}
+	}
+	if filterGiven(q.IsAnomaly) {
+		flag, ok := map[string]int64{"no": 0, "yes": 1}[strings.ToLower(q.IsAnomaly)]
+		if !ok {
+			return filter, fmt.Errorf("%w: 'is_anomaly' must be yes, no or all", ErrInvalidFilter)
+		}
+		filter.IsAnomaly = sql.NullInt64{Int64: flag, Valid: true}
+	}
+
+	var err error
+	if filter.From, err = parseFilterTime("from", q.From); err != nil {
+		return filter, err
+	}
+	if filter.To, err = parseFilterTime("to", q.To); err != nil {
+		return filter, err
+	}
+	if filter.From.Valid && filter.To.Valid && !filter.From.Time.Before(filter.To.Time) {
+		return filter, fmt.Errorf("%w: 'from' must be before 'to'", ErrInvalidFilter)
+	}
+
+	if filter.MinBlock, err = parseFilterInt("min_block", q.MinBlock); err != nil {
+		return filter, err
+	}
+	if filter.MaxBlock, err = parseFilterInt("max_block", q.MaxBlock); err != nil {
+		return filter, err
+	}
+	if filter.MinBlock.Valid && filter.MaxBlock.Valid && filter.MinBlock.Int64 > filter.MaxBlock.Int64 {
+		return filter, fmt.Errorf("%w: 'min_block' must not exceed 'max_block'", ErrInvalidFilter)
+	}
+
+	if filter.MinLevel, err = parseFilterLevel("min_level", q.MinLevel); err != nil {
+		return filter, err
+	}
+	if filter.MaxLevel, err = parseFilterLevel("max_level", q.MaxLevel); err != nil {
+		return filter, err
+	}
+	if filter.MinLevel.Valid && filter.MaxLevel.Valid && filter.MinLevel.Float64 > filter.MaxLevel.Float64 {
+		return filter, fmt.Errorf("%w: 'min_level' must not exceed 'max_level'", ErrInvalidFilter)
+	}
+
+	if q.Page != "" {
+		page, err := strconv.Atoi(q.Page)
+		if err != nil || page < 1 {
+			return filter, fmt.Errorf("%w: 'page' must be a positive integer", ErrInvalidFilter)
+		}
+		filter.Page = page
+		filter.PageSize = DefaultHealthPageSize
+	}
+	if q.PageSize != "" {
+		size, err := strconv.Atoi(q.PageSize)
+		if err != nil || size < 1 || size > MaxHealthPageSize {
+			return filter, fmt.Errorf("%w: 'page_size' must be an integer between 1 and %d", ErrInvalidFilter, MaxHealthPageSize)
+		}
+		filter.PageSize = size
+	}
+	return filter, nil
+}
+
+// filterGiven reports whether a filter value is given and not "all".
+func filterGiven(value string) bool {
+	return value != "" && strings.ToLower(value) != "all"
+}
+
+func parseFilterTime(name, value string) (sql.NullTime, error) {
+	if value == "" {
+		return sql.NullTime{}, nil
+	}
+	ts, err := parseTimestamp(value)
+	if err != nil {
+		return sql.NullTime{}, fmt.Errorf("%w: '%s' must be 'YYYY-MM-DD HH:MM:SS' or RFC3339", ErrInvalidFilter, name)
+	}
+	return sql.NullTime{Time: ts, Valid: true}, nil
+}
+
+func parseFilterInt(name, value string) (sql.NullInt64, error) {
+	if value == "" {
+		return sql.NullInt64{}, nil
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || n < 0 {
+		return sql.NullInt64{}, fmt.Errorf("%w: '%s' must be a non-negative integer", ErrInvalidFilter, name)
+	}
+	return sql.NullInt64{Int64: n, Valid: true}, nil
+}
+
+func parseFilterLevel(name, value string) (sql.NullFloat64, error) {
+	if value == "" {
+		return sql.NullFloat64{}, nil
+	}
+	level, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsNaN(level) || level < 0 || level > 100 {
+		return sql.NullFloat64{}, fmt.Errorf("%w: '%s' must be a number between 0 and 100", ErrInvalidFilter, name)
+	}
+	return sql.NullFloat64{Float64: level, Valid: true}, nil
+}
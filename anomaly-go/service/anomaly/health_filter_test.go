@@ -0,0 +1,144 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	jsonmodel "anomaly-go/model/json"
+	model "anomaly-go/model/postgres"
+)
+
+func TestParseDeviceHealthQuery(t *testing.T) {
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.Local)
+	to := time.Date(2026, 3, 2, 0, 0, 0, 0, time.Local)
+	tests := []struct {
+		name string
+		q    jsonmodel.DeviceHealthQuery
+		want model.DeviceHealthFilter
+	}{
+		{
+			name: "no filters",
+			q:    jsonmodel.DeviceHealthQuery{},
+			want: model.DeviceHealthFilter{Page: 1},
+		},
+		{
+			name: "all disables filters",
+			q:    jsonmodel.DeviceHealthQuery{DeviceID: "all", ChargingStatus: "ALL", IsAnomaly: "All"},
+			want: model.DeviceHealthFilter{Page: 1},
+		},
+		{
+			name: "device, status and anomaly",
+			q:    jsonmodel.DeviceHealthQuery{DeviceID: "42", ChargingStatus: "Discharging", IsAnomaly: "yes", Search: "17"},
+			want: model.DeviceHealthFilter{
+				DeviceID:       42,
+				ChargingStatus: sql.NullInt64{Int64: batteryDischarging, Valid: true},
+				IsAnomaly:      sql.NullInt64{Int64: 1, Valid: true},
+				Search:         "17",
+				Page:           1,
+			},
+		},
+		{
+			name: "unknown charging status",
+			q:    jsonmodel.DeviceHealthQuery{ChargingStatus: "unknown", IsAnomaly: "no"},
+			want: model.DeviceHealthFilter{
+				ChargingStatus: sql.NullInt64{Int64: 0, Valid: true},
+				IsAnomaly:      sql.NullInt64{Int64: 0, Valid: true},
+				Page:           1,
+			},
+		},
+		{
+			name: "ranges",
+			q: jsonmodel.DeviceHealthQuery{
+				From: "2026-03-01 00:00:00", To: "2026-03-02 00:00:00",
+				MinBlock: "10", MaxBlock: "10", MinLevel: "20", MaxLevel: "80.5",
+			},
+			want: model.DeviceHealthFilter{
+				From:     sql.NullTime{Time: from, Valid: true},
+				To:       sql.NullTime{Time: to, Valid: true},
+				MinBlock: sql.NullInt64{Int64: 10, Valid: true},
+				MaxBlock: sql.NullInt64{Int64: 10, Valid: true},
+				MinLevel: sql.NullFloat64{Float64: 20, Valid: true},
+				MaxLevel: sql.NullFloat64{Float64: 80.5, Valid: true},
+				Page:     1,
+			},
+		},
+		{
+			name: "page with the default size",
+			q:    jsonmodel.DeviceHealthQuery{Page: "3"},
+			want: model.DeviceHealthFilter{Page: 3, PageSize: DefaultHealthPageSize},
+		},
+		{
+			name: "page size alone",
+			q:    jsonmodel.DeviceHealthQuery{PageSize: "25"},
+			want: model.DeviceHealthFilter{Page: 1, PageSize: 25},
+		},
+		{
+			name: "page and page size",
+			q:    jsonmodel.DeviceHealthQuery{Page: "2", PageSize: "1000"},
+			want: model.DeviceHealthFilter{Page: 2, PageSize: MaxHealthPageSize},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseDeviceHealthQuery(tt.q)
+			if err != nil {
+				t.Fatalf("parseDeviceHealthQuery() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseDeviceHealthQuery() = %+v; want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDeviceHealthQueryInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		q    jsonmodel.DeviceHealthQuery
+	}{
+		{"device not a number", jsonmodel.DeviceHealthQuery{DeviceID: "abc"}},
+		{"device not positive", jsonmodel.DeviceHealthQuery{DeviceID: "0"}},
+		{"charging status", jsonmodel.DeviceHealthQuery{ChargingStatus: "2"}},
+		{"anomaly flag", jsonmodel.DeviceHealthQuery{IsAnomaly: "true"}},
+		{"from", jsonmodel.DeviceHealthQuery{From: "2026-03-01"}},
+		{"to", jsonmodel.DeviceHealthQuery{To: "tomorrow"}},
+		{"empty time range", jsonmodel.DeviceHealthQuery{From: "2026-03-01 00:00:00", To: "2026-03-01 00:00:00"}},
+		{"negative block", jsonmodel.DeviceHealthQuery{MinBlock: "-1"}},
+		{"block not an integer", jsonmodel.DeviceHealthQuery{MaxBlock: "1.5"}},
+		{"reversed block range", jsonmodel.DeviceHealthQuery{MinBlock: "11", MaxBlock: "10"}},
+		{"level above 100", jsonmodel.DeviceHealthQuery{MaxLevel: "101"}},
+		{"level not a number", jsonmodel.DeviceHealthQuery{MinLevel: "NaN"}},
+		{"reversed level range", jsonmodel.DeviceHealthQuery{MinLevel: "60", MaxLevel: "40"}},
+		{"page zero", jsonmodel.DeviceHealthQuery{Page: "0"}},
+		{"page size too large", jsonmodel.DeviceHealthQuery{PageSize: "1001"}},
+		{"page size not a number", jsonmodel.DeviceHealthQuery{PageSize: "ten"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parseDeviceHealthQuery(tt.q); !errors.Is(err, ErrInvalidFilter) {
+				t.Errorf("parseDeviceHealthQuery(%+v) error = %v; want %v", tt.q, err, ErrInvalidFilter)
+			}
+		})
+	}
+}
+
+func TestFilterGiven(t *testing.T) {
+	tests := []struct {
+		value string
+		want  bool
+	}{
+		{"", false},
+		{"all", false},
+		{"ALL", false},
+		{"7", true},
+		{"allowed", true},
+	}
+	for _, tt := range tests {
+		if got := filterGiven(tt.value); got != tt.want {
+			t.Errorf("filterGiven(%q) = %v; want %v", tt.value, got, tt.want)
+		}
+	}
+}
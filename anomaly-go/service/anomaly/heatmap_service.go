@@ -0,0 +1,83 @@
+package service
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"anomaly-go/log"
+	jsonmodel "anomaly-go/model/json"
+	model "anomaly-go/model/postgres"
+	"anomaly-go/service/scoring"
+
+	"go.uber.org/zap"
+)
+
+// GetAnomalyHeatmap returns when anomalies cluster: transactions, anomalies and
+// anomaly rates of the transactions matching the /fetchData filters per weekday and
+// hour, read in timeZone when given.
+func (s *Service) GetAnomalyHeatmap(filter model.TransactionFilter, timeZone string) (jsonmodel.AnomalyHeatmapResponse, error) {
+	cells, err := s.Repo.GetAnomalyHeatmap(filter, timeZone, serverTimeZone())
+	if err != nil {
+		log.WriteLog.Error("Failed to fetch anomaly heatmap", zap.Error(err))
+		return jsonmodel.AnomalyHeatmapResponse{}, fmt.Errorf("500:could not fetch anomaly heatmap: %w", err)
+	}
+
+	resp := buildAnomalyHeatmap(cells)
+	resp.TimeZone = timeZone
+	if resp.TimeZone == "" {
+		resp.TimeZone = serverTimeZone()
+	}
+	return resp, nil
+}
+
+// buildAnomalyHeatmap lays the weekday and hour cells out as 7x24 matrices, Monday
+// first. Cells outside the grid are ignored.
+func buildAnomalyHeatmap(cells []model.HeatmapCell) jsonmodel.AnomalyHeatmapResponse {
+	resp := jsonmodel.AnomalyHeatmapResponse{
+		Days:         make([]string, 7),
+		Transactions: make([][]int, 7),
+		Anomalies:    make([][]int, 7),
+		AnomalyRates: make([][]float64, 7),
+	}
+	for day := 0; day < 7; day++ {
+		resp.Days[day] = time.Weekday((day + 1) % 7).String()
+		resp.Transactions[day] = make([]int, scoring.HoursPerDay)
+		resp.Anomalies[day] = make([]int, scoring.HoursPerDay)
+		resp.AnomalyRates[day] = make([]float64, scoring.HoursPerDay)
+	}
+	for _, c := range cells {
+		day := c.DayOfWeek - 1
+		if day < 0 || day >= 7 || c.Hour < 0 || c.Hour >= scoring.HoursPerDay {
+			continue
+		}
+		resp.Transactions[day][c.Hour] = c.Transactions
+		resp.Anomalies[day][c.Hour] = c.Anomalies
+		resp.AnomalyRates[day][c.Hour] = ratio(c.Anomalies, c.Transactions)
+		resp.Total += c.Transactions
+		resp.TotalAnomalies += c.Anomalies
+	}
+	return resp
+}
+
+// serverTimeZone names the server local zone, in which transaction times are stored,
+// for the database: the zone TZ names, otherwise the zoneinfo file /etc/localtime
+// links to, otherwise the current UTC offset as a POSIX zone, which counts hours
+// west of Greenwich.
+func serverTimeZone() string {
+	if name := time.Local.String(); name != "Local" {
+		return name
+	}
+	if target, err := os.Readlink("/etc/localtime"); err == nil {
+		if i := strings.LastIndex(target, "zoneinfo/"); i >= 0 {
+			return target[i+len("zoneinfo/"):]
+		}
+	}
+	_, offset := time.Now().Zone()
+	sign := "-"
+	if offset < 0 {
+		sign, offset = "+", -offset
+	}
+	return fmt.Sprintf("UTC%s%02d:%02d", sign, offset/3600, offset%3600/60)
+}
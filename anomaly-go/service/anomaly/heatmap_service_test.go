@@ -0,0 +1,73 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	model "anomaly-go/model/postgres"
+)
+
+func TestBuildAnomalyHeatmap(t *testing.T) {
+	cells := []model.HeatmapCell{
+		{DayOfWeek: 1, Hour: 0, Transactions: 10, Anomalies: 1},
+		{DayOfWeek: 3, Hour: 14, Transactions: 4, Anomalies: 2},
+		{DayOfWeek: 7, Hour: 23, Transactions: 5, Anomalies: 0},
+		// Outside the grid.
+		{DayOfWeek: 0, Hour: 10, Transactions: 100, Anomalies: 100},
+		{DayOfWeek: 8, Hour: 10, Transactions: 100, Anomalies: 100},
+		{DayOfWeek: 2, Hour: 24, Transactions: 100, Anomalies: 100},
+	}
+
+	got := buildAnomalyHeatmap(cells)
+
+	wantDays := []string{"Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday", "Sunday"}
+	for i, day := range wantDays {
+		if got.Days[i] != day {
+			t.Errorf("Days[%d] = %q; want %q", i, got.Days[i], day)
+		}
+		if len(got.Transactions[i]) != 24 || len(got.Anomalies[i]) != 24 || len(got.AnomalyRates[i]) != 24 {
+			t.Fatalf("row %d has %d/%d/%d hours; want 24", i, len(got.Transactions[i]), len(got.Anomalies[i]), len(got.AnomalyRates[i]))
+		}
+	}
+
+	tests := []struct {
+		day, hour    int
+		transactions int
+		anomalies    int
+		rate         float64
+	}{
+		{0, 0, 10, 1, 0.1},
+		{2, 14, 4, 2, 0.5},
+		{6, 23, 5, 0, 0},
+		{1, 10, 0, 0, 0},
+	}
+	for _, tt := range tests {
+		if got.Transactions[tt.day][tt.hour] != tt.transactions || got.Anomalies[tt.day][tt.hour] != tt.anomalies ||
+			got.AnomalyRates[tt.day][tt.hour] != tt.rate {
+			t.Errorf("cell %s %02d:00 = %d, %d, %v; want %d, %d, %v", wantDays[tt.day], tt.hour,
+				got.Transactions[tt.day][tt.hour], got.Anomalies[tt.day][tt.hour], got.AnomalyRates[tt.day][tt.hour],
+				tt.transactions, tt.anomalies, tt.rate)
+		}
+	}
+
+	if got.Total != 19 || got.TotalAnomalies != 3 {
+		t.Errorf("totals = %d, %d; want 19, 3", got.Total, got.TotalAnomalies)
+	}
+}
+
+func TestServerTimeZone(t *testing.T) {
+	name := serverTimeZone()
+	if strings.HasPrefix(name, "UTC+") || strings.HasPrefix(name, "UTC-") {
+		return
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatalf("serverTimeZone() = %q, not a zone: %v", name, err)
+	}
+	now := time.Now()
+	_, want := now.Zone()
+	if _, got := now.In(loc).Zone(); got != want {
+		t.Errorf("serverTimeZone() = %q at offset %ds; want the server offset %ds", name, got, want)
+	}
+}
@@ -0,0 +1,289 @@
+package service
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"anomaly-go/log"
+	jsonmodel "anomaly-go/model/json"
+	model "anomaly-go/model/postgres"
+	"anomaly-go/service/rules"
+	"anomaly-go/service/scoring"
+	"anomaly-go/util/constants"
+
+	"go.uber.org/zap"
+)
+
+// Ingest result statuses.
+const (
+	IngestStatusScored    = "scored"
+	IngestStatusDuplicate = "duplicate"
+	IngestStatusInvalid   = "invalid"
+)
+
+// IngestTransactions scores a batch of new transactions with the active scorer,
+// evaluates the fraud rules and stores them in anomaly_results. A rule hit raises an
+// otherwise normal label to "review required". Transactions are processed in time order so the
+// device baselines see them as they happened. Already known transaction IDs are
+// reported as duplicates and do not affect the baselines. A running shadow candidate
+// is scored too, into the shadow columns only.
+func (s *Service) IngestTransactions(reqs []jsonmodel.IngestTransaction) (jsonmodel.IngestTransactionsResponse, error) {
+	resp := jsonmodel.IngestTransactionsResponse{Results: make([]jsonmodel.IngestResult, len(reqs))}
+
+	type pending struct {
+		index int
+		txn   scoring.Transaction
+	}
+	var valid []pending
+	deviceSet := map[int64]bool{}
+	for i, req := range reqs {
+		resp.Results[i] = jsonmodel.IngestResult{DeviceID: req.DeviceID, TransactionID: req.TransactionID}
+		txn, err := parseIngestTransaction(req)
+		if err != nil {
+			resp.Results[i].Status = IngestStatusInvalid
+			resp.Results[i].Error = err.Error()
+			resp.Invalid++
+			continue
+		}
+		valid = append(valid, pending{index: i, txn: txn})
+		deviceSet[txn.DeviceID] = true
+	}
+	sort.SliceStable(valid, func(i, j int) bool { return valid[i].txn.Time.Before(valid[j].txn.Time) })
+
+	if len(valid) == 0 {
+		return resp, nil
+	}
+
+	threshold, err := s.currentThreshold()
+	if err != nil {
+		return jsonmodel.IngestTransactionsResponse{}, err
+	}
+
+	s.scoreMu.Lock()
+	defer s.scoreMu.Unlock()
+
+	scorer := s.ActiveScorer()
+	modelVersion := scorer.Model().Key()
+	shadow := s.activeShadowCandidate()
+
+	baselines, err := s.loadBaselines(deviceSet)
+	if err != nil {
+		return jsonmodel.IngestTransactionsResponse{}, err
+	}
+	deviceIDs := make([]int64, 0, len(deviceSet))
+	for id := range deviceSet {
+		deviceIDs = append(deviceIDs, id)
+	}
+	seasonal, err := s.loadSeasonality(deviceIDs)
+	if err != nil {
+		return jsonmodel.IngestTransactionsResponse{}, err
+	}
+
+	for _, p := range valid {
+		baseline, ok := baselines[p.txn.DeviceID]
+		if !ok {
+			baseline = scoring.NewBaseline(p.txn.DeviceID)
+		}
+		baseline.Seasonality = seasonal[p.txn.DeviceID]
+
+		result := scorer.Score(baseline, p.txn)
+		ruleTxn := rules.Transaction{DeviceID: p.txn.DeviceID, ID: p.txn.ID, Time: p.txn.Time, Amount: p.txn.Amount}
+		hits, err := s.evaluateRules(ruleTxn)
+		if err != nil {
+			return jsonmodel.IngestTransactionsResponse{}, err
+		}
+
+		label := scoreLabel(result.Confidence, threshold, len(hits) > 0)
+
+		hitModels := toRuleHitModels(ruleTxn, hits)
+		reasonModels := append(toReasonModels(p.txn, result.Reasons), ruleReasonModels(hitModels)...)
+
+		updated := baseline.Clone()
+		scorer.Update(updated, p.txn)
+
+		txnModel := model.Transaction{
+			DeviceID:          p.txn.DeviceID,
+			TransactionID:     p.txn.ID,
+			TransactionTime:   p.txn.Time,
+			TransactionAmount: p.txn.Amount,
+			ConfidenceScore:   result.Confidence,
+			AnomalyCheck:      sql.NullString{String: label, Valid: true},
+			ModelVersion:      sql.NullString{String: modelVersion, Valid: true},
+		}
+		if shadow != nil {
+			shadow.scoreShadow(&txnModel, scorer, result, baseline, p.txn, threshold, len(hits) > 0)
+		}
+
+		inserted, err := s.Repo.InsertScoredTransaction(txnModel, reasonModels, hitModels, toBaselineModel(updated))
+		if err != nil {
+			log.WriteLog.Error("Failed to store scored transaction", zap.String("txn_id", p.txn.ID), zap.Error(err))
+			return jsonmodel.IngestTransactionsResponse{}, fmt.Errorf("500:could not store scored transaction: %w", err)
+		}
+
+		res := &resp.Results[p.index]
+		if !inserted {
+			res.Status = IngestStatusDuplicate
+			resp.Duplicates++
+			continue
+		}
+		baselines[p.txn.DeviceID] = updated
+		res.Status = IngestStatusScored
+		res.ConfidenceScore = result.Confidence
+		res.AnomalyCheck = label
+		res.ModelVersion = modelVersion
+		for _, r := range reasonModels {
+			res.Reasons = append(res.Reasons, toReasonJSON(r))
+		}
+		for _, h := range hitModels {
+			res.RuleHits = append(res.RuleHits, toRuleHitJSON(h))
+		}
+		resp.Scored++
+	}
+
+	log.WriteLog.Info("Ingested transactions",
+		zap.Int("scored", resp.Scored),
+		zap.Int("duplicates", resp.Duplicates),
+		zap.Int("invalid", resp.Invalid),
+	)
+	return resp, nil
+}
+
+// currentThreshold returns the stored confidence threshold, or the default when none is set.
+func (s *Service) currentThreshold() (float64, error) {
+	threshold, found, err := s.Repo.GetConfidenceThreshold()
+	if err != nil {
+		return 0, fmt.Errorf("500:database error on fetch threshold: %w", err)
+	}
+	if !found {
+		return constants.DefaultConfidenceThreshold, nil
+	}
+	return threshold, nil
+}
+
+// loadBaselines reads the stored baselines of the given devices.
+func (s *Service) loadBaselines(deviceSet map[int64]bool) (map[int64]*scoring.Baseline, error) {
+	deviceIDs := make([]int64, 0, len(deviceSet))
+	for id := range deviceSet {
+		deviceIDs = append(deviceIDs, id)
+	}
+
+	stored, err := s.Repo.GetDeviceBaselines(deviceIDs)
+	if err != nil {
+		log.WriteLog.Error("Failed to fetch device baselines", zap.Error(err))
+		return nil, fmt.Errorf("500:could not fetch device baselines: %w", err)
+	}
+
+	baselines := make(map[int64]*scoring.Baseline, len(stored))
+	for _, b := range stored {
+		baselines[b.DeviceID] = fromBaselineModel(b)
+	}
+	return baselines, nil
+}
+
+// scoreLabel maps a score to a label: scores at or above the threshold are anomalies,
+// and a rule hit raises an otherwise normal transaction to "review required".
+func scoreLabel(confidence, threshold float64, ruleHit bool) string {
+	switch {
+	case confidence >= threshold:
+		return constants.LabelAnomalyDetected
+	case ruleHit:
+		return constants.LabelReviewRequired
+	default:
+		return constants.LabelNotFraud
+	}
+}
+
+// parseIngestTransaction validates one ingested transaction.
+func parseIngestTransaction(req jsonmodel.IngestTransaction) (scoring.Transaction, error) {
+	if req.DeviceID <= 0 {
+		return scoring.Transaction{}, fmt.Errorf("device_id must be a positive integer")
+	}
+	if strings.TrimSpace(req.TransactionID) == "" {
+		return scoring.Transaction{}, fmt.Errorf("transaction_id is required")
+	}
+	if math.IsNaN(req.TransactionAmount) || math.IsInf(req.TransactionAmount, 0) || req.TransactionAmount < 0 {
+		return scoring.Transaction{}, fmt.Errorf("transaction_amount must be a non-negative number")
+	}
+	ts, err := parseTimestamp(req.TransactionTime)
+	if err != nil {
+		return scoring.Transaction{}, fmt.Errorf("transaction_time must be 'YYYY-MM-DD HH:MM:SS' or RFC3339")
+	}
+
+	return scoring.Transaction{
+		DeviceID: req.DeviceID,
+		ID:       strings.TrimSpace(req.TransactionID),
+		Time:     ts,
+		Amount:   req.TransactionAmount,
+	}, nil
+}
+
+// parseTimestamp accepts the "2006-01-02 15:04:05" layout used in API responses, in
+// server local time, as well as RFC3339. Either is returned in server local time,
+// the zone of the naive timestamps stored and of the hour-of-day features.
+func parseTimestamp(value string) (time.Time, error) {
+	if ts, err := time.ParseInLocation("2006-01-02 15:04:05", value, time.Local); err == nil {
+		return ts, nil
+	}
+	ts, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return ts.In(time.Local), nil
+}
+
+func toBaselineModel(b *scoring.Baseline) model.DeviceBaseline {
+	return model.DeviceBaseline{
+		DeviceID:    b.DeviceID,
+		TxnCount:    b.Count,
+		AmountMean:  b.AmountMean,
+		AmountVar:   b.AmountVar,
+		GapMean:     b.GapMean,
+		GapVar:      b.GapVar,
+		LastTxnTime: b.LastTxnTime,
+		HourProfile: b.HourProfile,
+		UpdatedAt:   time.Now(),
+	}
+}
+
+func fromBaselineModel(b model.DeviceBaseline) *scoring.Baseline {
+	return &scoring.Baseline{
+		DeviceID:    b.DeviceID,
+		Count:       b.TxnCount,
+		AmountMean:  b.AmountMean,
+		AmountVar:   b.AmountVar,
+		GapMean:     b.GapMean,
+		GapVar:      b.GapVar,
+		LastTxnTime: b.LastTxnTime,
+		HourProfile: b.HourProfile,
+	}
+}
+
+func toReasonModels(txn scoring.Transaction, reasons []scoring.Reason) []model.AnomalyReason {
+	var models []model.AnomalyReason
+	for _, r := range reasons {
+		models = append(models, model.AnomalyReason{
+			DeviceID:      txn.DeviceID,
+			TransactionID: txn.ID,
+			Code:          r.Code,
+			Message:       r.Message,
+			Feature:       r.Feature,
+			Value:         r.Value,
+			Baseline:      r.Baseline,
+		})
+	}
+	return models
+}
+
+func toReasonJSON(r model.AnomalyReason) jsonmodel.Reason {
+	return jsonmodel.Reason{
+		Code:     r.Code,
+		Message:  r.Message,
+		Feature:  r.Feature,
+		Value:    r.Value,
+		Baseline: r.Baseline,
+	}
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	jsonmodel "anomaly-go/model/json"
+	"anomaly-go/service/scoring"
+	"anomaly-go/util/constants"
+)
+
+func TestScoreLabel(t *testing.T) {
+	tests := []struct {
+		name       string
+		confidence float64
+		ruleHit    bool
+		want       string
+	}{
+		{"below the threshold", 49.9, false, constants.LabelNotFraud},
+		{"at the threshold", 50, false, constants.LabelAnomalyDetected},
+		{"rule hit below the threshold", 10, true, constants.LabelReviewRequired},
+		{"rule hit above the threshold", 80, true, constants.LabelAnomalyDetected},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := scoreLabel(tt.confidence, 50, tt.ruleHit); got != tt.want {
+				t.Errorf("scoreLabel(%v, 50, %v) = %q; want %q", tt.confidence, tt.ruleHit, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseIngestTransaction(t *testing.T) {
+	valid := jsonmodel.IngestTransaction{DeviceID: 7, TransactionID: " t-1 ", TransactionTime: "2026-03-02 09:30:00", TransactionAmount: 12.5}
+	with := func(change func(*jsonmodel.IngestTransaction)) jsonmodel.IngestTransaction {
+		req := valid
+		change(&req)
+		return req
+	}
+	tests := []struct {
+		name    string
+		req     jsonmodel.IngestTransaction
+		want    scoring.Transaction
+		wantErr bool
+	}{
+		{"valid", valid, scoring.Transaction{DeviceID: 7, ID: "t-1", Time: time.Date(2026, 3, 2, 9, 30, 0, 0, time.Local), Amount: 12.5}, false},
+		{"zero amount", with(func(r *jsonmodel.IngestTransaction) { r.TransactionAmount = 0 }), scoring.Transaction{DeviceID: 7, ID: "t-1", Time: time.Date(2026, 3, 2, 9, 30, 0, 0, time.Local)}, false},
+		{"no device", with(func(r *jsonmodel.IngestTransaction) { r.DeviceID = 0 }), scoring.Transaction{}, true},
+		{"blank transaction id", with(func(r *jsonmodel.IngestTransaction) { r.TransactionID = "  " }), scoring.Transaction{}, true},
+		{"negative amount", with(func(r *jsonmodel.IngestTransaction) { r.TransactionAmount = -1 }), scoring.Transaction{}, true},
+		{"NaN amount", with(func(r *jsonmodel.IngestTransaction) { r.TransactionAmount = math.NaN() }), scoring.Transaction{}, true},
+		{"infinite amount", with(func(r *jsonmodel.IngestTransaction) { r.TransactionAmount = math.Inf(1) }), scoring.Transaction{}, true},
+		{"bad time", with(func(r *jsonmodel.IngestTransaction) { r.TransactionTime = "02/03/2026" }), scoring.Transaction{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseIngestTransaction(tt.req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseIngestTransaction() error = %v; want error %v", err, tt.wantErr)
+			}
+			if got.DeviceID != tt.want.DeviceID || got.ID != tt.want.ID || !got.Time.Equal(tt.want.Time) || got.Amount != tt.want.Amount {
+				t.Errorf("parseIngestTransaction() = %+v; want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTimestamp(t *testing.T) {
+	local := time.Date(2026, 3, 2, 9, 30, 0, 0, time.Local)
+	tests := []struct {
+		name    string
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{"server local layout", "2026-03-02 09:30:00", local, false},
+		{"RFC3339 in UTC", local.UTC().Format(time.RFC3339), local, false},
+		{"RFC3339 with an offset", local.In(time.FixedZone("", 5*3600+1800)).Format(time.RFC3339), local, false},
+		{"date only", "2026-03-02", time.Time{}, true},
+		{"empty", "", time.Time{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseTimestamp(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseTimestamp(%q) error = %v; want error %v", tt.value, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !got.Equal(tt.want) || got.Location() != time.Local || got.Hour() != tt.want.Hour() {
+				t.Errorf("parseTimestamp(%q) = %v; want %v in server local time", tt.value, got, tt.want)
+			}
+		})
+	}
+}
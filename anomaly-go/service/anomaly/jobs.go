@@ -0,0 +1,52 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"anomaly-go/log"
+
+	"go.uber.org/zap"
+)
+
+// StartBackgroundJobs launches the periodic jobs of the service. They stop when ctx
+// is cancelled.
+func (s *Service) StartBackgroundJobs(ctx context.Context) {
+	analytics := s.Config.AnalyticsConfig
+	s.jobsCtx = ctx
+
+	go runPeriodic(ctx, "fraud rule reload", analytics.RuleReloadInterval, s.ReloadRules)
+	go runPeriodic(ctx, "score drift monitoring", analytics.DriftInterval, s.ComputeDrift)
+	go runPeriodic(ctx, "duplicate detection", analytics.DuplicateInterval, s.DetectDuplicates)
+	go runPeriodic(ctx, "seasonality baselines", analytics.SeasonalityInterval, s.UpdateSeasonality)
+	go runPeriodic(ctx, "silent device detection", analytics.SilentInterval, s.DetectSilentDevices)
+	go runPeriodic(ctx, "battery scoring", analytics.BatteryScoreInterval, s.ComputeBatteryScores)
+	go runPeriodic(ctx, "battery anomaly detection", analytics.BatteryAnomalyInterval, s.DetectBatteryAnomalies)
+	go runPeriodic(ctx, "battery wear", analytics.BatteryWearInterval, s.UpdateBatteryWear)
+	go runPeriodic(ctx, "battery KPI snapshots", batteryKPICheckInterval, s.SnapshotBatteryKPIs)
+
+	if err := s.resumeInterruptedRescore(); err != nil {
+		log.WriteLog.Error("Failed to resume rescore job", zap.Error(err))
+	}
+}
+
+// runPeriodic calls job every interval until ctx is cancelled. Failures are logged
+// and retried on the next tick.
+func runPeriodic(ctx context.Context, name string, interval time.Duration, job func() error) {
+	log.WriteLog.Info("Background job started", zap.String("job", name), zap.Duration("interval", interval))
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.WriteLog.Info("Background job stopped", zap.String("job", name))
+			return
+		case <-ticker.C:
+			if err := job(); err != nil {
+				log.WriteLog.Error("Background job failed", zap.String("job", name), zap.Error(err))
+			}
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	jsonmodel "anomaly-go/model/json"
+	model "anomaly-go/model/postgres"
+	"anomaly-go/util/constants"
+)
+
+// unknownLabelColor is used for labels that are present in the data but missing from the taxonomy.
+const unknownLabelColor = "#9E9E9E"
+
+// GetLabels fetches the label taxonomy.
+func (s *Service) GetLabels() ([]jsonmodel.Label, error) {
+	labels, err := s.Repo.GetLabels()
+	if err != nil {
+		return nil, fmt.Errorf("500:could not fetch labels: %w", err)
+	}
+
+	jsonLabels := []jsonmodel.Label{}
+	for _, l := range labels {
+		jsonLabels = append(jsonLabels, jsonmodel.Label{
+			Name:        l.Name,
+			DisplayName: l.DisplayName,
+			Severity:    l.Severity,
+			Color:       l.Color,
+		})
+	}
+	return jsonLabels, nil
+}
+
+// CreateLabel adds a label to the taxonomy. Zero rows affected means it already exists.
+func (s *Service) CreateLabel(label jsonmodel.Label) (int64, error) {
+	rowsAffected, err := s.Repo.CreateLabel(toLabelModel(label))
+	if err != nil {
+		return 0, fmt.Errorf("500:database error on create label: %w", err)
+	}
+	return rowsAffected, nil
+}
+
+// UpdateLabel changes the display name, severity and color of a label.
+func (s *Service) UpdateLabel(label jsonmodel.Label) (int64, error) {
+	rowsAffected, err := s.Repo.UpdateLabel(toLabelModel(label))
+	if err != nil {
+		return 0, fmt.Errorf("500:database error on update label: %w", err)
+	}
+	return rowsAffected, nil
+}
+
+// DeleteLabel removes a label from the taxonomy. Transactions keep their raw label value.
+func (s *Service) DeleteLabel(name string) (int64, error) {
+	rowsAffected, err := s.Repo.DeleteLabel(normalizeLabel(name))
+	if err != nil {
+		return 0, fmt.Errorf("500:database error on delete label: %w", err)
+	}
+	return rowsAffected, nil
+}
+
+// buildLabelMetrics joins the per-label counts with the taxonomy. Labels that are
+// configured but absent from the data are reported with a zero count.
+func buildLabelMetrics(counts []model.LabelCount, labels []model.Label) map[string]jsonmodel.LabelMetric {
+	metrics := make(map[string]jsonmodel.LabelMetric, len(labels)+1)
+	for _, l := range labels {
+		metrics[l.Name] = jsonmodel.LabelMetric{
+			DisplayName: l.DisplayName,
+			Severity:    l.Severity,
+			Color:       l.Color,
+		}
+	}
+
+	for _, c := range counts {
+		key := constants.LabelNullKey
+		if c.Label.Valid {
+			key = c.Label.String
+		}
+		metric, ok := metrics[key]
+		if !ok {
+			metric = jsonmodel.LabelMetric{DisplayName: key, Color: unknownLabelColor}
+		}
+		metric.Count += c.Count
+		metrics[key] = metric
+	}
+	return metrics
+}
+
+func toLabelModel(label jsonmodel.Label) model.Label {
+	return model.Label{
+		Name:        normalizeLabel(label.Name),
+		DisplayName: strings.TrimSpace(label.DisplayName),
+		Severity:    label.Severity,
+		Color:       strings.ToUpper(label.Color),
+	}
+}
+
+// normalizeLabel brings a label to the lower-case form used for all label comparisons.
+func normalizeLabel(label string) string {
+	return strings.ToLower(strings.TrimSpace(label))
+}
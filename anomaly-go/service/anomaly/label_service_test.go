@@ -0,0 +1,80 @@
+package service
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+
+	jsonmodel "anomaly-go/model/json"
+	model "anomaly-go/model/postgres"
+	"anomaly-go/util/constants"
+)
+
+func TestBuildLabelMetrics(t *testing.T) {
+	labels := []model.Label{
+		{Name: "fraud", DisplayName: "Fraud", Severity: 3, Color: "#FF0000"},
+		{Name: "normal", DisplayName: "Normal", Color: "#00FF00"},
+	}
+	label := func(name string) sql.NullString { return sql.NullString{String: name, Valid: true} }
+
+	tests := []struct {
+		name   string
+		counts []model.LabelCount
+		want   map[string]jsonmodel.LabelMetric
+	}{
+		{"no data", nil, map[string]jsonmodel.LabelMetric{
+			"fraud":  {DisplayName: "Fraud", Severity: 3, Color: "#FF0000"},
+			"normal": {DisplayName: "Normal", Color: "#00FF00"},
+		}},
+		{"configured labels", []model.LabelCount{{Label: label("fraud"), Count: 4}, {Label: label("normal"), Count: 10}}, map[string]jsonmodel.LabelMetric{
+			"fraud":  {DisplayName: "Fraud", Severity: 3, Color: "#FF0000", Count: 4},
+			"normal": {DisplayName: "Normal", Color: "#00FF00", Count: 10},
+		}},
+		{"label missing from the taxonomy", []model.LabelCount{{Label: label("chargeback"), Count: 2}}, map[string]jsonmodel.LabelMetric{
+			"fraud":      {DisplayName: "Fraud", Severity: 3, Color: "#FF0000"},
+			"normal":     {DisplayName: "Normal", Color: "#00FF00"},
+			"chargeback": {DisplayName: "chargeback", Color: unknownLabelColor, Count: 2},
+		}},
+		{"unlabelled transactions", []model.LabelCount{{Count: 7}}, map[string]jsonmodel.LabelMetric{
+			"fraud":                {DisplayName: "Fraud", Severity: 3, Color: "#FF0000"},
+			"normal":               {DisplayName: "Normal", Color: "#00FF00"},
+			constants.LabelNullKey: {DisplayName: constants.LabelNullKey, Color: unknownLabelColor, Count: 7},
+		}},
+		{"repeated label", []model.LabelCount{{Label: label("fraud"), Count: 1}, {Label: label("fraud"), Count: 2}}, map[string]jsonmodel.LabelMetric{
+			"fraud":  {DisplayName: "Fraud", Severity: 3, Color: "#FF0000", Count: 3},
+			"normal": {DisplayName: "Normal", Color: "#00FF00"},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := buildLabelMetrics(tt.counts, labels); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("buildLabelMetrics() = %+v; want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToLabelModel(t *testing.T) {
+	got := toLabelModel(jsonmodel.Label{Name: "  Fraud ", DisplayName: " Fraud ", Severity: 3, Color: "#ff00aa"})
+	want := model.Label{Name: "fraud", DisplayName: "Fraud", Severity: 3, Color: "#FF00AA"}
+	if got != want {
+		t.Errorf("toLabelModel() = %+v; want %+v", got, want)
+	}
+}
+
+func TestNormalizeLabel(t *testing.T) {
+	tests := []struct {
+		label string
+		want  string
+	}{
+		{"fraud", "fraud"},
+		{"Fraud", "fraud"},
+		{"  SUSPICIOUS\t", "suspicious"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := normalizeLabel(tt.label); got != tt.want {
+			t.Errorf("normalizeLabel(%q) = %q; want %q", tt.label, got, tt.want)
+		}
+	}
+}
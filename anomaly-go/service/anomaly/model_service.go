@@ -0,0 +1,184 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"anomaly-go/log"
+	jsonmodel "anomaly-go/model/json"
+	model "anomaly-go/model/postgres"
+	"anomaly-go/service/scoring"
+
+	"go.uber.org/zap"
+)
+
+// ErrModelNotRunnable is returned when activating a model the service cannot run itself.
+var ErrModelNotRunnable = errors.New("model has no in-process implementation")
+
+// InitModelRegistry registers the built-in scorer, activates it when no model is
+// active yet and loads the active model as the scorer used on ingest.
+func (s *Service) InitModelRegistry() error {
+	builtin := scoring.Default().Model()
+	if _, err := s.Repo.CreateModelVersion(&model.ModelVersion{
+		Name:       builtin.Name,
+		Version:    builtin.Version,
+		Parameters: string(builtin.Parameters),
+	}); err != nil {
+		return fmt.Errorf("500:could not register built-in model: %w", err)
+	}
+
+	active, found, err := s.Repo.GetActiveModelVersion()
+	if err != nil {
+		return fmt.Errorf("500:could not fetch active model: %w", err)
+	}
+	if !found {
+		active, _, err = s.Repo.FindModelVersion(builtin.Name, builtin.Version)
+		if err != nil {
+			return fmt.Errorf("500:could not fetch built-in model: %w", err)
+		}
+		if _, err := s.Repo.ActivateModelVersion(active.ID); err != nil {
+			return fmt.Errorf("500:could not activate built-in model: %w", err)
+		}
+	}
+
+	scorer, err := scoring.New(active.Name, active.Version, []byte(active.Parameters))
+	if err != nil {
+		log.WriteLog.Error("Active model cannot be loaded, falling back to the built-in scorer",
+			zap.String("model", active.Name+"@"+active.Version), zap.Error(err))
+		scorer = scoring.Default()
+	}
+	s.setScorer(scorer)
+	return nil
+}
+
+// ActiveScorer returns the scorer used on ingest.
+func (s *Service) ActiveScorer() scoring.Scorer {
+	s.scorerMu.RLock()
+	defer s.scorerMu.RUnlock()
+	return s.scorer
+}
+
+func (s *Service) setScorer(scorer scoring.Scorer) {
+	s.scorerMu.Lock()
+	defer s.scorerMu.Unlock()
+	s.scorer = scorer
+	log.WriteLog.Info("Active scorer loaded", zap.String("model", scorer.Model().Key()))
+}
+
+// GetModels fetches the model registry.
+func (s *Service) GetModels() ([]jsonmodel.ModelVersion, error) {
+	versions, err := s.Repo.GetModelVersions()
+	if err != nil {
+		return nil, fmt.Errorf("500:could not fetch model registry: %w", err)
+	}
+
+	jsonVersions := []jsonmodel.ModelVersion{}
+	for _, v := range versions {
+		jsonVersions = append(jsonVersions, toModelVersionJSON(v))
+	}
+	return jsonVersions, nil
+}
+
+// ValidateModel checks the parameters of a model the service can run. Models of
+// other names are accepted as-is, as they only serve to trace external scores.
+func (s *Service) ValidateModel(req jsonmodel.ModelVersion) error {
+	if len(req.Parameters) > 0 && !json.Valid(req.Parameters) {
+		return fmt.Errorf("parameters must be a JSON object")
+	}
+	if !scoring.Buildable(req.Name) {
+		return nil
+	}
+	_, err := scoring.New(req.Name, req.Version, req.Parameters)
+	return err
+}
+
+// RegisterModel adds a model version to the registry. Zero rows affected means the
+// name/version pair already exists.
+func (s *Service) RegisterModel(req jsonmodel.ModelVersion) (uint, int64, error) {
+	version := model.ModelVersion{
+		Name:       strings.TrimSpace(req.Name),
+		Version:    strings.TrimSpace(req.Version),
+		Parameters: string(req.Parameters),
+	}
+	rowsAffected, err := s.Repo.CreateModelVersion(&version)
+	if err != nil {
+		return 0, 0, fmt.Errorf("500:database error on register model: %w", err)
+	}
+	return version.ID, rowsAffected, nil
+}
+
+// ActivateModel makes a registered model the scorer used on ingest. It returns
+// false when the model does not exist.
+func (s *Service) ActivateModel(id uint) (bool, error) {
+	version, found, err := s.Repo.GetModelVersion(id)
+	if err != nil {
+		return false, fmt.Errorf("500:could not fetch model: %w", err)
+	}
+	if !found {
+		return false, nil
+	}
+
+	scorer, err := scoring.New(version.Name, version.Version, []byte(version.Parameters))
+	if err != nil {
+		return true, fmt.Errorf("%w: %v", ErrModelNotRunnable, err)
+	}
+
+	if _, err := s.Repo.ActivateModelVersion(id); err != nil {
+		return true, fmt.Errorf("500:database error on activate model: %w", err)
+	}
+	s.setScorer(scorer)
+	return true, nil
+}
+
+// CompareModelVersions summarises the results of every model version among the
+// transactions matching the /fetchData filters.
+func (s *Service) CompareModelVersions(filter model.TransactionFilter) ([]jsonmodel.ModelVersionComparison, error) {
+	stats, err := s.Repo.CompareModelVersions(filter)
+	if err != nil {
+		log.WriteLog.Error("Failed to compare model versions", zap.Error(err))
+		return nil, fmt.Errorf("500:could not compare model versions: %w", err)
+	}
+
+	comparisons := []jsonmodel.ModelVersionComparison{}
+	for _, st := range stats {
+		c := jsonmodel.ModelVersionComparison{
+			Transactions:  st.Transactions,
+			Flagged:       st.Flagged,
+			FlagRate:      ratio(st.Flagged, st.Transactions),
+			AvgConfidence: st.AvgConfidence,
+			Quality: newQualityMetrics(model.ConfusionCounts{
+				TruePositives:  st.TruePositives,
+				FalsePositives: st.FalsePositives,
+				TrueNegatives:  st.TrueNegatives,
+				FalseNegatives: st.FalseNegatives,
+			}),
+		}
+		if st.ModelVersion.Valid {
+			c.ModelVersion = &st.ModelVersion.String
+		}
+		comparisons = append(comparisons, c)
+	}
+	return comparisons, nil
+}
+
+func toModelVersionJSON(v model.ModelVersion) jsonmodel.ModelVersion {
+	j := jsonmodel.ModelVersion{
+		ID:        v.ID,
+		Name:      v.Name,
+		Version:   v.Version,
+		Key:       scoring.ModelInfo{Name: v.Name, Version: v.Version}.Key(),
+		Active:    v.Active,
+		Runnable:  scoring.Buildable(v.Name),
+		CreatedAt: v.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+	if v.Parameters != "" {
+		j.Parameters = json.RawMessage(v.Parameters)
+	}
+	if v.ActivatedAt.Valid {
+		activatedAt := v.ActivatedAt.Time.Format("2006-01-02 15:04:05")
+		j.ActivatedAt = &activatedAt
+	}
+	return j
+}
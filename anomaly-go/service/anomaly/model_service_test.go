@@ -0,0 +1,66 @@
+package service
+
+import (
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	jsonmodel "anomaly-go/model/json"
+	model "anomaly-go/model/postgres"
+	"anomaly-go/service/scoring"
+)
+
+func TestValidateModel(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     jsonmodel.ModelVersion
+		wantErr bool
+	}{
+		{"built-in without parameters", jsonmodel.ModelVersion{Name: scoring.BaselineScorerName, Version: "2.0.0"}, false},
+		{"built-in with parameters", jsonmodel.ModelVersion{Name: scoring.BaselineScorerName, Version: "2.0.0", Parameters: json.RawMessage(`{"min_history": 5}`)}, false},
+		{"built-in with invalid parameters", jsonmodel.ModelVersion{Name: scoring.BaselineScorerName, Version: "2.0.0", Parameters: json.RawMessage(`{"amount_alpha": 0}`)}, true},
+		{"malformed parameters", jsonmodel.ModelVersion{Name: scoring.BaselineScorerName, Version: "2.0.0", Parameters: json.RawMessage(`{"min_history":`)}, true},
+		{"external model", jsonmodel.ModelVersion{Name: "isolation_forest", Version: "0.3", Parameters: json.RawMessage(`{"trees": 100}`)}, false},
+		{"external model with malformed parameters", jsonmodel.ModelVersion{Name: "isolation_forest", Version: "0.3", Parameters: json.RawMessage(`trees`)}, true},
+	}
+
+	s := &Service{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := s.ValidateModel(tt.req); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateModel() error = %v; wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestToModelVersionJSON(t *testing.T) {
+	created := time.Date(2024, 3, 4, 12, 0, 0, 0, time.UTC)
+	activated := created.Add(time.Hour)
+
+	got := toModelVersionJSON(model.ModelVersion{
+		ID:          2,
+		Name:        scoring.BaselineScorerName,
+		Version:     "1.0.0",
+		Parameters:  `{"min_history":20}`,
+		Active:      true,
+		ActivatedAt: sql.NullTime{Time: activated, Valid: true},
+		CreatedAt:   created,
+	})
+
+	if got.Key != "ewma_baseline@1.0.0" || !got.Runnable || !got.Active {
+		t.Errorf("toModelVersionJSON() = %+v; want a runnable active ewma_baseline@1.0.0", got)
+	}
+	if string(got.Parameters) != `{"min_history":20}` {
+		t.Errorf("toModelVersionJSON() parameters = %s; want the stored document", got.Parameters)
+	}
+	if got.CreatedAt != "2024-03-04 12:00:00" || got.ActivatedAt == nil || *got.ActivatedAt != "2024-03-04 13:00:00" {
+		t.Errorf("toModelVersionJSON() times = %q, %v; want 2024-03-04 12:00:00 and 13:00:00", got.CreatedAt, got.ActivatedAt)
+	}
+
+	external := toModelVersionJSON(model.ModelVersion{Name: "isolation_forest", Version: "0.3", CreatedAt: created})
+	if external.Runnable || external.Parameters != nil || external.ActivatedAt != nil {
+		t.Errorf("toModelVersionJSON() = %+v; want a never activated external model without parameters", external)
+	}
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"fmt"
+
+	"anomaly-go/log"
+	jsonmodel "anomaly-go/model/json"
+	model "anomaly-go/model/postgres"
+	"anomaly-go/service/peers"
+
+	"go.uber.org/zap"
+)
+
+// BenchmarkPeers places devices against their peer group, built from the
+// transactions matching the /fetchData filters. It returns the given device, or every
+// device flagged as outside its peer group when deviceID is 0. It returns false when
+// the device has no transactions in scope.
+func (s *Service) BenchmarkPeers(filter model.TransactionFilter, deviceID int64) (jsonmodel.PeerBenchmarkResponse, bool, error) {
+	activity, err := s.Repo.GetDeviceActivity(filter)
+	if err != nil {
+		log.WriteLog.Error("Failed to fetch device activity", zap.Error(err))
+		return jsonmodel.PeerBenchmarkResponse{}, false, fmt.Errorf("500:could not fetch device activity: %w", err)
+	}
+	scores, err := s.Repo.GetBatteryScores()
+	if err != nil {
+		log.WriteLog.Error("Failed to fetch battery scores", zap.Error(err))
+		return jsonmodel.PeerBenchmarkResponse{}, false, fmt.Errorf("500:could not fetch battery scores: %w", err)
+	}
+	batteryScores := make(map[int64]float64, len(scores))
+	for _, sc := range scores {
+		batteryScores[int64(sc.DeviceID)] = sc.DeviceBS
+	}
+
+	devices := make([]peers.Device, 0, len(activity))
+	for _, a := range activity {
+		d := peers.Device{
+			ID:           a.DeviceID,
+			Transactions: a.Transactions,
+			AnomalyRate:  ratio(a.Anomalies, a.Transactions),
+			AvgAmount:    a.AvgAmount,
+			MedianAmount: a.MedianAmount,
+			PeakHour:     a.PeakHour,
+		}
+		if bs, ok := batteryScores[a.DeviceID]; ok {
+			d.BatteryScore = &bs
+		}
+		devices = append(devices, d)
+	}
+
+	resp := jsonmodel.PeerBenchmarkResponse{Devices: []jsonmodel.PeerPlacement{}}
+	found := deviceID == 0
+	for _, p := range peers.Benchmark(devices) {
+		if deviceID != 0 && p.DeviceID != deviceID {
+			continue
+		}
+		if deviceID == 0 && !p.Outlier {
+			continue
+		}
+		found = true
+		resp.Devices = append(resp.Devices, toPeerPlacementJSON(p))
+	}
+	return resp, found, nil
+}
+
+func toPeerPlacementJSON(p peers.Placement) jsonmodel.PeerPlacement {
+	j := jsonmodel.PeerPlacement{
+		DeviceID: p.DeviceID,
+		Bands: jsonmodel.PeerBands{
+			Volume:      p.Bands.Volume,
+			TicketSize:  p.Bands.Ticket,
+			ActiveHours: p.Bands.Hours,
+		},
+		PeerGroup: p.PeerGroup,
+		Peers:     p.Peers,
+		Metrics:   make(map[string]jsonmodel.PeerMetric, len(p.Metrics)),
+		Outlier:   p.Outlier,
+	}
+	for name, m := range p.Metrics {
+		j.Metrics[name] = jsonmodel.PeerMetric{
+			Value:      m.Value,
+			Percentile: m.Percentile,
+			PeerMedian: m.PeerMedian,
+			RobustZ:    m.RobustZ,
+			Outlier:    m.Outlier,
+		}
+	}
+	return j
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"anomaly-go/log"
+	jsonmodel "anomaly-go/model/json"
+	model "anomaly-go/model/postgres"
+
+	"go.uber.org/zap"
+)
+
+// ruleReasonPrefix prefixes the reason codes derived from rule hits, e.g. RULE_VELOCITY.
+const ruleReasonPrefix = "RULE_"
+
+// GetTopReasons aggregates the reason codes of the transactions matching the /fetchData filters.
+func (s *Service) GetTopReasons(filter model.TransactionFilter, limit int) (jsonmodel.TopReasonsResponse, error) {
+	total, err := s.Repo.CountTransactions(filter)
+	if err != nil {
+		log.WriteLog.Error("Failed to count transactions", zap.Error(err))
+		return jsonmodel.TopReasonsResponse{}, fmt.Errorf("500:could not count transactions: %w", err)
+	}
+
+	counts, err := s.Repo.CountTopReasons(filter, limit)
+	if err != nil {
+		log.WriteLog.Error("Failed to count top reasons", zap.Error(err))
+		return jsonmodel.TopReasonsResponse{}, fmt.Errorf("500:could not fetch top reasons: %w", err)
+	}
+
+	reasons := []jsonmodel.TopReason{}
+	for _, c := range counts {
+		percent := 0.0
+		if total > 0 {
+			percent = (float64(c.Transactions) / float64(total)) * 100
+		}
+		reasons = append(reasons, jsonmodel.TopReason{
+			Code:         c.Code,
+			Transactions: c.Transactions,
+			Occurrences:  c.Occurrences,
+			Percent:      percent,
+		})
+	}
+
+	return jsonmodel.TopReasonsResponse{TotalTransactions: total, Reasons: reasons}, nil
+}
+
+// groupReasons indexes reason codes by transaction for the /fetchData response.
+func groupReasons(reasons []model.AnomalyReason) map[txnKey][]jsonmodel.Reason {
+	grouped := make(map[txnKey][]jsonmodel.Reason)
+	for _, r := range reasons {
+		key := txnKey{r.DeviceID, r.TransactionID}
+		grouped[key] = append(grouped[key], toReasonJSON(r))
+	}
+	return grouped
+}
+
+// ruleReasonModels turns rule hits into reason codes, so rules and the statistical
+// scorer explain transactions in the same place.
+func ruleReasonModels(hits []model.RuleHit) []model.AnomalyReason {
+	var reasons []model.AnomalyReason
+	for _, h := range hits {
+		reasons = append(reasons, model.AnomalyReason{
+			DeviceID:      h.DeviceID,
+			TransactionID: h.TransactionID,
+			Code:          ruleReasonPrefix + strings.ToUpper(h.RuleType),
+			Message:       fmt.Sprintf("Rule '%s': %s", h.RuleName, h.Message),
+			Feature:       h.Feature,
+			Value:         h.Value,
+			Baseline:      h.Limit,
+		})
+	}
+	return reasons
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+
+	model "anomaly-go/model/postgres"
+	"anomaly-go/service/rules"
+)
+
+func TestRuleReasonModels(t *testing.T) {
+	hits := []model.RuleHit{
+		{
+			DeviceID: 7, TransactionID: "T1", RuleName: "burst", RuleType: rules.TypeVelocity,
+			Message: "5 transactions from the device within 5m", Feature: rules.FeatureWindowCount, Value: 5, Limit: 3,
+		},
+		{
+			DeviceID: 7, TransactionID: "T1", RuleName: "spike", RuleType: rules.TypeAmountSpike,
+			Message: "Amount 90.00 is above 3x the 30d median of 20.00", Feature: rules.FeatureAmount, Value: 90, Limit: 60,
+		},
+	}
+
+	want := []model.AnomalyReason{
+		{
+			DeviceID: 7, TransactionID: "T1", Code: "RULE_VELOCITY",
+			Message: "Rule 'burst': 5 transactions from the device within 5m", Feature: rules.FeatureWindowCount, Value: 5, Baseline: 3,
+		},
+		{
+			DeviceID: 7, TransactionID: "T1", Code: "RULE_AMOUNT_SPIKE",
+			Message: "Rule 'spike': Amount 90.00 is above 3x the 30d median of 20.00", Feature: rules.FeatureAmount, Value: 90, Baseline: 60,
+		},
+	}
+
+	if got := ruleReasonModels(hits); !reflect.DeepEqual(got, want) {
+		t.Errorf("ruleReasonModels() = %+v; want %+v", got, want)
+	}
+	if got := ruleReasonModels(nil); len(got) != 0 {
+		t.Errorf("ruleReasonModels(nil) = %+v; want none", got)
+	}
+}
+
+func TestToReasonJSON(t *testing.T) {
+	r := model.AnomalyReason{
+		ID: 1, DeviceID: 7, TransactionID: "T1", Code: "AMOUNT_HIGH",
+		Message: "Amount is unusually high", Feature: "txn_amt", Value: 500, Baseline: 40,
+	}
+
+	got := toReasonJSON(r)
+	if got.Code != r.Code || got.Message != r.Message || got.Feature != r.Feature ||
+		got.Value != r.Value || got.Baseline != r.Baseline {
+		t.Errorf("toReasonJSON() = %+v; want the fields of %+v", got, r)
+	}
+}
+
+func TestGroupReasons(t *testing.T) {
+	reasons := []model.AnomalyReason{
+		{DeviceID: 1, TransactionID: "T1", Code: "AMOUNT_HIGH"},
+		{DeviceID: 2, TransactionID: "T1", Code: "BURST"},
+		{DeviceID: 1, TransactionID: "T1", Code: "RULE_VELOCITY"},
+		{DeviceID: 1, TransactionID: "T2", Code: "UNUSUAL_HOUR"},
+	}
+
+	got := groupReasons(reasons)
+
+	want := map[txnKey][]string{
+		{1, "T1"}: {"AMOUNT_HIGH", "RULE_VELOCITY"},
+		{2, "T1"}: {"BURST"},
+		{1, "T2"}: {"UNUSUAL_HOUR"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("groupReasons() has %d transactions; want %d", len(got), len(want))
+	}
+	for key, codes := range want {
+		var gotCodes []string
+		for _, r := range got[key] {
+			gotCodes = append(gotCodes, r.Code)
+		}
+		if !reflect.DeepEqual(gotCodes, codes) {
+			t.Errorf("groupReasons()[%v] = %v; want %v", key, gotCodes, codes)
+		}
+	}
+}
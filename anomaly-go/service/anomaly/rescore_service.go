@@ -0,0 +1,435 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"anomaly-go/log"
+	jsonmodel "anomaly-go/model/json"
+	model "anomaly-go/model/postgres"
+	"anomaly-go/service/scoring"
+
+	"go.uber.org/zap"
+)
+
+// Rescore job statuses.
+const (
+	RescoreStatusRunning   = "running"
+	RescoreStatusPaused    = "paused"
+	RescoreStatusCompleted = "completed"
+	RescoreStatusFailed    = "failed"
+)
+
+// Rescore targets: live replaces the scores shown to reviewers, shadow only fills the
+// shadow columns.
+const (
+	RescoreTargetLive   = "live"
+	RescoreTargetShadow = "shadow"
+)
+
+const defaultRescoreChunkSize = 500
+
+var (
+	// ErrRescoreRunning is returned when starting a job while another one runs.
+	ErrRescoreRunning = errors.New("a rescore job is already running")
+	// ErrRescoreNotResumable is returned when resuming a job that is not paused or failed.
+	ErrRescoreNotResumable = errors.New("only paused or failed rescore jobs can be resumed")
+	// ErrRescoreNotRunning is returned when pausing a job that is not running.
+	ErrRescoreNotRunning = errors.New("rescore job is not running")
+)
+
+// ValidateRescoreRequest checks the time range of a new rescore job.
+func (s *Service) ValidateRescoreRequest(req jsonmodel.StartRescoreRequest) error {
+	from, to, err := parseTimeRange(req.From, req.To)
+	if err != nil {
+		return err
+	}
+	if from.Valid && to.Valid && !from.Time.Before(to.Time) {
+		return fmt.Errorf("'from' must be before 'to'")
+	}
+	return nil
+}
+
+// StartRescore starts a job rescoring the stored transactions with the active model,
+// or resumes the paused or failed job given by req.JobID. The job replays history in
+// time order to rebuild its own device baselines, so rows before 'from' warm the
+// baselines up without being rewritten. It returns false when the job to resume does
+// not exist.
+func (s *Service) StartRescore(req jsonmodel.StartRescoreRequest) (jsonmodel.RescoreJob, bool, error) {
+	s.rescoreMu.Lock()
+	defer s.rescoreMu.Unlock()
+
+	if s.rescoreCancel != nil {
+		return jsonmodel.RescoreJob{}, true, ErrRescoreRunning
+	}
+
+	var job model.RescoreJob
+	var scorer scoring.Scorer
+	if req.JobID != 0 {
+		var found bool
+		var err error
+		job, found, err = s.Repo.GetRescoreJob(req.JobID)
+		if err != nil {
+			return jsonmodel.RescoreJob{}, false, fmt.Errorf("500:could not fetch rescore job: %w", err)
+		}
+		if !found {
+			return jsonmodel.RescoreJob{}, false, nil
+		}
+		if job.Status != RescoreStatusPaused && job.Status != RescoreStatusFailed {
+			return jsonmodel.RescoreJob{}, true, ErrRescoreNotResumable
+		}
+		if scorer, err = s.rescoreScorer(job); err != nil {
+			return jsonmodel.RescoreJob{}, true, err
+		}
+		job.Status = RescoreStatusRunning
+		job.Error = ""
+		job.FinishedAt = sql.NullTime{}
+		if err := s.Repo.UpdateRescoreJobStatus(&job); err != nil {
+			return jsonmodel.RescoreJob{}, true, fmt.Errorf("500:could not update rescore job: %w", err)
+		}
+	} else {
+		threshold, err := s.currentThreshold()
+		if err != nil {
+			return jsonmodel.RescoreJob{}, true, err
+		}
+		from, to, err := parseTimeRange(req.From, req.To)
+		if err != nil {
+			return jsonmodel.RescoreJob{}, true, err
+		}
+
+		scorer = s.ActiveScorer()
+		info := scorer.Model()
+		job = model.RescoreJob{
+			Status:       RescoreStatusRunning,
+			Target:       req.Target,
+			ModelName:    info.Name,
+			ModelVersion: info.Version,
+			Threshold:    threshold,
+			RangeFrom:    from,
+			RangeTo:      to,
+			ChunkSize:    req.ChunkSize,
+		}
+		if job.Target == "" {
+			job.Target = RescoreTargetLive
+		}
+		if job.ChunkSize == 0 {
+			job.ChunkSize = defaultRescoreChunkSize
+		}
+		if err := s.Repo.CreateRescoreJob(&job); err != nil {
+			return jsonmodel.RescoreJob{}, true, fmt.Errorf("500:could not create rescore job: %w", err)
+		}
+	}
+
+	s.runRescore(job, scorer)
+	log.WriteLog.Info("Rescore job started", zap.Uint("id", job.ID), zap.String("target", job.Target),
+		zap.String("model", scorer.Model().Key()))
+	return toRescoreJobJSON(job), true, nil
+}
+
+// PauseRescore asks the running job to stop after its current chunk. It returns false
+// when the job does not exist.
+func (s *Service) PauseRescore(id uint) (bool, error) {
+	s.rescoreMu.Lock()
+	defer s.rescoreMu.Unlock()
+
+	if s.rescoreCancel == nil || s.rescoreJobID != id {
+		_, found, err := s.Repo.GetRescoreJob(id)
+		if err != nil {
+			return false, fmt.Errorf("500:could not fetch rescore job: %w", err)
+		}
+		if !found {
+			return false, nil
+		}
+		return true, ErrRescoreNotRunning
+	}
+
+	s.rescorePause = true
+	s.rescoreCancel()
+	return true, nil
+}
+
+// GetRescoreStatus reports the progress of a rescore job, or of the latest job when
+// id is 0.
+func (s *Service) GetRescoreStatus(id uint) (jsonmodel.RescoreJob, bool, error) {
+	var job model.RescoreJob
+	var found bool
+	var err error
+	if id == 0 {
+		job, found, err = s.Repo.GetLatestRescoreJob()
+	} else {
+		job, found, err = s.Repo.GetRescoreJob(id)
+	}
+	if err != nil {
+		return jsonmodel.RescoreJob{}, false, fmt.Errorf("500:could not fetch rescore job: %w", err)
+	}
+	if !found {
+		return jsonmodel.RescoreJob{}, false, nil
+	}
+
+	status := toRescoreJobJSON(job)
+	if job.Status != RescoreStatusCompleted {
+		remaining, err := s.Repo.CountRescoreRemaining(job)
+		if err != nil {
+			return jsonmodel.RescoreJob{}, true, fmt.Errorf("500:could not count remaining transactions: %w", err)
+		}
+		status.Remaining = remaining
+	}
+	return status, true, nil
+}
+
+// resumeInterruptedRescore restarts the job that was still running when the service
+// last stopped.
+func (s *Service) resumeInterruptedRescore() error {
+	jobs, err := s.Repo.GetRescoreJobsByStatus(RescoreStatusRunning)
+	if err != nil {
+		return fmt.Errorf("500:could not fetch rescore jobs: %w", err)
+	}
+	if len(jobs) == 0 {
+		return nil
+	}
+
+	job := jobs[0]
+	scorer, err := s.rescoreScorer(job)
+	if err != nil {
+		s.finishRescore(&job, RescoreStatusFailed, err)
+		return err
+	}
+
+	s.rescoreMu.Lock()
+	defer s.rescoreMu.Unlock()
+	s.runRescore(job, scorer)
+	log.WriteLog.Info("Rescore job resumed", zap.Uint("id", job.ID))
+	return nil
+}
+
+// rescoreScorer rebuilds the model a job was started with.
+func (s *Service) rescoreScorer(job model.RescoreJob) (scoring.Scorer, error) {
+	version, found, err := s.Repo.FindModelVersion(job.ModelName, job.ModelVersion)
+	if err != nil {
+		return nil, fmt.Errorf("500:could not fetch model: %w", err)
+	}
+	if !found {
+		return nil, fmt.Errorf("%w: %s@%s is no longer registered", ErrModelNotRunnable, job.ModelName, job.ModelVersion)
+	}
+	scorer, err := scoring.New(version.Name, version.Version, []byte(version.Parameters))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrModelNotRunnable, err)
+	}
+	return scorer, nil
+}
+
+// runRescore processes a job in the background. The caller holds rescoreMu. A paused
+// job is marked paused; a job stopped by shutdown stays running so it resumes on the
+// next start.
+func (s *Service) runRescore(job model.RescoreJob, scorer scoring.Scorer) {
+	ctx, cancel := context.WithCancel(s.jobsCtx)
+	s.rescoreJobID = job.ID
+	s.rescoreCancel = cancel
+	s.rescorePause = false
+
+	go func() {
+		err := s.rescoreLoop(ctx, &job, scorer)
+
+		s.rescoreMu.Lock()
+		paused := s.rescorePause
+		s.rescoreCancel = nil
+		s.rescoreJobID = 0
+		s.rescoreMu.Unlock()
+		cancel()
+
+		switch {
+		case err != nil:
+			log.WriteLog.Error("Rescore job failed", zap.Uint("id", job.ID), zap.Error(err))
+			s.finishRescore(&job, RescoreStatusFailed, err)
+		case ctx.Err() == nil:
+			log.WriteLog.Info("Rescore job completed", zap.Uint("id", job.ID),
+				zap.Int64("processed", job.Processed), zap.Int64("rescored", job.Rescored))
+			s.finishRescore(&job, RescoreStatusCompleted, nil)
+		case paused:
+			log.WriteLog.Info("Rescore job paused", zap.Uint("id", job.ID), zap.Int64("processed", job.Processed))
+			s.finishRescore(&job, RescoreStatusPaused, nil)
+		default:
+			log.WriteLog.Info("Rescore job interrupted by shutdown", zap.Uint("id", job.ID))
+		}
+	}()
+}
+
+// rescoreLoop processes chunks until the job is done or ctx is cancelled.
+func (s *Service) rescoreLoop(ctx context.Context, job *model.RescoreJob, scorer scoring.Scorer) error {
+	for ctx.Err() == nil {
+		txns, err := s.Repo.FetchRescoreChunk(*job)
+		if err != nil {
+			return fmt.Errorf("could not fetch transactions: %w", err)
+		}
+		if len(txns) == 0 {
+			return nil
+		}
+		if err := s.rescoreChunk(job, scorer, txns); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rescoreChunk scores one chunk against the job baselines and saves the scores
+// together with the new checkpoint.
+func (s *Service) rescoreChunk(job *model.RescoreJob, scorer scoring.Scorer, txns []model.Transaction) error {
+	deviceSet := map[int64]bool{}
+	txnIDs := make([]string, 0, len(txns))
+	for _, t := range txns {
+		deviceSet[t.DeviceID] = true
+		txnIDs = append(txnIDs, t.TransactionID)
+	}
+	deviceIDs := make([]int64, 0, len(deviceSet))
+	for id := range deviceSet {
+		deviceIDs = append(deviceIDs, id)
+	}
+
+	stored, err := s.Repo.GetRescoreBaselines(job.ID, deviceIDs)
+	if err != nil {
+		return fmt.Errorf("could not fetch job baselines: %w", err)
+	}
+	baselines := make(map[int64]*scoring.Baseline, len(deviceIDs))
+	for _, b := range stored {
+		baselines[b.DeviceID] = fromBaselineModel(b.DeviceBaseline)
+	}
+	// Seasonal profiles are not versioned, so every job scores against the current ones.
+	seasonal, err := s.loadSeasonality(deviceIDs)
+	if err != nil {
+		return err
+	}
+	for id, b := range baselines {
+		b.Seasonality = seasonal[id]
+	}
+	ruleHits, err := s.Repo.GetRuleHitTransactionIDs(txnIDs)
+	if err != nil {
+		return fmt.Errorf("could not fetch rule hits: %w", err)
+	}
+
+	modelVersion := scorer.Model().Key()
+	var scores []model.RescoreScore
+	for _, t := range txns {
+		baseline, ok := baselines[t.DeviceID]
+		if !ok {
+			baseline = scoring.NewBaseline(t.DeviceID)
+			baselines[t.DeviceID] = baseline
+			baseline.Seasonality = seasonal[t.DeviceID]
+		}
+		txn := scoring.Transaction{DeviceID: t.DeviceID, ID: t.TransactionID, Time: t.TransactionTime, Amount: t.TransactionAmount}
+		job.Processed++
+
+		inRange := !job.RangeFrom.Valid || !t.TransactionTime.Before(job.RangeFrom.Time)
+		reviewed := t.Review.Valid && t.Review.String != ""
+		switch {
+		case !inRange:
+		case reviewed && job.Target == RescoreTargetLive:
+			job.SkippedReviewed++
+		default:
+			result := scorer.Score(baseline, txn)
+			scores = append(scores, model.RescoreScore{
+				DeviceID:      t.DeviceID,
+				TransactionID: t.TransactionID,
+				Confidence:    result.Confidence,
+				Label:         scoreLabel(result.Confidence, job.Threshold, ruleHits[t.DeviceID][t.TransactionID]),
+				ModelVersion:  modelVersion,
+				Reasons:       toReasonModels(txn, result.Reasons),
+			})
+		}
+		scorer.Update(baseline, txn)
+	}
+
+	last := txns[len(txns)-1]
+	job.LastTxnTime = sql.NullTime{Time: last.TransactionTime, Valid: true}
+	job.LastDeviceID = last.DeviceID
+	job.LastTxnID = last.TransactionID
+
+	jobBaselines := make([]model.RescoreBaseline, 0, len(deviceIDs))
+	for _, id := range deviceIDs {
+		jobBaselines = append(jobBaselines, model.RescoreBaseline{JobID: job.ID, DeviceBaseline: toBaselineModel(baselines[id])})
+	}
+
+	keptReasons := []string{ruleReasonPrefix + "%", ReasonDuplicate}
+	if _, err := s.Repo.SaveRescoreChunk(job, scores, job.Target == RescoreTargetShadow, jobBaselines, keptReasons); err != nil {
+		return fmt.Errorf("could not save rescored chunk: %w", err)
+	}
+	return nil
+}
+
+// finishRescore records the final status of a job and drops its baselines once done.
+func (s *Service) finishRescore(job *model.RescoreJob, status string, jobErr error) {
+	job.Status = status
+	if jobErr != nil {
+		job.Error = jobErr.Error()
+	}
+	if status == RescoreStatusCompleted || status == RescoreStatusFailed {
+		job.FinishedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	}
+	if err := s.Repo.UpdateRescoreJobStatus(job); err != nil {
+		log.WriteLog.Error("Failed to update rescore job status", zap.Uint("id", job.ID), zap.Error(err))
+	}
+	if status == RescoreStatusCompleted {
+		if err := s.Repo.DeleteRescoreBaselines(job.ID); err != nil {
+			log.WriteLog.Error("Failed to delete rescore baselines", zap.Uint("id", job.ID), zap.Error(err))
+		}
+	}
+}
+
+// parseTimeRange parses optional 'from' and 'to' bounds, each left invalid when empty.
+func parseTimeRange(fromStr, toStr string) (sql.NullTime, sql.NullTime, error) {
+	var from, to sql.NullTime
+	if fromStr != "" {
+		ts, err := parseTimestamp(fromStr)
+		if err != nil {
+			return from, to, fmt.Errorf("'from' must be 'YYYY-MM-DD HH:MM:SS' or RFC3339")
+		}
+		from = sql.NullTime{Time: ts, Valid: true}
+	}
+	if toStr != "" {
+		ts, err := parseTimestamp(toStr)
+		if err != nil {
+			return from, to, fmt.Errorf("'to' must be 'YYYY-MM-DD HH:MM:SS' or RFC3339")
+		}
+		to = sql.NullTime{Time: ts, Valid: true}
+	}
+	return from, to, nil
+}
+
+func toRescoreJobJSON(job model.RescoreJob) jsonmodel.RescoreJob {
+	j := jsonmodel.RescoreJob{
+		ID:              job.ID,
+		Status:          job.Status,
+		Target:          job.Target,
+		ModelVersion:    scoring.ModelInfo{Name: job.ModelName, Version: job.ModelVersion}.Key(),
+		Threshold:       job.Threshold,
+		ChunkSize:       job.ChunkSize,
+		Processed:       job.Processed,
+		Rescored:        job.Rescored,
+		SkippedReviewed: job.SkippedReviewed,
+		Error:           job.Error,
+		CreatedAt:       job.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:       job.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+	if job.RangeFrom.Valid {
+		from := job.RangeFrom.Time.Format("2006-01-02 15:04:05")
+		j.RangeFrom = &from
+	}
+	if job.RangeTo.Valid {
+		to := job.RangeTo.Time.Format("2006-01-02 15:04:05")
+		j.RangeTo = &to
+	}
+	if job.LastTxnTime.Valid {
+		j.Checkpoint = &jsonmodel.RescoreCheckpoint{
+			TransactionTime: job.LastTxnTime.Time.Format("2006-01-02 15:04:05"),
+			DeviceID:        job.LastDeviceID,
+			TransactionID:   job.LastTxnID,
+		}
+	}
+	if job.FinishedAt.Valid {
+		finishedAt := job.FinishedAt.Time.Format("2006-01-02 15:04:05")
+		j.FinishedAt = &finishedAt
+	}
+	return j
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	jsonmodel "anomaly-go/model/json"
+	model "anomaly-go/model/postgres"
+	"anomaly-go/service/scoring"
+)
+
+func TestParseTimeRange(t *testing.T) {
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.Local)
+	to := time.Date(2026, 3, 2, 0, 0, 0, 0, time.Local)
+	tests := []struct {
+		name     string
+		from, to string
+		wantFrom bool
+		wantTo   bool
+		wantErr  bool
+	}{
+		{"no bounds", "", "", false, false, false},
+		{"from only", "2026-03-01 00:00:00", "", true, false, false},
+		{"to only", "", "2026-03-02T00:00:00" + to.Format("Z07:00"), false, true, false},
+		{"both", "2026-03-01 00:00:00", "2026-03-02 00:00:00", true, true, false},
+		{"invalid from", "yesterday", "", false, false, true},
+		{"invalid to", "2026-03-01 00:00:00", "2026-03-02", true, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotFrom, gotTo, err := parseTimeRange(tt.from, tt.to)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseTimeRange(%q, %q) error = %v; want error %v", tt.from, tt.to, err, tt.wantErr)
+			}
+			if gotFrom.Valid != tt.wantFrom || (gotFrom.Valid && !gotFrom.Time.Equal(from)) {
+				t.Errorf("parseTimeRange(%q, %q) from = %+v; want valid %v at %v", tt.from, tt.to, gotFrom, tt.wantFrom, from)
+			}
+			if gotTo.Valid != tt.wantTo || (gotTo.Valid && !gotTo.Time.Equal(to)) {
+				t.Errorf("parseTimeRange(%q, %q) to = %+v; want valid %v at %v", tt.from, tt.to, gotTo, tt.wantTo, to)
+			}
+		})
+	}
+}
+
+func TestValidateRescoreRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     jsonmodel.StartRescoreRequest
+		wantErr bool
+	}{
+		{"whole history", jsonmodel.StartRescoreRequest{}, false},
+		{"ordered range", jsonmodel.StartRescoreRequest{From: "2026-03-01 00:00:00", To: "2026-03-02 00:00:00"}, false},
+		{"empty range", jsonmodel.StartRescoreRequest{From: "2026-03-01 00:00:00", To: "2026-03-01 00:00:00"}, true},
+		{"reversed range", jsonmodel.StartRescoreRequest{From: "2026-03-02 00:00:00", To: "2026-03-01 00:00:00"}, true},
+		{"invalid bound", jsonmodel.StartRescoreRequest{From: "last week"}, true},
+	}
+
+	s := &Service{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := s.ValidateRescoreRequest(tt.req); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateRescoreRequest() error = %v; wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestToRescoreJobJSON(t *testing.T) {
+	created := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	job := model.RescoreJob{
+		ID:           4,
+		Status:       RescoreStatusPaused,
+		Target:       RescoreTargetShadow,
+		ModelName:    scoring.BaselineScorerName,
+		ModelVersion: "1.0.0",
+		RangeFrom:    sql.NullTime{Time: created.Add(-24 * time.Hour), Valid: true},
+		LastTxnTime:  sql.NullTime{Time: created.Add(-time.Hour), Valid: true},
+		LastDeviceID: 42,
+		LastTxnID:    "T9",
+		CreatedAt:    created,
+		UpdatedAt:    created,
+	}
+
+	got := toRescoreJobJSON(job)
+	if got.ModelVersion != "ewma_baseline@1.0.0" {
+		t.Errorf("toRescoreJobJSON() model version = %q; want %q", got.ModelVersion, "ewma_baseline@1.0.0")
+	}
+	if got.RangeFrom == nil || *got.RangeFrom != "2026-02-28 08:00:00" || got.RangeTo != nil {
+		t.Errorf("toRescoreJobJSON() range = %v, %v; want from 2026-02-28 08:00:00 and no end", got.RangeFrom, got.RangeTo)
+	}
+	want := jsonmodel.RescoreCheckpoint{TransactionTime: "2026-03-01 07:00:00", DeviceID: 42, TransactionID: "T9"}
+	if got.Checkpoint == nil || *got.Checkpoint != want {
+		t.Errorf("toRescoreJobJSON() checkpoint = %+v; want %+v", got.Checkpoint, want)
+	}
+	if got.FinishedAt != nil {
+		t.Errorf("toRescoreJobJSON() finished at = %v; want nil for an unfinished job", *got.FinishedAt)
+	}
+}
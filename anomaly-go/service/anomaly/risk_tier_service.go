@@ -0,0 +1,219 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"anomaly-go/log"
+	jsonmodel "anomaly-go/model/json"
+	model "anomaly-go/model/postgres"
+	"anomaly-go/service/battery"
+
+	"go.uber.org/zap"
+)
+
+var (
+	// ErrUnknownRiskTier is returned when filtering on a tier that is not configured.
+	ErrUnknownRiskTier = errors.New("unknown risk tier")
+	// ErrRiskTierBoundaryTaken is returned when a tier would share its boundary with
+	// another one.
+	ErrRiskTierBoundaryTaken = errors.New("another risk tier already uses this max_score")
+)
+
+// GetRiskTiers fetches the battery risk tiers, lowest boundary first.
+func (s *Service) GetRiskTiers() ([]jsonmodel.RiskTier, error) {
+	tiers, err := s.Repo.GetRiskTiers()
+	if err != nil {
+		return nil, fmt.Errorf("500:could not fetch risk tiers: %w", err)
+	}
+
+	jsonTiers := []jsonmodel.RiskTier{}
+	for _, t := range tiers {
+		jsonTiers = append(jsonTiers, jsonmodel.RiskTier{
+			Name:        t.Name,
+			DisplayName: t.DisplayName,
+			MaxScore:    nullFloatPtr(t.MaxScore),
+			AtRisk:      t.AtRisk,
+			Color:       t.Color,
+		})
+	}
+	return jsonTiers, nil
+}
+
+// CreateRiskTier adds a risk tier. Zero rows affected means the name is taken.
+func (s *Service) CreateRiskTier(tier jsonmodel.RiskTier) (int64, error) {
+	m := toRiskTierModel(tier)
+	if err := s.checkRiskTierBoundary(m); err != nil {
+		return 0, err
+	}
+	rowsAffected, err := s.Repo.CreateRiskTier(m)
+	if err != nil {
+		return 0, fmt.Errorf("500:database error on create risk tier: %w", err)
+	}
+	return rowsAffected, nil
+}
+
+// UpdateRiskTier changes the boundary, at-risk flag and display attributes of a tier.
+func (s *Service) UpdateRiskTier(tier jsonmodel.RiskTier) (int64, error) {
+	m := toRiskTierModel(tier)
+	if err := s.checkRiskTierBoundary(m); err != nil {
+		return 0, err
+	}
+	rowsAffected, err := s.Repo.UpdateRiskTier(m)
+	if err != nil {
+		return 0, fmt.Errorf("500:database error on update risk tier: %w", err)
+	}
+	return rowsAffected, nil
+}
+
+// DeleteRiskTier removes a risk tier. Its devices move to the next tier up.
+func (s *Service) DeleteRiskTier(name string) (int64, error) {
+	rowsAffected, err := s.Repo.DeleteRiskTier(normalizeLabel(name))
+	if err != nil {
+		return 0, fmt.Errorf("500:database error on delete risk tier: %w", err)
+	}
+	return rowsAffected, nil
+}
+
+// checkRiskTierBoundary rejects a tier sharing its boundary with another tier.
+func (s *Service) checkRiskTierBoundary(tier model.BatteryRiskTier) error {
+	stored, err := s.Repo.GetRiskTiers()
+	if err != nil {
+		return fmt.Errorf("500:could not fetch risk tiers: %w", err)
+	}
+	tiers := []battery.Tier{toBatteryTier(tier)}
+	for _, t := range stored {
+		if t.Name != tier.Name {
+			tiers = append(tiers, toBatteryTier(t))
+		}
+	}
+	if err := battery.ValidateTiers(tiers); err != nil {
+		return fmt.Errorf("%w: %v", ErrRiskTierBoundaryTaken, err)
+	}
+	return nil
+}
+
+// loadRiskTiers reads the configured tiers, sorted for battery.Classify, along with
+// their stored rows keyed by name.
+func (s *Service) loadRiskTiers() ([]battery.Tier, map[string]model.BatteryRiskTier, error) {
+	stored, err := s.Repo.GetRiskTiers()
+	if err != nil {
+		log.WriteLog.Error("Failed to fetch risk tiers", zap.Error(err))
+		return nil, nil, fmt.Errorf("500:could not fetch risk tiers: %w", err)
+	}
+	tiers := make([]battery.Tier, 0, len(stored))
+	byName := make(map[string]model.BatteryRiskTier, len(stored))
+	for _, t := range stored {
+		tiers = append(tiers, toBatteryTier(t))
+		byName[t.Name] = t
+	}
+	battery.SortTiers(tiers)
+	return tiers, byName, nil
+}
+
+// GetAtRiskKPIs counts the devices of each risk tier and lists the devices of one
+// tier. An empty tier lists the devices of every at-risk tier, and "all" every
+// scored device.
+func (s *Service) GetAtRiskKPIs(deviceIDStr, searchTerm, tier string) (jsonmodel.AtRiskResponse, error) {
+	tiers, byName, err := s.loadRiskTiers()
+	if err != nil {
+		return jsonmodel.AtRiskResponse{}, err
+	}
+	tier = normalizeLabel(tier)
+	if _, ok := byName[tier]; !ok && tier != "" && tier != "all" && tier != battery.TierUnassigned {
+		return jsonmodel.AtRiskResponse{}, fmt.Errorf("%w %q", ErrUnknownRiskTier, tier)
+	}
+
+	totalDevices, devices, err := s.Repo.GetHealthDeviceScores(deviceIDStr, searchTerm)
+	if err != nil {
+		log.WriteLog.Error("Failed to fetch at-risk KPIs", zap.Error(err))
+		return jsonmodel.AtRiskResponse{}, fmt.Errorf("500:could not fetch at-risk KPIs: %w", err)
+	}
+
+	now := time.Now()
+	counts := map[string]int{}
+	riskCount := 0
+	jsonDevices := []jsonmodel.AtRiskDevice{}
+	for _, d := range devices {
+		t, _ := battery.Classify(tiers, d.DeviceBS)
+		counts[t.Name]++
+		if t.AtRisk {
+			riskCount++
+		}
+		if tier == "all" || tier == t.Name || (tier == "" && t.AtRisk) {
+			j := jsonmodel.AtRiskDevice{
+				DeviceID: d.DeviceID,
+				DeviceBS: d.DeviceBS,
+				Tier:     t.Name,
+			}
+			j.ScoreComputedAt, j.ScoreAgeHours = scoreAge(d.ComputedAt, now)
+			jsonDevices = append(jsonDevices, j)
+		}
+	}
+
+	kpi := jsonmodel.AtRiskKPI{
+		TotalDevices:  totalDevices,
+		AtRisk:        riskCount,
+		AtRiskPercent: percentOf(riskCount, totalDevices),
+		Tiers:         make([]jsonmodel.RiskTierCount, 0, len(tiers)+1),
+	}
+	for _, t := range tiers {
+		stored := byName[t.Name]
+		kpi.Tiers = append(kpi.Tiers, jsonmodel.RiskTierCount{
+			Name:        t.Name,
+			DisplayName: stored.DisplayName,
+			MaxScore:    t.MaxScore,
+			AtRisk:      t.AtRisk,
+			Color:       stored.Color,
+			Count:       counts[t.Name],
+			Percent:     percentOf(counts[t.Name], totalDevices),
+		})
+	}
+	if n := counts[battery.TierUnassigned]; n > 0 {
+		kpi.Tiers = append(kpi.Tiers, jsonmodel.RiskTierCount{
+			Name:        battery.TierUnassigned,
+			DisplayName: "Unassigned",
+			Color:       unknownLabelColor,
+			Count:       n,
+			Percent:     percentOf(n, totalDevices),
+		})
+	}
+
+	return jsonmodel.AtRiskResponse{KPI: kpi, Devices: jsonDevices}, nil
+}
+
+// percentOf returns part as a percentage of total, 0 for an empty total.
+func percentOf(part, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(part) / float64(total) * 100
+}
+
+func nullFloatPtr(v sql.NullFloat64) *float64 {
+	if !v.Valid {
+		return nil
+	}
+	f := v.Float64
+	return &f
+}
+
+func toBatteryTier(t model.BatteryRiskTier) battery.Tier {
+	return battery.Tier{Name: t.Name, MaxScore: nullFloatPtr(t.MaxScore), AtRisk: t.AtRisk}
+}
+
+func toRiskTierModel(tier jsonmodel.RiskTier) model.BatteryRiskTier {
+	m := model.BatteryRiskTier{
+		Name:        normalizeLabel(tier.Name),
+		DisplayName: strings.TrimSpace(tier.DisplayName),
+		AtRisk:      tier.AtRisk,
+		Color:       strings.ToUpper(tier.Color),
+	}
+	if tier.MaxScore != nil {
+		m.MaxScore = sql.NullFloat64{Float64: *tier.MaxScore, Valid: true}
+	}
+	return m
+}
@@ -0,0 +1,172 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"anomaly-go/log"
+	jsonmodel "anomaly-go/model/json"
+	model "anomaly-go/model/postgres"
+	"anomaly-go/service/rules"
+
+	"go.uber.org/zap"
+)
+
+// ReloadRules compiles the enabled rules from the database and swaps them into the
+// rule engine. Rules that fail to compile are skipped and logged, so one bad rule
+// does not disable the others.
+func (s *Service) ReloadRules() error {
+	stored, err := s.Repo.GetFraudRules(true)
+	if err != nil {
+		return fmt.Errorf("500:could not fetch fraud rules: %w", err)
+	}
+
+	var compiled []rules.Rule
+	for _, r := range stored {
+		rule, err := rules.Compile(r.ID, r.Name, r.Definition)
+		if err != nil {
+			log.WriteLog.Error("Skipping invalid fraud rule", zap.String("rule", r.Name), zap.Error(err))
+			continue
+		}
+		compiled = append(compiled, rule)
+	}
+
+	s.Rules.Load(compiled)
+	log.WriteLog.Debug("Fraud rules reloaded", zap.Int("active", len(compiled)))
+	return nil
+}
+
+// ValidateRule checks that a rule definition compiles.
+func (s *Service) ValidateRule(name, definition string) error {
+	_, err := rules.Compile(0, name, definition)
+	return err
+}
+
+// GetRules fetches every stored fraud rule, enabled or not.
+func (s *Service) GetRules() ([]jsonmodel.FraudRule, error) {
+	stored, err := s.Repo.GetFraudRules(false)
+	if err != nil {
+		return nil, fmt.Errorf("500:could not fetch fraud rules: %w", err)
+	}
+
+	jsonRules := []jsonmodel.FraudRule{}
+	for _, r := range stored {
+		enabled := r.Enabled
+		jsonRules = append(jsonRules, jsonmodel.FraudRule{
+			ID:         r.ID,
+			Name:       r.Name,
+			Definition: r.Definition,
+			Enabled:    &enabled,
+			CreatedAt:  r.CreatedAt.Format("2006-01-02 15:04:05"),
+			UpdatedAt:  r.UpdatedAt.Format("2006-01-02 15:04:05"),
+		})
+	}
+	return jsonRules, nil
+}
+
+// CreateRule stores a new rule and reloads the engine. Zero rows affected means the
+// name is already taken.
+func (s *Service) CreateRule(req jsonmodel.FraudRule) (uint, int64, error) {
+	rule := toRuleModel(req)
+	rowsAffected, err := s.Repo.CreateFraudRule(&rule)
+	if err != nil {
+		return 0, 0, fmt.Errorf("500:database error on create rule: %w", err)
+	}
+	return rule.ID, rowsAffected, s.reloadAfterChange(rowsAffected)
+}
+
+// UpdateRule changes a rule and reloads the engine.
+func (s *Service) UpdateRule(req jsonmodel.FraudRule) (int64, error) {
+	rowsAffected, err := s.Repo.UpdateFraudRule(toRuleModel(req))
+	if err != nil {
+		return 0, fmt.Errorf("500:database error on update rule: %w", err)
+	}
+	return rowsAffected, s.reloadAfterChange(rowsAffected)
+}
+
+// DeleteRule removes a rule and reloads the engine.
+func (s *Service) DeleteRule(id uint) (int64, error) {
+	rowsAffected, err := s.Repo.DeleteFraudRule(id)
+	if err != nil {
+		return 0, fmt.Errorf("500:database error on delete rule: %w", err)
+	}
+	return rowsAffected, s.reloadAfterChange(rowsAffected)
+}
+
+// reloadAfterChange applies an admin change immediately instead of waiting for the
+// next periodic reload.
+func (s *Service) reloadAfterChange(rowsAffected int64) error {
+	if rowsAffected == 0 {
+		return nil
+	}
+	return s.ReloadRules()
+}
+
+// evaluateRules runs the active rules against an incoming transaction.
+func (s *Service) evaluateRules(txn rules.Transaction) ([]rules.Hit, error) {
+	hits, err := s.Rules.Evaluate(txn, s.Repo)
+	if err != nil {
+		log.WriteLog.Error("Failed to evaluate fraud rules", zap.String("txn_id", txn.ID), zap.Error(err))
+		return nil, fmt.Errorf("500:could not evaluate fraud rules: %w", err)
+	}
+	return hits, nil
+}
+
+// txnKey identifies a transaction: transaction IDs are only unique per device.
+type txnKey struct {
+	DeviceID      int64
+	TransactionID string
+}
+
+// groupRuleHits indexes rule hits by transaction for the /fetchData response.
+func groupRuleHits(hits []model.RuleHit) map[txnKey][]jsonmodel.RuleHit {
+	grouped := make(map[txnKey][]jsonmodel.RuleHit)
+	for _, h := range hits {
+		key := txnKey{h.DeviceID, h.TransactionID}
+		grouped[key] = append(grouped[key], toRuleHitJSON(h))
+	}
+	return grouped
+}
+
+func toRuleModel(req jsonmodel.FraudRule) model.FraudRule {
+	enabled := true
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+	return model.FraudRule{
+		ID:         req.ID,
+		Name:       strings.TrimSpace(req.Name),
+		Definition: req.Definition,
+		Enabled:    enabled,
+	}
+}
+
+func toRuleHitModels(txn rules.Transaction, hits []rules.Hit) []model.RuleHit {
+	var models []model.RuleHit
+	for _, h := range hits {
+		models = append(models, model.RuleHit{
+			DeviceID:      txn.DeviceID,
+			TransactionID: txn.ID,
+			RuleID:        h.RuleID,
+			RuleName:      h.RuleName,
+			RuleType:      h.RuleType,
+			Message:       h.Message,
+			Feature:       h.Feature,
+			Value:         h.Value,
+			Limit:         h.Limit,
+		})
+	}
+	return models
+}
+
+func toRuleHitJSON(h model.RuleHit) jsonmodel.RuleHit {
+	return jsonmodel.RuleHit{
+		RuleID:   h.RuleID,
+		RuleName: h.RuleName,
+		RuleType: h.RuleType,
+		Message:  h.Message,
+		Feature:  h.Feature,
+		Value:    h.Value,
+		Limit:    h.Limit,
+	}
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	jsonmodel "anomaly-go/model/json"
+	model "anomaly-go/model/postgres"
+	"anomaly-go/service/rules"
+)
+
+func TestToRuleModel(t *testing.T) {
+	disabled := false
+
+	tests := []struct {
+		name string
+		req  jsonmodel.FraudRule
+		want model.FraudRule
+	}{
+		{
+			name: "enabled by default",
+			req:  jsonmodel.FraudRule{ID: 3, Name: "  burst  ", Definition: "type: velocity"},
+			want: model.FraudRule{ID: 3, Name: "burst", Definition: "type: velocity", Enabled: true},
+		},
+		{
+			name: "explicitly disabled",
+			req:  jsonmodel.FraudRule{Name: "spike", Definition: "type: amount_spike", Enabled: &disabled},
+			want: model.FraudRule{Name: "spike", Definition: "type: amount_spike", Enabled: false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := toRuleModel(tt.req); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("toRuleModel() = %+v; want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToRuleHitModels(t *testing.T) {
+	txn := rules.Transaction{DeviceID: 42, ID: "T1", Time: time.Now(), Amount: 90}
+	hits := []rules.Hit{
+		{RuleID: 1, RuleName: "burst", RuleType: rules.TypeVelocity, Message: "5 transactions", Value: 5, Limit: 3},
+		{RuleID: 2, RuleName: "spike", RuleType: rules.TypeAmountSpike, Message: "Amount 90.00", Value: 90, Limit: 30},
+	}
+
+	got := toRuleHitModels(txn, hits)
+	if len(got) != len(hits) {
+		t.Fatalf("toRuleHitModels() returned %d hits; want %d", len(got), len(hits))
+	}
+	for i, m := range got {
+		if m.DeviceID != 42 || m.TransactionID != "T1" {
+			t.Errorf("hit %d transaction = %d/%s; want 42/T1", i, m.DeviceID, m.TransactionID)
+		}
+		if m.RuleID != hits[i].RuleID || m.RuleName != hits[i].RuleName || m.RuleType != hits[i].RuleType ||
+			m.Message != hits[i].Message || m.Value != hits[i].Value || m.Limit != hits[i].Limit {
+			t.Errorf("hit %d = %+v; want the fields of %+v", i, m, hits[i])
+		}
+	}
+
+	if got := toRuleHitModels(txn, nil); len(got) != 0 {
+		t.Errorf("toRuleHitModels(nil) = %+v; want none", got)
+	}
+}
+
+func TestGroupRuleHits(t *testing.T) {
+	hits := []model.RuleHit{
+		{DeviceID: 1, TransactionID: "T1", RuleID: 1, RuleName: "burst"},
+		{DeviceID: 2, TransactionID: "T1", RuleID: 1, RuleName: "burst"},
+		{DeviceID: 1, TransactionID: "T1", RuleID: 2, RuleName: "spike"},
+		{DeviceID: 1, TransactionID: "T2", RuleID: 3, RuleName: "repeat"},
+	}
+
+	got := groupRuleHits(hits)
+
+	want := map[txnKey][]string{
+		{1, "T1"}: {"burst", "spike"},
+		{2, "T1"}: {"burst"},
+		{1, "T2"}: {"repeat"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("groupRuleHits() has %d transactions; want %d", len(got), len(want))
+	}
+	for key, names := range want {
+		var gotNames []string
+		for _, h := range got[key] {
+			gotNames = append(gotNames, h.RuleName)
+		}
+		if !reflect.DeepEqual(gotNames, names) {
+			t.Errorf("groupRuleHits()[%v] = %v; want %v", key, gotNames, names)
+		}
+	}
+}
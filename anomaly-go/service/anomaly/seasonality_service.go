@@ -0,0 +1,186 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"anomaly-go/log"
+	jsonmodel "anomaly-go/model/json"
+	model "anomaly-go/model/postgres"
+	"anomaly-go/service/scoring"
+
+	"go.uber.org/zap"
+)
+
+// UpdateSeasonality folds every hour completed since the last run into the seasonal
+// profiles of the devices. Hours without transactions count as zero so quiet hours
+// stay quiet. A device seen for the first time starts its profile at its first
+// transaction, and a first build looks back over the configured history.
+func (s *Service) UpdateSeasonality() error {
+	cfg := s.Config.AnalyticsConfig
+	now := time.Now()
+	current := wallHour(now)
+	earliest := current.Add(-cfg.SeasonalityHistory)
+
+	stored, err := s.Repo.GetDeviceSeasonality(nil)
+	if err != nil {
+		return fmt.Errorf("500:could not fetch seasonal profiles: %w", err)
+	}
+	profiles := make(map[int64]*scoring.Seasonality, len(stored))
+	from := earliest
+	if len(stored) > 0 {
+		from = current
+	}
+	for _, p := range stored {
+		profile := fromSeasonalityModel(p)
+		profiles[p.DeviceID] = profile
+		if next := profile.LastHour.Add(time.Hour); next.Before(from) {
+			from = next
+		}
+	}
+	if from.Before(earliest) {
+		from = earliest
+	}
+	if !from.Before(current) {
+		return nil
+	}
+
+	counts, err := s.Repo.CountHourlyTransactions(from, current, 0)
+	if err != nil {
+		return fmt.Errorf("500:could not count hourly transactions: %w", err)
+	}
+	hourly := map[int64]map[string]int{}
+	for _, c := range counts {
+		hour := wallHour(c.Hour)
+		if hourly[c.DeviceID] == nil {
+			hourly[c.DeviceID] = map[string]int{}
+			if _, ok := profiles[c.DeviceID]; !ok {
+				profile := scoring.NewSeasonality(c.DeviceID)
+				profile.LastHour = hour.Add(-time.Hour)
+				profiles[c.DeviceID] = profile
+			}
+		}
+		hourly[c.DeviceID][hourKey(hour)] = c.Count
+	}
+
+	var updated []model.DeviceSeasonality
+	for id, profile := range profiles {
+		next := profile.LastHour.Add(time.Hour)
+		if next.Before(earliest) {
+			next = earliest
+		}
+		if !next.Before(current) {
+			continue
+		}
+		for hour := next; hour.Before(current); hour = hour.Add(time.Hour) {
+			profile.Observe(hour, float64(hourly[id][hourKey(hour)]))
+		}
+		updated = append(updated, toSeasonalityModel(profile, now))
+	}
+
+	if err := s.Repo.SaveDeviceSeasonality(updated); err != nil {
+		return fmt.Errorf("500:could not save seasonal profiles: %w", err)
+	}
+	if len(updated) > 0 {
+		log.WriteLog.Info("Seasonal profiles updated", zap.Int("devices", len(updated)), zap.Time("through", current))
+	}
+	return nil
+}
+
+// loadSeasonality reads the seasonal profiles of the given devices.
+func (s *Service) loadSeasonality(deviceIDs []int64) (map[int64]*scoring.Seasonality, error) {
+	stored, err := s.Repo.GetDeviceSeasonality(deviceIDs)
+	if err != nil {
+		log.WriteLog.Error("Failed to fetch seasonal profiles", zap.Error(err))
+		return nil, fmt.Errorf("500:could not fetch seasonal profiles: %w", err)
+	}
+	profiles := make(map[int64]*scoring.Seasonality, len(stored))
+	for _, p := range stored {
+		profiles[p.DeviceID] = fromSeasonalityModel(p)
+	}
+	return profiles, nil
+}
+
+// GetSeasonalityProfile returns the expected transaction count of a device for each
+// hour of the week next to the actual count of the last seven days. It returns
+// false when the device has no seasonal profile yet.
+func (s *Service) GetSeasonalityProfile(deviceID int64) (jsonmodel.SeasonalityProfileResponse, bool, error) {
+	profiles, err := s.loadSeasonality([]int64{deviceID})
+	if err != nil {
+		return jsonmodel.SeasonalityProfileResponse{}, false, err
+	}
+	profile, ok := profiles[deviceID]
+	if !ok {
+		return jsonmodel.SeasonalityProfileResponse{}, false, nil
+	}
+
+	current := wallHour(time.Now())
+	from := current.Add(-scoring.HoursPerWeek * time.Hour)
+	counts, err := s.Repo.CountHourlyTransactions(from, current, deviceID)
+	if err != nil {
+		log.WriteLog.Error("Failed to count hourly transactions", zap.Error(err))
+		return jsonmodel.SeasonalityProfileResponse{}, false, fmt.Errorf("500:could not count hourly transactions: %w", err)
+	}
+	actual := make([]int, scoring.HoursPerWeek)
+	for _, c := range counts {
+		actual[scoring.SlotOf(wallHour(c.Hour))] += c.Count
+	}
+
+	resp := jsonmodel.SeasonalityProfileResponse{
+		DeviceID:   deviceID,
+		LastHour:   profile.LastHour.Format("2006-01-02 15:04:05"),
+		ActualFrom: from.Format("2006-01-02 15:04:05"),
+		ActualTo:   current.Format("2006-01-02 15:04:05"),
+		Slots:      make([]jsonmodel.SeasonalitySlot, 0, scoring.HoursPerWeek),
+	}
+	for slot := 0; slot < scoring.HoursPerWeek; slot++ {
+		day := slot / scoring.HoursPerDay
+		resp.Slots = append(resp.Slots, jsonmodel.SeasonalitySlot{
+			DayOfWeek:    time.Weekday((day + 1) % 7).String(),
+			Hour:         slot % scoring.HoursPerDay,
+			Expected:     profile.Expected[slot],
+			StdDev:       math.Sqrt(profile.Variance[slot]),
+			Observations: profile.Observations[slot],
+			Actual:       actual[slot],
+		})
+		resp.ExpectedWeek += profile.Expected[slot]
+		resp.ActualWeek += actual[slot]
+	}
+	return resp, true, nil
+}
+
+// wallHour truncates t to the start of its hour on the local wall clock. Naive
+// timestamps read from the database carry their wall clock in UTC, so the clock is
+// read as is rather than converted.
+func wallHour(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, time.Local)
+}
+
+// hourKey identifies an hour by its wall clock.
+func hourKey(t time.Time) string {
+	return t.Format("2006-01-02 15")
+}
+
+func toSeasonalityModel(p *scoring.Seasonality, updatedAt time.Time) model.DeviceSeasonality {
+	return model.DeviceSeasonality{
+		DeviceID:     p.DeviceID,
+		Expected:     p.Expected,
+		Variance:     p.Variance,
+		Observations: p.Observations,
+		LastHour:     p.LastHour,
+		UpdatedAt:    updatedAt,
+	}
+}
+
+func fromSeasonalityModel(p model.DeviceSeasonality) *scoring.Seasonality {
+	profile := scoring.NewSeasonality(p.DeviceID)
+	if len(p.Expected) == scoring.HoursPerWeek && len(p.Variance) == scoring.HoursPerWeek && len(p.Observations) == scoring.HoursPerWeek {
+		copy(profile.Expected, p.Expected)
+		copy(profile.Variance, p.Variance)
+		copy(profile.Observations, p.Observations)
+	}
+	// last_hour is stored with its time zone; read it back on the local clock.
+	profile.LastHour = p.LastHour.In(time.Local)
+	return profile
+}
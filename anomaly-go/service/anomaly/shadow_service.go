@@ -0,0 +1,200 @@
+package service
+
+import (
+	"database/sql"
+	"fmt"
+
+	"anomaly-go/log"
+	jsonmodel "anomaly-go/model/json"
+	model "anomaly-go/model/postgres"
+	"anomaly-go/service/scoring"
+
+	"go.uber.org/zap"
+)
+
+// shadowCandidate is the model and threshold scored next to the live ones on ingest.
+type shadowCandidate struct {
+	id        uint
+	createdAt string
+	// scorer is nil when the candidate keeps the live model.
+	scorer scoring.Scorer
+	// threshold is nil when the candidate keeps the live threshold.
+	threshold *float64
+}
+
+// InitShadowMode loads the candidate left active by a previous run. A candidate whose
+// model can no longer be built is stopped.
+func (s *Service) InitShadowMode() error {
+	stored, found, err := s.Repo.GetActiveShadowCandidate()
+	if err != nil {
+		return fmt.Errorf("500:could not fetch shadow candidate: %w", err)
+	}
+	if !found {
+		return nil
+	}
+
+	candidate, _, err := s.buildShadowCandidate(stored)
+	if err != nil {
+		log.WriteLog.Error("Shadow candidate cannot be loaded, stopping shadow mode", zap.Uint("id", stored.ID), zap.Error(err))
+		_, err = s.Repo.StopShadowCandidate()
+		return err
+	}
+	s.setShadowCandidate(candidate)
+	return nil
+}
+
+// StartShadow runs a candidate model and/or threshold in shadow mode, replacing the
+// previous candidate. Its labels go to the shadow columns only. It returns false when
+// the candidate model does not exist.
+func (s *Service) StartShadow(req jsonmodel.StartShadowRequest) (bool, error) {
+	stored := model.ShadowCandidate{}
+	if req.ModelID != nil {
+		stored.ModelVersionID = sql.NullInt64{Int64: int64(*req.ModelID), Valid: true}
+	}
+	if req.Threshold != nil {
+		stored.Threshold = sql.NullFloat64{Float64: float64(*req.Threshold), Valid: true}
+	}
+
+	candidate, found, err := s.buildShadowCandidate(stored)
+	if err != nil || !found {
+		return found, err
+	}
+
+	if err := s.Repo.StartShadowCandidate(&stored); err != nil {
+		return true, fmt.Errorf("500:database error on start shadow mode: %w", err)
+	}
+	candidate.id = stored.ID
+	candidate.createdAt = stored.CreatedAt.Format("2006-01-02 15:04:05")
+	s.setShadowCandidate(candidate)
+	return true, nil
+}
+
+// StopShadow ends shadow mode. Zero rows affected means no candidate was running.
+func (s *Service) StopShadow() (int64, error) {
+	rowsAffected, err := s.Repo.StopShadowCandidate()
+	if err != nil {
+		return 0, fmt.Errorf("500:database error on stop shadow mode: %w", err)
+	}
+	s.setShadowCandidate(nil)
+	return rowsAffected, nil
+}
+
+// CompareShadow compares the live and shadow labels of the transactions matching the
+// /fetchData filters.
+func (s *Service) CompareShadow(filter model.TransactionFilter) (jsonmodel.ShadowComparisonResponse, error) {
+	stats, err := s.Repo.CompareShadow(filter)
+	if err != nil {
+		log.WriteLog.Error("Failed to compare shadow labels", zap.Error(err))
+		return jsonmodel.ShadowComparisonResponse{}, fmt.Errorf("500:could not compare shadow labels: %w", err)
+	}
+
+	resp := jsonmodel.ShadowComparisonResponse{Comparisons: []jsonmodel.ShadowComparison{}}
+	if candidate := s.activeShadowCandidate(); candidate != nil {
+		resp.Candidate = &jsonmodel.ShadowCandidate{
+			ID:        candidate.id,
+			Threshold: candidate.threshold,
+			CreatedAt: candidate.createdAt,
+		}
+		if candidate.scorer != nil {
+			key := candidate.scorer.Model().Key()
+			resp.Candidate.ModelVersion = &key
+		}
+	}
+
+	for _, st := range stats {
+		c := jsonmodel.ShadowComparison{
+			Transactions:  st.Transactions,
+			AgreementRate: ratio(st.Agreements, st.Transactions),
+			LiveFlagged:   st.LiveFlagged,
+			ShadowFlagged: st.ShadowFlagged,
+			ExtraFlags:    st.ExtraFlags,
+			MissedFlags:   st.MissedFlags,
+			Live: newQualityMetrics(model.ConfusionCounts{
+				TruePositives:  st.LiveTruePositives,
+				FalsePositives: st.LiveFalsePositives,
+				TrueNegatives:  st.LiveTrueNegatives,
+				FalseNegatives: st.LiveFalseNegatives,
+			}),
+			Shadow: newQualityMetrics(model.ConfusionCounts{
+				TruePositives:  st.ShadowTruePositives,
+				FalsePositives: st.ShadowFalsePositives,
+				TrueNegatives:  st.ShadowTrueNegatives,
+				FalseNegatives: st.ShadowFalseNegatives,
+			}),
+		}
+		if st.ShadowModelVersion.Valid {
+			c.ShadowModelVersion = &st.ShadowModelVersion.String
+		}
+		if st.ShadowThreshold.Valid {
+			c.ShadowThreshold = &st.ShadowThreshold.Float64
+		}
+		resp.Comparisons = append(resp.Comparisons, c)
+	}
+	return resp, nil
+}
+
+// buildShadowCandidate loads the model of a stored candidate. It returns false when
+// the model does not exist.
+func (s *Service) buildShadowCandidate(stored model.ShadowCandidate) (*shadowCandidate, bool, error) {
+	candidate := &shadowCandidate{
+		id:        stored.ID,
+		createdAt: stored.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+	if stored.Threshold.Valid {
+		threshold := stored.Threshold.Float64
+		candidate.threshold = &threshold
+	}
+	if !stored.ModelVersionID.Valid {
+		return candidate, true, nil
+	}
+
+	version, found, err := s.Repo.GetModelVersion(uint(stored.ModelVersionID.Int64))
+	if err != nil {
+		return nil, false, fmt.Errorf("500:could not fetch model: %w", err)
+	}
+	if !found {
+		return nil, false, nil
+	}
+	scorer, err := scoring.New(version.Name, version.Version, []byte(version.Parameters))
+	if err != nil {
+		return nil, true, fmt.Errorf("%w: %v", ErrModelNotRunnable, err)
+	}
+	candidate.scorer = scorer
+	return candidate, true, nil
+}
+
+func (s *Service) activeShadowCandidate() *shadowCandidate {
+	s.scorerMu.RLock()
+	defer s.scorerMu.RUnlock()
+	return s.shadow
+}
+
+func (s *Service) setShadowCandidate(candidate *shadowCandidate) {
+	s.scorerMu.Lock()
+	defer s.scorerMu.Unlock()
+	s.shadow = candidate
+	if candidate != nil {
+		log.WriteLog.Info("Shadow candidate loaded", zap.Uint("id", candidate.id))
+	}
+}
+
+// scoreShadow scores a transaction as the candidate would and fills the shadow columns
+// of txn. The candidate reads the live device baselines; they only ever evolve with
+// the live model.
+func (c *shadowCandidate) scoreShadow(txn *model.Transaction, live scoring.Scorer, liveResult scoring.Result,
+	baseline *scoring.Baseline, scored scoring.Transaction, liveThreshold float64, ruleHit bool) {
+	scorer, result := live, liveResult
+	if c.scorer != nil {
+		scorer = c.scorer
+		result = c.scorer.Score(baseline, scored)
+	}
+	threshold := liveThreshold
+	if c.threshold != nil {
+		threshold = *c.threshold
+	}
+
+	txn.ShadowConfidence = sql.NullFloat64{Float64: result.Confidence, Valid: true}
+	txn.ShadowLabel = sql.NullString{String: scoreLabel(result.Confidence, threshold, ruleHit), Valid: true}
+	txn.ShadowModelVersion = sql.NullString{String: scorer.Model().Key(), Valid: true}
+	txn.ShadowThreshold = sql.NullFloat64{Float64: threshold, Valid: true}
+}
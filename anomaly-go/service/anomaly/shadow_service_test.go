@@ -0,0 +1,95 @@
+package service
+
+import (
+	"testing"
+
+	model "anomaly-go/model/postgres"
+	"anomaly-go/service/scoring"
+	"anomaly-go/util/constants"
+)
+
+// fixedScorer scores every transaction with the same confidence.
+type fixedScorer struct {
+	version    string
+	confidence float64
+}
+
+func (f fixedScorer) Model() scoring.ModelInfo {
+	return scoring.ModelInfo{Name: scoring.BaselineScorerName, Version: f.version}
+}
+
+func (f fixedScorer) Score(*scoring.Baseline, scoring.Transaction) scoring.Result {
+	return scoring.Result{Confidence: f.confidence}
+}
+
+func (f fixedScorer) Update(*scoring.Baseline, scoring.Transaction) {}
+
+func TestScoreShadow(t *testing.T) {
+	live := fixedScorer{version: "1.0.0", confidence: 60}
+	liveResult := scoring.Result{Confidence: 60}
+	threshold := func(v float64) *float64 { return &v }
+
+	tests := []struct {
+		name          string
+		candidate     shadowCandidate
+		ruleHit       bool
+		wantScore     float64
+		wantLabel     string
+		wantVersion   string
+		wantThreshold float64
+	}{
+		{
+			name:          "live model and threshold",
+			candidate:     shadowCandidate{},
+			wantScore:     60,
+			wantLabel:     constants.LabelAnomalyDetected,
+			wantVersion:   "ewma_baseline@1.0.0",
+			wantThreshold: 50,
+		},
+		{
+			name:          "stricter threshold",
+			candidate:     shadowCandidate{threshold: threshold(70)},
+			wantScore:     60,
+			wantLabel:     constants.LabelNotFraud,
+			wantVersion:   "ewma_baseline@1.0.0",
+			wantThreshold: 70,
+		},
+		{
+			name:          "candidate model",
+			candidate:     shadowCandidate{scorer: fixedScorer{version: "2.0.0", confidence: 20}},
+			wantScore:     20,
+			wantLabel:     constants.LabelNotFraud,
+			wantVersion:   "ewma_baseline@2.0.0",
+			wantThreshold: 50,
+		},
+		{
+			name:          "candidate model and threshold with a rule hit",
+			candidate:     shadowCandidate{scorer: fixedScorer{version: "2.0.0", confidence: 20}, threshold: threshold(30)},
+			ruleHit:       true,
+			wantScore:     20,
+			wantLabel:     constants.LabelReviewRequired,
+			wantVersion:   "ewma_baseline@2.0.0",
+			wantThreshold: 30,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var txn model.Transaction
+			tt.candidate.scoreShadow(&txn, live, liveResult, &scoring.Baseline{}, scoring.Transaction{}, 50, tt.ruleHit)
+
+			if !txn.ShadowConfidence.Valid || txn.ShadowConfidence.Float64 != tt.wantScore {
+				t.Errorf("shadow confidence = %+v; want %v", txn.ShadowConfidence, tt.wantScore)
+			}
+			if !txn.ShadowLabel.Valid || txn.ShadowLabel.String != tt.wantLabel {
+				t.Errorf("shadow label = %+v; want %q", txn.ShadowLabel, tt.wantLabel)
+			}
+			if !txn.ShadowModelVersion.Valid || txn.ShadowModelVersion.String != tt.wantVersion {
+				t.Errorf("shadow model version = %+v; want %q", txn.ShadowModelVersion, tt.wantVersion)
+			}
+			if !txn.ShadowThreshold.Valid || txn.ShadowThreshold.Float64 != tt.wantThreshold {
+				t.Errorf("shadow threshold = %+v; want %v", txn.ShadowThreshold, tt.wantThreshold)
+			}
+		})
+	}
+}
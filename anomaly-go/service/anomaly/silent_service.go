@@ -0,0 +1,222 @@
+package service
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"anomaly-go/log"
+	jsonmodel "anomaly-go/model/json"
+	model "anomaly-go/model/postgres"
+
+	"go.uber.org/zap"
+)
+
+// Causes of a silent device, from the battery_health cross-check.
+const (
+	// SilentCauseBatteryDead: the last battery level is at or below the dead level
+	// and the device was not charging.
+	SilentCauseBatteryDead = "battery_dead"
+	// SilentCauseDeviceOffline: battery reports stopped too, so the device is off,
+	// unplugged or cut off from the network.
+	SilentCauseDeviceOffline = "device_offline"
+	// SilentCauseNoTransactions: the battery still reports normally, so the device is
+	// alive but transactions do not reach it or are not made.
+	SilentCauseNoTransactions = "no_transactions"
+	// SilentCauseNoBatteryData: the device never reported battery data.
+	SilentCauseNoBatteryData = "no_battery_data"
+)
+
+// silentMinWeeks is the number of weeks an hour-of-week slot needs before it counts
+// towards the expected volume of a device.
+const silentMinWeeks = 2
+
+// DetectSilentDevices compares the transactions of each device over the last
+// completed hours of the configured window with what its seasonal profile expects.
+// Devices falling to the drop ratio open a finding, explained with their latest
+// battery_health block; open findings of devices active again are resolved.
+func (s *Service) DetectSilentDevices() error {
+	cfg := s.Config.AnalyticsConfig
+	now := time.Now()
+	windowTo := wallHour(now)
+	windowFrom := windowTo.Add(-cfg.SilentWindow)
+
+	profiles, err := s.loadSeasonality(nil)
+	if err != nil {
+		return err
+	}
+	counts, err := s.Repo.CountHourlyTransactions(windowFrom, windowTo, 0)
+	if err != nil {
+		return fmt.Errorf("500:could not count hourly transactions: %w", err)
+	}
+	actual := map[int64]int{}
+	for _, c := range counts {
+		actual[c.DeviceID] += c.Count
+	}
+
+	expected := map[int64]float64{}
+	var silentIDs []int64
+	for id, profile := range profiles {
+		for hour := windowFrom; hour.Before(windowTo); hour = hour.Add(time.Hour) {
+			if e, _, ok := profile.Expect(hour, silentMinWeeks); ok {
+				expected[id] += e
+			}
+		}
+		if expected[id] >= cfg.SilentMinExpected && float64(actual[id]) <= expected[id]*cfg.SilentDropRatio {
+			silentIDs = append(silentIDs, id)
+		}
+	}
+
+	open, err := s.Repo.GetOpenSilentDevices()
+	if err != nil {
+		return fmt.Errorf("500:could not fetch open silent devices: %w", err)
+	}
+	openByDevice := make(map[int64]model.SilentDevice, len(open))
+	for _, f := range open {
+		openByDevice[f.DeviceID] = f
+	}
+
+	readings, err := s.Repo.GetLatestBatteryReadings(silentIDs)
+	if err != nil {
+		return fmt.Errorf("500:could not fetch battery readings: %w", err)
+	}
+	battery := make(map[int64]model.BatteryReading, len(readings))
+	for _, b := range readings {
+		battery[b.DeviceID] = b
+	}
+	lastTxns, err := s.Repo.GetLastTransactionTimes(silentIDs)
+	if err != nil {
+		return fmt.Errorf("500:could not fetch last transactions: %w", err)
+	}
+	lastTxn := make(map[int64]time.Time, len(lastTxns))
+	for _, l := range lastTxns {
+		lastTxn[l.DeviceID] = l.LastTxnAt
+	}
+
+	findings := make([]model.SilentDevice, 0, len(silentIDs))
+	silent := make(map[int64]bool, len(silentIDs))
+	opened := 0
+	for _, id := range silentIDs {
+		silent[id] = true
+		f, ok := openByDevice[id]
+		if !ok {
+			f = model.SilentDevice{DeviceID: id, DetectedAt: now}
+			opened++
+		}
+		f.Expected = expected[id]
+		f.Actual = actual[id]
+		f.WindowFrom = windowFrom
+		f.WindowTo = windowTo
+		f.UpdatedAt = now
+		if t, ok := lastTxn[id]; ok {
+			f.LastTxnAt = sql.NullTime{Time: wallClock(t), Valid: true}
+		}
+		b, ok := battery[id]
+		f.Cause = silentCause(b, ok, windowFrom, cfg.SilentDeadBatteryLevel)
+		if ok {
+			f.BatteryLevel = b.Level
+			f.BatteryCharging = sql.NullBool{Bool: b.ChargingStatus.Int64 == 1, Valid: b.ChargingStatus.Valid}
+			if b.ReportedAt.Valid {
+				f.BatteryReportedAt = sql.NullTime{Time: wallClock(b.ReportedAt.Time), Valid: true}
+			}
+		}
+		findings = append(findings, f)
+	}
+
+	var resolved []uint
+	for _, f := range open {
+		if !silent[f.DeviceID] {
+			resolved = append(resolved, f.ID)
+		}
+	}
+
+	if err := s.Repo.SaveSilentDevices(findings); err != nil {
+		return fmt.Errorf("500:could not save silent devices: %w", err)
+	}
+	if err := s.Repo.ResolveSilentDevices(resolved, now); err != nil {
+		return fmt.Errorf("500:could not resolve silent devices: %w", err)
+	}
+	if opened > 0 || len(resolved) > 0 {
+		log.WriteLog.Warn("Silent devices updated",
+			zap.Int("opened", opened),
+			zap.Int("open", len(findings)),
+			zap.Int("resolved", len(resolved)),
+		)
+	}
+	return nil
+}
+
+// silentCause explains a silent device from its latest battery_health block.
+func silentCause(b model.BatteryReading, found bool, windowFrom time.Time, deadLevel float64) string {
+	switch {
+	case !found:
+		return SilentCauseNoBatteryData
+	case b.Level.Valid && b.Level.Float64 <= deadLevel && b.ChargingStatus.Int64 != 1:
+		return SilentCauseBatteryDead
+	case !b.ReportedAt.Valid || wallClock(b.ReportedAt.Time).Before(windowFrom):
+		return SilentCauseDeviceOffline
+	default:
+		return SilentCauseNoTransactions
+	}
+}
+
+// GetSilentDevices lists the silent device findings matching the filter.
+func (s *Service) GetSilentDevices(filter model.SilentDeviceFilter) (jsonmodel.SilentDevicesResponse, error) {
+	findings, err := s.Repo.GetSilentDevices(filter)
+	if err != nil {
+		log.WriteLog.Error("Failed to fetch silent devices", zap.Error(err))
+		return jsonmodel.SilentDevicesResponse{}, fmt.Errorf("500:could not fetch silent devices: %w", err)
+	}
+
+	resp := jsonmodel.SilentDevicesResponse{
+		Total:   len(findings),
+		ByCause: map[string]int{},
+		Devices: make([]jsonmodel.SilentDevice, 0, len(findings)),
+	}
+	for _, f := range findings {
+		resp.ByCause[f.Cause]++
+		resp.Devices = append(resp.Devices, toSilentDeviceJSON(f))
+	}
+	return resp, nil
+}
+
+// wallClock reads t on the local clock without converting it, like wallHour, so
+// naive database timestamps can be compared with and stored next to local times.
+func wallClock(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.Local)
+}
+
+func toSilentDeviceJSON(f model.SilentDevice) jsonmodel.SilentDevice {
+	j := jsonmodel.SilentDevice{
+		ID:         f.ID,
+		DeviceID:   f.DeviceID,
+		Cause:      f.Cause,
+		Expected:   f.Expected,
+		Actual:     f.Actual,
+		WindowFrom: f.WindowFrom.Format("2006-01-02 15:04:05"),
+		WindowTo:   f.WindowTo.Format("2006-01-02 15:04:05"),
+		DetectedAt: f.DetectedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:  f.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+	if f.LastTxnAt.Valid {
+		last := f.LastTxnAt.Time.Format("2006-01-02 15:04:05")
+		j.LastTransactionAt = &last
+	}
+	if f.BatteryLevel.Valid {
+		level := f.BatteryLevel.Float64
+		j.BatteryLevel = &level
+	}
+	if f.BatteryCharging.Valid {
+		charging := f.BatteryCharging.Bool
+		j.BatteryCharging = &charging
+	}
+	if f.BatteryReportedAt.Valid {
+		reported := f.BatteryReportedAt.Time.Format("2006-01-02 15:04:05")
+		j.BatteryReportedAt = &reported
+	}
+	if f.ResolvedAt.Valid {
+		resolved := f.ResolvedAt.Time.Format("2006-01-02 15:04:05")
+		j.ResolvedAt = &resolved
+	}
+	return j
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	model "anomaly-go/model/postgres"
+)
+
+func TestSilentCause(t *testing.T) {
+	windowFrom := time.Date(2026, 3, 2, 6, 0, 0, 0, time.Local)
+	// Battery times are naive database timestamps, read back as UTC.
+	recent := sql.NullTime{Time: time.Date(2026, 3, 2, 7, 0, 0, 0, time.UTC), Valid: true}
+	stale := sql.NullTime{Time: time.Date(2026, 3, 2, 5, 0, 0, 0, time.UTC), Valid: true}
+	level := func(v float64) sql.NullFloat64 { return sql.NullFloat64{Float64: v, Valid: true} }
+	charging := sql.NullInt64{Int64: 1, Valid: true}
+
+	tests := []struct {
+		name    string
+		reading model.BatteryReading
+		found   bool
+		want    string
+	}{
+		{"never reported", model.BatteryReading{}, false, SilentCauseNoBatteryData},
+		{"battery dead", model.BatteryReading{Level: level(3), ReportedAt: recent}, true, SilentCauseBatteryDead},
+		{"battery dead at the level", model.BatteryReading{Level: level(5), ReportedAt: stale}, true, SilentCauseBatteryDead},
+		{"low but charging", model.BatteryReading{Level: level(3), ChargingStatus: charging, ReportedAt: recent}, true, SilentCauseNoTransactions},
+		{"reports stopped", model.BatteryReading{Level: level(60), ReportedAt: stale}, true, SilentCauseDeviceOffline},
+		{"no report time", model.BatteryReading{Level: level(60)}, true, SilentCauseDeviceOffline},
+		{"unknown level, reports stopped", model.BatteryReading{ReportedAt: stale}, true, SilentCauseDeviceOffline},
+		{"still reporting", model.BatteryReading{Level: level(60), ReportedAt: recent}, true, SilentCauseNoTransactions},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := silentCause(tt.reading, tt.found, windowFrom, 5); got != tt.want {
+				t.Errorf("silentCause() = %q; want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWallClock(t *testing.T) {
+	in := time.Date(2026, 3, 2, 7, 30, 15, 42, time.FixedZone("UTC+9", 9*3600))
+	got := wallClock(in)
+
+	want := time.Date(2026, 3, 2, 7, 30, 15, 42, time.Local)
+	if !got.Equal(want) || got.Location() != time.Local {
+		t.Errorf("wallClock(%v) = %v; want %v", in, got, want)
+	}
+}
+
+func TestToSilentDeviceJSON(t *testing.T) {
+	detected := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	open := toSilentDeviceJSON(model.SilentDevice{
+		DeviceID:     7,
+		Cause:        SilentCauseBatteryDead,
+		BatteryLevel: sql.NullFloat64{Float64: 3, Valid: true},
+		WindowFrom:   detected.Add(-6 * time.Hour),
+		WindowTo:     detected,
+		DetectedAt:   detected,
+		UpdatedAt:    detected,
+	})
+
+	if open.WindowFrom != "2026-03-02 03:00:00" || open.DetectedAt != "2026-03-02 09:00:00" {
+		t.Errorf("toSilentDeviceJSON() times = %q, %q; want 2026-03-02 03:00:00 and 09:00:00", open.WindowFrom, open.DetectedAt)
+	}
+	if open.BatteryLevel == nil || *open.BatteryLevel != 3 {
+		t.Errorf("toSilentDeviceJSON() battery level = %v; want 3", open.BatteryLevel)
+	}
+	if open.LastTransactionAt != nil || open.BatteryCharging != nil || open.BatteryReportedAt != nil || open.ResolvedAt != nil {
+		t.Errorf("toSilentDeviceJSON() = %+v; want the unknown fields left out", open)
+	}
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+
+	"anomaly-go/log"
+	jsonmodel "anomaly-go/model/json"
+	model "anomaly-go/model/postgres"
+	"anomaly-go/service/structuring"
+
+	"go.uber.org/zap"
+)
+
+// DetectStructuring runs the structuring detector over the transactions matching the
+// /fetchData filters, per device and time window. Findings are ordered by score.
+func (s *Service) DetectStructuring(filter model.TransactionFilter, window string) (jsonmodel.StructuringResponse, error) {
+	txns, err := s.Repo.FetchStructuringTransactions(filter, window)
+	if err != nil {
+		log.WriteLog.Error("Failed to fetch transactions for structuring detection", zap.Error(err))
+		return jsonmodel.StructuringResponse{}, fmt.Errorf("500:could not fetch transactions: %w", err)
+	}
+
+	cfg := s.structuringConfig()
+	resp := jsonmodel.StructuringResponse{Window: window, Findings: []jsonmodel.StructuringFinding{}}
+
+	// Rows are ordered by device and window, so each group is a contiguous run.
+	for start := 0; start < len(txns); {
+		end := start
+		var group []structuring.Transaction
+		for end < len(txns) && txns[end].DeviceID == txns[start].DeviceID && txns[end].WindowStart.Equal(txns[start].WindowStart) {
+			t := txns[end]
+			group = append(group, structuring.Transaction{ID: t.TransactionID, Time: t.TransactionTime, Amount: t.TransactionAmount})
+			end++
+		}
+
+		for _, f := range structuring.Detect(cfg, group) {
+			finding := jsonmodel.StructuringFinding{
+				DeviceID:     txns[start].DeviceID,
+				WindowStart:  txns[start].WindowStart.Format("2006-01-02 15:04:05"),
+				Type:         f.Type,
+				Score:        f.Score,
+				Message:      f.Message,
+				Observed:     f.Observed,
+				Expected:     f.Expected,
+				Transactions: f.Transactions,
+				Supporting:   f.Supporting,
+			}
+			if f.Type == structuring.FindingNearLimit {
+				limit := f.Limit
+				finding.Limit = &limit
+			}
+			resp.Findings = append(resp.Findings, finding)
+		}
+		start = end
+	}
+
+	sort.SliceStable(resp.Findings, func(i, j int) bool { return resp.Findings[i].Score > resp.Findings[j].Score })
+	return resp, nil
+}
+
+// structuringConfig applies the ANALYTICS.env overrides to the detector defaults.
+func (s *Service) structuringConfig() structuring.Config {
+	analytics := s.Config.AnalyticsConfig
+	cfg := structuring.DefaultConfig()
+	if len(analytics.StructuringLimits) > 0 {
+		cfg.Limits = analytics.StructuringLimits
+	}
+	if analytics.StructuringNearLimitPercent > 0 {
+		cfg.NearLimitBand = float64(analytics.StructuringNearLimitPercent) / 100
+	}
+	if analytics.StructuringMinRepeats > 0 {
+		cfg.MinRepeats = analytics.StructuringMinRepeats
+	}
+	if analytics.StructuringRoundUnit > 0 {
+		cfg.RoundUnit = float64(analytics.StructuringRoundUnit)
+	}
+	if analytics.StructuringMinSamples > 0 {
+		cfg.MinSamples = analytics.StructuringMinSamples
+	}
+	return cfg
+}
@@ -0,0 +1,89 @@
+package battery
+
+import (
+	"fmt"
+	"time"
+)
+
+// Battery anomaly reason codes.
+const (
+	ReasonFastDischarge   = "FAST_DISCHARGE"
+	ReasonChargeNoGain    = "CHARGE_NO_GAIN"
+	ReasonLevelJump       = "LEVEL_JUMP"
+	ReasonLevelOutOfRange = "LEVEL_OUT_OF_RANGE"
+)
+
+const (
+	// FastDischargeFactor is how many times the fleet discharge rate a block must
+	// drain at to be abnormally fast.
+	FastDischargeFactor = 3
+	// LevelJumpPoints is the rise in battery percentage, without charging, from
+	// which a level jump is reported.
+	LevelJumpPoints = 5
+)
+
+// Finding is one reason a battery block is anomalous.
+type Finding struct {
+	Code      string
+	Message   string
+	Value     float64
+	Threshold float64
+}
+
+// Check evaluates a block against the fleet reference and the previous block of the
+// same device, nil when there is none. Blocks with an impossible level are only
+// reported as such, since their rates are meaningless.
+func Check(b Block, prev *Block, fleet Fleet) []Finding {
+	var findings []Finding
+	for _, level := range []float64{b.StartLevel, b.EndLevel} {
+		if level < 0 || level > 100 {
+			return append(findings, Finding{
+				Code:      ReasonLevelOutOfRange,
+				Message:   fmt.Sprintf("Battery level %.1f%% is outside 0-100%%", level),
+				Value:     level,
+				Threshold: 100,
+			})
+		}
+	}
+
+	if rate, ok := DischargeRate(b); ok && b.Discharging && fleet.DischargeRate > 0 {
+		if limit := FastDischargeFactor * fleet.DischargeRate; rate > limit {
+			findings = append(findings, Finding{
+				Code:      ReasonFastDischarge,
+				Message:   fmt.Sprintf("Battery drained %.1f%%/h, over %d times the fleet rate of %.1f%%/h", rate, FastDischargeFactor, fleet.DischargeRate),
+				Value:     rate,
+				Threshold: limit,
+			})
+		}
+	}
+
+	if b.Charging && b.End.Sub(b.Start) >= MinBlockDuration && b.EndLevel <= b.StartLevel {
+		findings = append(findings, Finding{
+			Code:      ReasonChargeNoGain,
+			Message:   fmt.Sprintf("Battery stayed at %.1f%% -> %.1f%% over %s of charging", b.StartLevel, b.EndLevel, b.End.Sub(b.Start).Round(time.Minute)),
+			Value:     b.EndLevel - b.StartLevel,
+			Threshold: 0,
+		})
+	}
+
+	if b.Discharging {
+		if rise := b.EndLevel - b.StartLevel; rise > LevelJumpPoints {
+			findings = append(findings, Finding{
+				Code:      ReasonLevelJump,
+				Message:   fmt.Sprintf("Battery rose %.1f points while discharging", rise),
+				Value:     rise,
+				Threshold: LevelJumpPoints,
+			})
+		} else if prev != nil && prev.Discharging && !prev.End.After(b.Start) {
+			if rise := b.StartLevel - prev.EndLevel; rise > LevelJumpPoints {
+				findings = append(findings, Finding{
+					Code:      ReasonLevelJump,
+					Message:   fmt.Sprintf("Battery rose %.1f points since the previous discharge block without charging", rise),
+					Value:     rise,
+					Threshold: LevelJumpPoints,
+				})
+			}
+		}
+	}
+	return findings
+}
@@ -0,0 +1,79 @@
+package battery
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// discharging returns a discharge block of the given length starting at now.
+func discharging(d time.Duration, from, to float64) Block {
+	return Block{Start: testNow, End: testNow.Add(d), StartLevel: from, EndLevel: to, Discharging: true}
+}
+
+// charging returns a charge block of the given length starting at now.
+func charging(d time.Duration, from, to float64) Block {
+	return Block{Start: testNow, End: testNow.Add(d), StartLevel: from, EndLevel: to, Charging: true}
+}
+
+func TestCheck(t *testing.T) {
+	fleet := Fleet{DischargeRate: 5, ChargeRate: 20}
+	unknown := discharging(time.Hour, 80, 60)
+	unknown.Discharging = false
+	earlier := func(b Block) *Block {
+		b.Start, b.End = b.Start.Add(-2*time.Hour), b.End.Add(-2*time.Hour)
+		return &b
+	}
+	overlapping := discharging(2*time.Hour, 80, 50)
+	overlapping.Start = testNow.Add(-time.Hour)
+
+	tests := []struct {
+		name  string
+		b     Block
+		prev  *Block
+		fleet Fleet
+		want  []string
+	}{
+		{"normal discharge", discharging(time.Hour, 80, 76), nil, fleet, nil},
+		{"full range levels", discharging(20*time.Hour, 100, 0), nil, fleet, nil},
+		{"start above 100", discharging(time.Hour, 101, 0), nil, fleet, []string{ReasonLevelOutOfRange}},
+		{"end below 0", charging(time.Hour, 50, -1), nil, fleet, []string{ReasonLevelOutOfRange}},
+		{"at three times the fleet rate", discharging(time.Hour, 80, 65), nil, fleet, nil},
+		{"over three times the fleet rate", discharging(time.Hour, 80, 64), nil, fleet, []string{ReasonFastDischarge}},
+		{"fast drop with unknown status", unknown, nil, fleet, nil},
+		{"fast drop without a fleet rate", discharging(time.Hour, 80, 20), nil, Fleet{}, nil},
+		{"fast drop in a short block", discharging(MinBlockDuration-time.Second, 80, 70), nil, fleet, nil},
+		{"charge with gain", charging(time.Hour, 40, 60), nil, fleet, nil},
+		{"charge without gain", charging(time.Hour, 40, 40), nil, fleet, []string{ReasonChargeNoGain}},
+		{"charge losing level", charging(time.Hour, 40, 38), nil, fleet, []string{ReasonChargeNoGain}},
+		{"short charge without gain", charging(MinBlockDuration-time.Second, 40, 40), nil, fleet, nil},
+		{"rise while discharging", discharging(time.Hour, 50, 56), nil, fleet, []string{ReasonLevelJump}},
+		{"small rise while discharging", discharging(time.Hour, 50, 55), nil, fleet, nil},
+		{"jump since previous discharge", discharging(time.Hour, 56, 54), earlier(discharging(time.Hour, 60, 50)), fleet, []string{ReasonLevelJump}},
+		{"small jump since previous discharge", discharging(time.Hour, 55, 54), earlier(discharging(time.Hour, 60, 50)), fleet, nil},
+		{"jump after a charge", discharging(time.Hour, 90, 88), earlier(charging(time.Hour, 50, 90)), fleet, nil},
+		{"jump after an overlapping block", discharging(time.Hour, 60, 58), &overlapping, fleet, nil},
+		{"fast and jumping", discharging(time.Hour, 50, 80), nil, Fleet{DischargeRate: 1}, []string{ReasonLevelJump}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var codes []string
+			for _, f := range Check(tt.b, tt.prev, tt.fleet) {
+				codes = append(codes, f.Code)
+			}
+			if !reflect.DeepEqual(codes, tt.want) {
+				t.Errorf("Check() = %v; want %v", codes, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckFastDischargeDetails(t *testing.T) {
+	findings := Check(discharging(30*time.Minute, 80, 60), nil, Fleet{DischargeRate: 5})
+	if len(findings) != 1 {
+		t.Fatalf("Check() = %+v; want one finding", findings)
+	}
+	if f := findings[0]; f.Code != ReasonFastDischarge || f.Value != 40 || f.Threshold != 15 {
+		t.Errorf("Check() = %+v; want %s at 40%%/h over 15%%/h", f, ReasonFastDischarge)
+	}
+}
@@ -0,0 +1,169 @@
+package battery
+
+import (
+	"math"
+	"time"
+)
+
+// Forecast statuses.
+const (
+	// ForecastAtRisk: the device score is already at or below the at-risk score.
+	ForecastAtRisk = "at_risk"
+	// ForecastDegrading: discharge rates are rising and the crossing is forecast.
+	ForecastDegrading = "degrading"
+	// ForecastStable: discharge rates are flat or falling, there is no fleet rate
+	// for them to move the score, or the crossing lies beyond MaxForecastHorizon.
+	ForecastStable = "stable"
+	// ForecastInsufficientData: fewer than MinForecastBlocks usable discharge blocks.
+	ForecastInsufficientData = "insufficient_data"
+	// ForecastUnscored: the device has no battery score to project from.
+	ForecastUnscored = "unscored"
+)
+
+const (
+	// MinForecastBlocks is the number of discharge blocks needed to fit a trend.
+	MinForecastBlocks = 10
+	// MinBlockDuration drops blocks too short to give a stable discharge rate.
+	MinBlockDuration = 10 * time.Minute
+	// MaxForecastHorizon caps forecasts; later crossings are reported as stable.
+	MaxForecastHorizon = 5 * 365 * 24 * time.Hour
+	// forecastZ is the normal quantile of the two-sided 95% interval on the trend.
+	forecastZ = 1.96
+)
+
+// DischargeRate returns the battery percentage lost per hour over a discharge block,
+// or false when the block is too short or the level did not drop.
+func DischargeRate(b Block) (float64, bool) {
+	d := b.End.Sub(b.Start)
+	drop := b.StartLevel - b.EndLevel
+	if d < MinBlockDuration || drop <= 0 {
+		return 0, false
+	}
+	return drop / d.Hours(), true
+}
+
+// Forecast is the projected date a device crosses the at-risk score. Lower and
+// Upper bound the 95% interval; Upper is nil when the trend may be flat.
+type Forecast struct {
+	Status      string
+	Blocks      int
+	CurrentRate float64
+	SlopePerDay float64
+	Estimate    *time.Time
+	Lower       *time.Time
+	Upper       *time.Time
+}
+
+// ForecastCrossing fits a linear trend of the discharge rate over time and projects
+// when the score crosses atRiskScore. The rate moves the score through its discharge
+// component only, with the other components held: a battery draining faster than
+// the fleet and twice as fast as today scores half of today's score, and one
+// draining slower than the fleet loses nothing until it reaches the fleet rate.
+// Without a fleet rate the score does not depend on the rate and the device is
+// stable. The interval reflects the uncertainty of the slope.
+func ForecastCrossing(blocks []Block, score float64, fleet Fleet, atRiskScore float64, now time.Time) Forecast {
+	var xs, ys []float64
+	var origin time.Time
+	for _, b := range blocks {
+		if !b.Discharging {
+			continue
+		}
+		rate, ok := DischargeRate(b)
+		if !ok {
+			continue
+		}
+		mid := b.Start.Add(b.End.Sub(b.Start) / 2)
+		if origin.IsZero() || mid.Before(origin) {
+			origin = mid
+		}
+		xs = append(xs, float64(mid.Unix()))
+		ys = append(ys, rate)
+	}
+
+	f := Forecast{Status: ForecastInsufficientData, Blocks: len(xs)}
+	if score <= atRiskScore {
+		f.Status = ForecastAtRisk
+	}
+	if len(xs) < MinForecastBlocks {
+		return f
+	}
+	for i := range xs {
+		xs[i] = (xs[i] - float64(origin.Unix())) / 86400
+	}
+
+	intercept, slope, slopeSE, ok := linearFit(xs, ys)
+	if !ok {
+		return f
+	}
+	nowX := now.Sub(origin).Hours() / 24
+	f.CurrentRate = intercept + slope*nowX
+	f.SlopePerDay = slope
+	if f.Status == ForecastAtRisk || f.CurrentRate <= 0 {
+		return f
+	}
+	// Rate increase needed for the discharge component to fall far enough for the
+	// score to reach atRiskScore.
+	f.Status = ForecastStable
+	target := atRiskScore / score * dischargeComponent(f.CurrentRate, fleet.DischargeRate)
+	if fleet.DischargeRate <= 0 || target <= 0 {
+		return f
+	}
+	rise := fleet.DischargeRate/target - f.CurrentRate
+	estimate, ok := crossingDate(now, rise, slope)
+	if !ok {
+		return f
+	}
+	f.Status = ForecastDegrading
+	f.Estimate = estimate
+	f.Lower, _ = crossingDate(now, rise, slope+forecastZ*slopeSE)
+	f.Upper, _ = crossingDate(now, rise, slope-forecastZ*slopeSE)
+	return f
+}
+
+// crossingDate returns when a rate growing by slope per day has risen by rise, or
+// false when it never does within MaxForecastHorizon. The horizon is checked on the
+// number of days before converting to a Duration, which a near-flat slope overflows.
+func crossingDate(now time.Time, rise, slope float64) (*time.Time, bool) {
+	if slope <= 0 {
+		return nil, false
+	}
+	days := rise / slope
+	if math.IsNaN(days) || math.IsInf(days, 0) || days > MaxForecastHorizon.Hours()/24 {
+		return nil, false
+	}
+	t := now.Add(time.Duration(days * 24 * float64(time.Hour)))
+	return &t, true
+}
+
+// linearFit returns the least squares line through the points and the standard
+// error of its slope.
+func linearFit(xs, ys []float64) (intercept, slope, slopeSE float64, ok bool) {
+	n := float64(len(xs))
+	if len(xs) < 3 {
+		return 0, 0, 0, false
+	}
+	var sx, sy float64
+	for i := range xs {
+		sx += xs[i]
+		sy += ys[i]
+	}
+	mx, my := sx/n, sy/n
+	var sxx, sxy float64
+	for i := range xs {
+		sxx += (xs[i] - mx) * (xs[i] - mx)
+		sxy += (xs[i] - mx) * (ys[i] - my)
+	}
+	if sxx == 0 {
+		return 0, 0, 0, false
+	}
+	slope = sxy / sxx
+	intercept = my - slope*mx
+
+	var sse float64
+	for i := range xs {
+		r := ys[i] - (intercept + slope*xs[i])
+		sse += r * r
+	}
+	slopeSE = math.Sqrt(sse / (n - 2) / sxx)
+	return intercept, slope, slopeSE, true
+}
@@ -0,0 +1,198 @@
+package battery
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+var testNow = time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+
+// dischargeBlocks returns n one-hour discharge blocks, one per day before now,
+// draining rate(i) percentage points per hour, oldest first.
+func dischargeBlocks(n int, rate func(i int) float64) []Block {
+	blocks := make([]Block, 0, n)
+	for i := 0; i < n; i++ {
+		start := testNow.AddDate(0, 0, i-n)
+		blocks = append(blocks, Block{
+			Number:      i,
+			Start:       start,
+			End:         start.Add(time.Hour),
+			StartLevel:  90,
+			EndLevel:    90 - rate(i),
+			Discharging: true,
+		})
+	}
+	return blocks
+}
+
+func TestDischargeRate(t *testing.T) {
+	start := testNow
+	tests := []struct {
+		name string
+		b    Block
+		want float64
+		ok   bool
+	}{
+		{"one hour", Block{Start: start, End: start.Add(time.Hour), StartLevel: 80, EndLevel: 70}, 10, true},
+		{"at minimum duration", Block{Start: start, End: start.Add(MinBlockDuration), StartLevel: 80, EndLevel: 79}, 6, true},
+		{"too short", Block{Start: start, End: start.Add(MinBlockDuration - time.Second), StartLevel: 80, EndLevel: 70}, 0, false},
+		{"flat level", Block{Start: start, End: start.Add(time.Hour), StartLevel: 80, EndLevel: 80}, 0, false},
+		{"rising level", Block{Start: start, End: start.Add(time.Hour), StartLevel: 70, EndLevel: 80}, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := DischargeRate(tt.b)
+			if ok != tt.ok || math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("DischargeRate() = %v, %v; want %v, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestCrossingDate(t *testing.T) {
+	horizonDays := MaxForecastHorizon.Hours() / 24
+	tests := []struct {
+		name     string
+		rise     float64
+		slope    float64
+		wantDays float64
+		ok       bool
+	}{
+		{"ten days", 5, 0.5, 10, true},
+		{"today", 0, 1, 0, true},
+		{"at horizon", horizonDays, 1, horizonDays, true},
+		{"past horizon", horizonDays + 1, 1, 0, false},
+		{"zero slope", 5, 0, 0, false},
+		{"negative slope", 5, -1, 0, false},
+		{"near-flat slope overflows a Duration", 5, 1e-6, 0, false},
+		{"subnormal slope", 5, math.SmallestNonzeroFloat64, 0, false},
+		{"infinite rise", math.Inf(1), 1, 0, false},
+		{"NaN rise", math.NaN(), 1, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := crossingDate(testNow, tt.rise, tt.slope)
+			if ok != tt.ok {
+				t.Fatalf("crossingDate() ok = %v; want %v", ok, tt.ok)
+			}
+			if !ok {
+				if got != nil {
+					t.Errorf("crossingDate() = %v; want nil", got)
+				}
+				return
+			}
+			if days := got.Sub(testNow).Hours() / 24; math.Abs(days-tt.wantDays) > 1e-6 {
+				t.Errorf("crossingDate() is %v days out; want %v", days, tt.wantDays)
+			}
+		})
+	}
+}
+
+func TestLinearFit(t *testing.T) {
+	tests := []struct {
+		name          string
+		xs, ys        []float64
+		wantIntercept float64
+		wantSlope     float64
+		wantSE        float64
+		ok            bool
+	}{
+		{"empty", nil, nil, 0, 0, 0, false},
+		{"single point", []float64{1}, []float64{2}, 0, 0, 0, false},
+		{"two points", []float64{1, 2}, []float64{2, 4}, 0, 0, 0, false},
+		{"exact line", []float64{0, 1, 2, 3}, []float64{1, 3, 5, 7}, 1, 2, 0, true},
+		{"flat", []float64{0, 1, 2}, []float64{4, 4, 4}, 4, 0, 0, true},
+		{"zero x variance", []float64{2, 2, 2}, []float64{1, 2, 3}, 0, 0, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			intercept, slope, se, ok := linearFit(tt.xs, tt.ys)
+			if ok != tt.ok {
+				t.Fatalf("linearFit() ok = %v; want %v", ok, tt.ok)
+			}
+			if math.Abs(intercept-tt.wantIntercept) > 1e-9 || math.Abs(slope-tt.wantSlope) > 1e-9 || math.Abs(se-tt.wantSE) > 1e-9 {
+				t.Errorf("linearFit() = %v, %v, %v; want %v, %v, %v", intercept, slope, se, tt.wantIntercept, tt.wantSlope, tt.wantSE)
+			}
+		})
+	}
+}
+
+func TestForecastCrossing(t *testing.T) {
+	tests := []struct {
+		name       string
+		blocks     []Block
+		score      float64
+		wantStatus string
+		wantDate   bool
+	}{
+		{"no blocks", nil, 500, ForecastInsufficientData, false},
+		{"single block", dischargeBlocks(1, func(int) float64 { return 5 }), 500, ForecastInsufficientData, false},
+		{"one block short", dischargeBlocks(MinForecastBlocks-1, func(i int) float64 { return 5 + float64(i) }), 500, ForecastInsufficientData, false},
+		{"already at risk with little data", dischargeBlocks(1, func(int) float64 { return 5 }), 100, ForecastAtRisk, false},
+		{"already at risk", dischargeBlocks(20, func(i int) float64 { return 5 + float64(i) }), 100, ForecastAtRisk, false},
+		{"flat rate", dischargeBlocks(20, func(int) float64 { return 5 }), 500, ForecastStable, false},
+		{"falling rate", dischargeBlocks(20, func(i int) float64 { return 20 - 0.5*float64(i) }), 500, ForecastStable, false},
+		{"almost flat rate", dischargeBlocks(20, func(i int) float64 { return 5 + 1e-9*float64(i) }), 500, ForecastStable, false},
+		{"rising rate", dischargeBlocks(20, func(i int) float64 { return 5 + 0.1*float64(i) }), 500, ForecastDegrading, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := ForecastCrossing(tt.blocks, tt.score, Fleet{DischargeRate: 5}, 100, testNow)
+			if f.Status != tt.wantStatus {
+				t.Fatalf("ForecastCrossing() status = %q; want %q", f.Status, tt.wantStatus)
+			}
+			if (f.Estimate != nil) != tt.wantDate {
+				t.Fatalf("ForecastCrossing() estimate = %v; want one: %v", f.Estimate, tt.wantDate)
+			}
+			if f.Estimate == nil {
+				return
+			}
+			if f.Estimate.Before(testNow) || f.Estimate.After(testNow.Add(MaxForecastHorizon)) {
+				t.Errorf("estimate %v outside [now, now+horizon]", f.Estimate)
+			}
+			if f.Lower != nil && f.Lower.After(*f.Estimate) {
+				t.Errorf("lower bound %v after estimate %v", f.Lower, f.Estimate)
+			}
+			if f.Upper != nil && f.Upper.Before(*f.Estimate) {
+				t.Errorf("upper bound %v before estimate %v", f.Upper, f.Estimate)
+			}
+		})
+	}
+}
+
+func TestForecastCrossingEstimate(t *testing.T) {
+	// The rate rises by 0.1 per day from 5, so it is 7 now.
+	blocks := dischargeBlocks(20, func(i int) float64 { return 5 + 0.1*float64(i) })
+	tests := []struct {
+		name     string
+		fleet    Fleet
+		wantDays float64
+	}{
+		// Score 200 against an at-risk score of 100 needs the rate to double, a rise
+		// of 7: 70 days away.
+		{"faster than the fleet", Fleet{DischargeRate: 5}, 70},
+		{"at the fleet rate", Fleet{DischargeRate: 7}, 70},
+		// The discharge component stays capped at 1 until the rate reaches 10, then
+		// has to halve: a rise of 13.
+		{"slower than the fleet", Fleet{DischargeRate: 10}, 130},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := ForecastCrossing(blocks, 200, tt.fleet, 100, testNow)
+			if f.Estimate == nil {
+				t.Fatalf("ForecastCrossing() status %q without estimate", f.Status)
+			}
+			if days := f.Estimate.Sub(testNow).Hours() / 24; math.Abs(days-tt.wantDays) > 1 {
+				t.Errorf("crossing in %.2f days; want about %v", days, tt.wantDays)
+			}
+		})
+	}
+}
+
+func TestForecastCrossingWithoutFleet(t *testing.T) {
+	blocks := dischargeBlocks(20, func(i int) float64 { return 5 + 0.1*float64(i) })
+	if f := ForecastCrossing(blocks, 200, Fleet{}, 100, testNow); f.Status != ForecastStable || f.Estimate != nil {
+		t.Errorf("ForecastCrossing() = %q, %v; want %q without estimate", f.Status, f.Estimate, ForecastStable)
+	}
+}
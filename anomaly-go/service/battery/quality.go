@@ -0,0 +1,169 @@
+package battery
+
+import (
+	"math"
+	"time"
+)
+
+// Data quality issue types.
+const (
+	IssueGap           = "gap"
+	IssueOverlap       = "overlap"
+	IssueDuplicate     = "duplicate"
+	IssueOutOfOrder    = "out_of_order"
+	IssueDiscontinuity = "level_discontinuity"
+)
+
+// TrailingGap is the Block of a gap still open at the end of the range, and
+// LeadingGap the Previous of a gap between the start of the range and the first block.
+const (
+	TrailingGap = -1
+	LeadingGap  = -1
+)
+
+const (
+	// GapTolerance is the longest silence between consecutive blocks that is not
+	// reported as a gap.
+	GapTolerance = 15 * time.Minute
+	// OverlapTolerance absorbs clock jitter between the end of a block and the start
+	// of the next.
+	OverlapTolerance = time.Minute
+)
+
+// Issue is one data quality problem. Block is the block it was found at, or
+// TrailingGap for a silence after the last block; Previous is the block before it,
+// or LeadingGap for a silence before the first block.
+// Value is the length in minutes of a gap or overlap, or the level change in
+// percentage points of a discontinuity.
+type Issue struct {
+	Type     string
+	Block    int
+	Previous int
+	At       time.Time
+	Value    float64
+}
+
+// Quality is the data quality of the blocks of one device over a range.
+type Quality struct {
+	Blocks          int
+	Span            time.Duration
+	Covered         time.Duration
+	Gaps            int
+	GapTime         time.Duration
+	LongestGap      time.Duration
+	Overlaps        int
+	Duplicates      int
+	OutOfOrder      int
+	Discontinuities int
+	Issues          []Issue
+}
+
+// Completeness is the share, in percent, of the range covered by at least one block.
+func (q Quality) Completeness() float64 {
+	if q.Span <= 0 {
+		return 0
+	}
+	return math.Min(100, float64(q.Covered)/float64(q.Span)*100)
+}
+
+// AssessQuality checks the blocks of one device, sorted by start time, over the
+// range from to until, or from their first start when from is zero. Gaps are
+// measured against the latest end seen so far, so a long block is not followed by
+// phantom gaps; a silence between from and the first block is reported as a leading
+// gap and one between the last block and until as a trailing gap. Level
+// discontinuities are only reported between adjacent blocks, since a gap may hide a
+// charge.
+func AssessQuality(blocks []Block, from, until time.Time) Quality {
+	q := Quality{Blocks: len(blocks)}
+	if len(blocks) == 0 {
+		return q
+	}
+	first := blocks[0]
+	if from.IsZero() {
+		from = first.Start
+	}
+	if until.Before(from) {
+		until = from
+	}
+	q.Span = until.Sub(from)
+
+	if gap := first.Start.Sub(from); gap > GapTolerance {
+		q.addGap(gap, Issue{Type: IssueGap, Block: first.Number, Previous: LeadingGap, At: from})
+	}
+	reach := from
+	q.Covered = clip(latest(first.Start, from), first.End, until)
+	if first.End.After(reach) {
+		reach = first.End
+	}
+	maxNumber := first.Number
+	for i := 1; i < len(blocks); i++ {
+		b, prev := blocks[i], blocks[i-1]
+
+		if b.Start.Equal(prev.Start) && b.End.Equal(prev.End) && b.StartLevel == prev.StartLevel && b.EndLevel == prev.EndLevel {
+			q.Duplicates++
+			q.Issues = append(q.Issues, Issue{Type: IssueDuplicate, Block: b.Number, Previous: prev.Number, At: b.Start})
+			continue
+		}
+		if b.Number < maxNumber {
+			q.OutOfOrder++
+			q.Issues = append(q.Issues, Issue{Type: IssueOutOfOrder, Block: b.Number, Previous: prev.Number, At: b.Start})
+		} else {
+			maxNumber = b.Number
+		}
+
+		switch gap := b.Start.Sub(reach); {
+		case gap > GapTolerance:
+			q.addGap(gap, Issue{Type: IssueGap, Block: b.Number, Previous: prev.Number, At: reach})
+		case -gap > OverlapTolerance:
+			q.Overlaps++
+			q.Issues = append(q.Issues, Issue{Type: IssueOverlap, Block: b.Number, Previous: prev.Number, At: b.Start, Value: -gap.Minutes()})
+		default:
+			if jump := b.StartLevel - prev.EndLevel; math.Abs(jump) > LevelJumpPoints {
+				q.Discontinuities++
+				q.Issues = append(q.Issues, Issue{Type: IssueDiscontinuity, Block: b.Number, Previous: prev.Number, At: b.Start, Value: jump})
+			}
+		}
+
+		start := b.Start
+		if start.Before(reach) {
+			start = reach
+		}
+		q.Covered += clip(start, b.End, until)
+		if b.End.After(reach) {
+			reach = b.End
+		}
+	}
+
+	if gap := until.Sub(reach); gap > GapTolerance {
+		q.addGap(gap, Issue{Type: IssueGap, Block: TrailingGap, Previous: blocks[len(blocks)-1].Number, At: reach})
+	}
+	return q
+}
+
+func (q *Quality) addGap(gap time.Duration, issue Issue) {
+	q.Gaps++
+	q.GapTime += gap
+	if gap > q.LongestGap {
+		q.LongestGap = gap
+	}
+	issue.Value = gap.Minutes()
+	q.Issues = append(q.Issues, issue)
+}
+
+func latest(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+// clip returns the part of [start, end) before until.
+func clip(start, end, until time.Time) time.Duration {
+	if end.After(until) {
+		end = until
+	}
+	if !end.After(start) {
+		return 0
+	}
+	return end.Sub(start)
+}
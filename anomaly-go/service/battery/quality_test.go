@@ -0,0 +1,99 @@
+package battery
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// minutes returns the time the given number of minutes after now.
+func minutes(m int) time.Time {
+	return testNow.Add(time.Duration(m) * time.Minute)
+}
+
+// span returns block number running from start to end minutes after now, with
+// levels from and to.
+func span(number, start, end int, from, to float64) Block {
+	return Block{Number: number, Start: minutes(start), End: minutes(end), StartLevel: from, EndLevel: to, Discharging: true}
+}
+
+func TestAssessQuality(t *testing.T) {
+	tests := []struct {
+		name             string
+		blocks           []Block
+		from             time.Time
+		until            time.Time
+		wantSpan         time.Duration
+		wantCompleteness float64
+		wantIssues       []string
+	}{
+		{"no blocks", nil, time.Time{}, minutes(60), 0, 0, nil},
+		{"single block", []Block{span(0, 0, 60, 80, 70)}, time.Time{}, minutes(60), time.Hour, 100, nil},
+		{"until before the first block", []Block{span(0, 0, 60, 80, 70)}, time.Time{}, minutes(-60), 0, 0, nil},
+		{"contiguous", []Block{span(0, 0, 60, 80, 70), span(1, 60, 120, 70, 60)}, time.Time{}, minutes(120), 2 * time.Hour, 100, nil},
+		{"gap at tolerance", []Block{span(0, 0, 60, 80, 70), span(1, 75, 135, 70, 60)}, time.Time{}, minutes(135), 135 * time.Minute, 120.0 / 135 * 100, nil},
+		{"gap over tolerance", []Block{span(0, 0, 60, 80, 70), span(1, 120, 180, 70, 60)}, time.Time{}, minutes(180), 3 * time.Hour, 200.0 / 3, []string{IssueGap}},
+		{"overlap at tolerance", []Block{span(0, 0, 60, 80, 70), span(1, 59, 120, 70, 60)}, time.Time{}, minutes(120), 2 * time.Hour, 100, nil},
+		{"overlap over tolerance", []Block{span(0, 0, 60, 80, 70), span(1, 50, 120, 70, 60)}, time.Time{}, minutes(120), 2 * time.Hour, 100, []string{IssueOverlap}},
+		{"duplicate", []Block{span(0, 0, 60, 80, 70), span(0, 0, 60, 80, 70)}, time.Time{}, minutes(60), time.Hour, 100, []string{IssueDuplicate}},
+		{"out of order", []Block{span(0, 0, 60, 80, 70), span(2, 60, 120, 70, 60), span(1, 120, 180, 60, 50)}, time.Time{}, minutes(180), 3 * time.Hour, 100, []string{IssueOutOfOrder}},
+		{"level discontinuity", []Block{span(0, 0, 60, 80, 70), span(1, 60, 120, 50, 40)}, time.Time{}, minutes(120), 2 * time.Hour, 100, []string{IssueDiscontinuity}},
+		{"level change across a gap", []Block{span(0, 0, 60, 80, 70), span(1, 120, 180, 50, 40)}, time.Time{}, minutes(180), 3 * time.Hour, 200.0 / 3, []string{IssueGap}},
+		{"no phantom gap after a long block", []Block{span(0, 0, 240, 80, 40), span(1, 30, 60, 42, 41), span(2, 240, 300, 40, 30)}, time.Time{}, minutes(300), 5 * time.Hour, 100, []string{IssueOverlap}},
+		{"trailing gap", []Block{span(0, 0, 60, 80, 70)}, time.Time{}, minutes(120), 2 * time.Hour, 50, []string{IssueGap}},
+		{"leading gap", []Block{span(0, 60, 120, 80, 70)}, minutes(0), minutes(120), 2 * time.Hour, 50, []string{IssueGap}},
+		{"leading silence at tolerance", []Block{span(0, 15, 75, 80, 70)}, minutes(0), minutes(75), 75 * time.Minute, 80, nil},
+		{"block started before the range", []Block{span(0, -30, 60, 80, 70)}, minutes(0), minutes(60), time.Hour, 100, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := AssessQuality(tt.blocks, tt.from, tt.until)
+			if q.Blocks != len(tt.blocks) {
+				t.Errorf("Blocks = %d; want %d", q.Blocks, len(tt.blocks))
+			}
+			if q.Span != tt.wantSpan {
+				t.Errorf("Span = %v; want %v", q.Span, tt.wantSpan)
+			}
+			if math.Abs(q.Completeness()-tt.wantCompleteness) > 1e-9 {
+				t.Errorf("Completeness() = %v; want %v", q.Completeness(), tt.wantCompleteness)
+			}
+			var issues []string
+			for _, issue := range q.Issues {
+				issues = append(issues, issue.Type)
+			}
+			if !reflect.DeepEqual(issues, tt.wantIssues) {
+				t.Errorf("issues = %v; want %v", issues, tt.wantIssues)
+			}
+		})
+	}
+}
+
+func TestAssessQualityGapDetails(t *testing.T) {
+	blocks := []Block{span(3, 60, 120, 80, 70), span(4, 180, 200, 70, 60)}
+	q := AssessQuality(blocks, minutes(0), minutes(260))
+
+	want := []Issue{
+		{Type: IssueGap, Block: 3, Previous: LeadingGap, At: minutes(0), Value: 60},
+		{Type: IssueGap, Block: 4, Previous: 3, At: minutes(120), Value: 60},
+		{Type: IssueGap, Block: TrailingGap, Previous: 4, At: minutes(200), Value: 60},
+	}
+	if !reflect.DeepEqual(q.Issues, want) {
+		t.Errorf("issues = %+v; want %+v", q.Issues, want)
+	}
+	if q.Gaps != 3 || q.GapTime != 3*time.Hour || q.LongestGap != time.Hour {
+		t.Errorf("gaps = %d over %v, longest %v; want 3 over 3h, longest 1h", q.Gaps, q.GapTime, q.LongestGap)
+	}
+	if q.Covered != 80*time.Minute {
+		t.Errorf("Covered = %v; want 1h20m", q.Covered)
+	}
+}
+
+func TestCompletenessCapped(t *testing.T) {
+	if got := (Quality{Span: time.Hour, Covered: 2 * time.Hour}).Completeness(); got != 100 {
+		t.Errorf("Completeness() = %v; want 100", got)
+	}
+	if got := (Quality{}).Completeness(); got != 0 {
+		t.Errorf("Completeness() of an empty range = %v; want 0", got)
+	}
+}
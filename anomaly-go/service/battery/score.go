@@ -0,0 +1,175 @@
+package battery
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+const (
+	// MaxScore is the score of a battery performing at or above the fleet reference
+	// on every component.
+	MaxScore = 1000
+	// MinScoreBlocks is the number of blocks a device needs to be scored.
+	MinScoreBlocks = 3
+	// trendHorizonDays is the horizon over which the discharge rate trend is judged:
+	// a rate rising by its own value within it drives the trend component to zero.
+	trendHorizonDays = 30
+)
+
+// Block is one battery_health block.
+type Block struct {
+	Number     int
+	Start      time.Time
+	End        time.Time
+	StartLevel float64
+	EndLevel   float64
+	// Charging and Discharging are both false when the charging status is unknown.
+	Charging    bool
+	Discharging bool
+	Anomaly     bool
+}
+
+// Fleet holds the reference rates devices are scored against: the median over
+// devices of their median discharge and charge rates.
+type Fleet struct {
+	DischargeRate float64
+	ChargeRate    float64
+}
+
+// Score is a battery score with its components, each in [0, 1]. Value is MaxScore
+// times the product of the components, so one failing component is enough to put a
+// battery at risk.
+type Score struct {
+	Value float64
+	// Discharge is the fleet discharge rate over the device rate, capped at 1.
+	Discharge float64
+	// ChargeAcceptance is the device charge rate over the fleet rate, capped at 1.
+	ChargeAcceptance float64
+	// AnomalyFree is the share of blocks not flagged as anomalous.
+	AnomalyFree float64
+	// Trend falls from 1 as the discharge rate rises over time.
+	Trend  float64
+	Blocks int
+}
+
+// Reference computes the fleet reference rates from the blocks of every device.
+func Reference(devices map[int64][]Block) Fleet {
+	var discharge, charge []float64
+	for _, blocks := range devices {
+		if r, ok := median(rates(blocks, dischargeRate)); ok {
+			discharge = append(discharge, r)
+		}
+		if r, ok := median(rates(blocks, chargeRate)); ok {
+			charge = append(charge, r)
+		}
+	}
+	var f Fleet
+	f.DischargeRate, _ = median(discharge)
+	f.ChargeRate, _ = median(charge)
+	return f
+}
+
+// Compute scores a device from its blocks, or returns false with fewer than
+// MinScoreBlocks blocks. Components without data, such as charge acceptance of a
+// device never seen charging, are neutral.
+func Compute(blocks []Block, fleet Fleet) (Score, bool) {
+	s := Score{Discharge: 1, ChargeAcceptance: 1, AnomalyFree: 1, Trend: 1, Blocks: len(blocks)}
+	if len(blocks) < MinScoreBlocks {
+		return s, false
+	}
+
+	if r, ok := median(rates(blocks, dischargeRate)); ok {
+		s.Discharge = dischargeComponent(r, fleet.DischargeRate)
+	}
+	if r, ok := median(rates(blocks, chargeRate)); ok && fleet.ChargeRate > 0 {
+		s.ChargeAcceptance = math.Min(1, r/fleet.ChargeRate)
+	}
+
+	anomalies := 0
+	for _, b := range blocks {
+		if b.Anomaly {
+			anomalies++
+		}
+	}
+	s.AnomalyFree = 1 - float64(anomalies)/float64(len(blocks))
+
+	var xs, ys []float64
+	for _, b := range blocks {
+		if r, ok := dischargeRate(b); ok {
+			mid := b.Start.Add(b.End.Sub(b.Start) / 2)
+			xs = append(xs, float64(mid.Unix())/86400)
+			ys = append(ys, r)
+		}
+	}
+	if _, slope, _, ok := linearFit(xs, ys); ok {
+		if typical, ok := median(ys); ok && typical > 0 {
+			rise := slope * trendHorizonDays / typical
+			s.Trend = 1 - math.Max(0, math.Min(1, rise))
+		}
+	}
+
+	s.Value = MaxScore * s.Discharge * s.ChargeAcceptance * s.AnomalyFree * s.Trend
+	return s, true
+}
+
+// ChargeRate returns the battery percentage gained per hour over a charging block,
+// or false when the block is too short or the level did not rise.
+func ChargeRate(b Block) (float64, bool) {
+	d := b.End.Sub(b.Start)
+	gain := b.EndLevel - b.StartLevel
+	if d < MinBlockDuration || gain <= 0 {
+		return 0, false
+	}
+	return gain / d.Hours(), true
+}
+
+// dischargeComponent is the Discharge component of a device draining at rate: the
+// fleet rate over the device rate, capped at 1, and neutral without either rate.
+func dischargeComponent(rate, fleetRate float64) float64 {
+	if rate <= 0 || fleetRate <= 0 {
+		return 1
+	}
+	return math.Min(1, fleetRate/rate)
+}
+
+// dischargeRate is DischargeRate of a block reported as discharging, so that a
+// level drop while charging or with an unknown status is not taken as a rate.
+func dischargeRate(b Block) (float64, bool) {
+	if !b.Discharging {
+		return 0, false
+	}
+	return DischargeRate(b)
+}
+
+// chargeRate is ChargeRate of a block reported as charging.
+func chargeRate(b Block) (float64, bool) {
+	if !b.Charging {
+		return 0, false
+	}
+	return ChargeRate(b)
+}
+
+// rates returns the rate of every block rate accepts.
+func rates(blocks []Block, rate func(Block) (float64, bool)) []float64 {
+	var out []float64
+	for _, b := range blocks {
+		if r, ok := rate(b); ok {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+func median(values []float64) (float64, bool) {
+	if len(values) == 0 {
+		return 0, false
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2, true
+	}
+	return sorted[mid], true
+}
@@ -0,0 +1,239 @@
+package battery
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// chargeBlocks returns n one-hour charge blocks, one per day before now, gaining
+// rate percentage points per hour.
+func chargeBlocks(n int, rate float64) []Block {
+	blocks := make([]Block, 0, n)
+	for i := 0; i < n; i++ {
+		start := testNow.AddDate(0, 0, i-n).Add(12 * time.Hour)
+		blocks = append(blocks, Block{
+			Number:     100 + i,
+			Start:      start,
+			End:        start.Add(time.Hour),
+			StartLevel: 20,
+			EndLevel:   20 + rate,
+			Charging:   true,
+		})
+	}
+	return blocks
+}
+
+func constantRate(rate float64) func(int) float64 {
+	return func(int) float64 { return rate }
+}
+
+func TestChargeRate(t *testing.T) {
+	start := testNow
+	tests := []struct {
+		name string
+		b    Block
+		want float64
+		ok   bool
+	}{
+		{"one hour", Block{Start: start, End: start.Add(time.Hour), StartLevel: 20, EndLevel: 50}, 30, true},
+		{"too short", Block{Start: start, End: start.Add(time.Minute), StartLevel: 20, EndLevel: 50}, 0, false},
+		{"no gain", Block{Start: start, End: start.Add(time.Hour), StartLevel: 50, EndLevel: 50}, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ChargeRate(tt.b)
+			if ok != tt.ok || math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("ChargeRate() = %v, %v; want %v, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestRateStatus(t *testing.T) {
+	drop := Block{Start: testNow, End: testNow.Add(time.Hour), StartLevel: 80, EndLevel: 70}
+	gain := Block{Start: testNow, End: testNow.Add(time.Hour), StartLevel: 70, EndLevel: 80}
+	tests := []struct {
+		name     string
+		b        Block
+		rate     func(Block) (float64, bool)
+		want     float64
+		accepted bool
+	}{
+		{"discharging drop", withStatus(drop, false, true), dischargeRate, 10, true},
+		{"unknown status drop", drop, dischargeRate, 0, false},
+		{"charging drop", withStatus(drop, true, false), dischargeRate, 0, false},
+		{"charging gain", withStatus(gain, true, false), chargeRate, 10, true},
+		{"unknown status gain", gain, chargeRate, 0, false},
+		{"discharging gain", withStatus(gain, false, true), chargeRate, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.rate(tt.b)
+			if ok != tt.accepted || math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("rate = %v, %v; want %v, %v", got, ok, tt.want, tt.accepted)
+			}
+		})
+	}
+}
+
+func withStatus(b Block, charging, discharging bool) Block {
+	b.Charging, b.Discharging = charging, discharging
+	return b
+}
+
+func TestDischargeComponent(t *testing.T) {
+	tests := []struct {
+		name            string
+		rate, fleetRate float64
+		want            float64
+	}{
+		{"at the fleet rate", 5, 5, 1},
+		{"slower than the fleet is capped", 2, 5, 1},
+		{"twice as fast", 10, 5, 0.5},
+		{"no rate", 0, 5, 1},
+		{"no fleet rate", 10, 0, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := dischargeComponent(tt.rate, tt.fleetRate); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("dischargeComponent(%v, %v) = %v; want %v", tt.rate, tt.fleetRate, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReference(t *testing.T) {
+	tests := []struct {
+		name    string
+		devices map[int64][]Block
+		want    Fleet
+	}{
+		{"no devices", nil, Fleet{}},
+		{"device without blocks", map[int64][]Block{1: nil}, Fleet{}},
+		{"single device", map[int64][]Block{1: dischargeBlocks(3, constantRate(4))}, Fleet{DischargeRate: 4}},
+		{"median over devices", map[int64][]Block{
+			1: dischargeBlocks(3, constantRate(4)),
+			2: dischargeBlocks(1, constantRate(6)),
+			3: append(dischargeBlocks(5, constantRate(10)), chargeBlocks(2, 30)...),
+		}, Fleet{DischargeRate: 6, ChargeRate: 30}},
+		{"even number of devices", map[int64][]Block{
+			1: dischargeBlocks(3, constantRate(4)),
+			2: dischargeBlocks(3, constantRate(6)),
+		}, Fleet{DischargeRate: 5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Reference(tt.devices); got != tt.want {
+				t.Errorf("Reference() = %+v; want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompute(t *testing.T) {
+	fleet := Fleet{DischargeRate: 5, ChargeRate: 20}
+	anomalous := dischargeBlocks(4, constantRate(5))
+	anomalous[1].Anomaly = true
+	// Unknown status blocks losing charge slowly do not dilute the discharge rate.
+	unknown := dischargeBlocks(3, constantRate(1))
+	for i := range unknown {
+		unknown[i].Discharging = false
+	}
+	// Blocks at the same instant leave the trend undefined, so it stays neutral.
+	sameTime := dischargeBlocks(1, constantRate(5))
+	sameTime = append(sameTime, sameTime[0], sameTime[0])
+
+	tests := []struct {
+		name   string
+		blocks []Block
+		fleet  Fleet
+		want   Score
+		ok     bool
+	}{
+		{"no blocks", nil, fleet, Score{Discharge: 1, ChargeAcceptance: 1, AnomalyFree: 1, Trend: 1}, false},
+		{"single block", dischargeBlocks(1, constantRate(5)), fleet, Score{Discharge: 1, ChargeAcceptance: 1, AnomalyFree: 1, Trend: 1, Blocks: 1}, false},
+		{"one block short", dischargeBlocks(MinScoreBlocks-1, constantRate(5)), fleet, Score{Discharge: 1, ChargeAcceptance: 1, AnomalyFree: 1, Trend: 1, Blocks: MinScoreBlocks - 1}, false},
+		{"at the fleet reference", dischargeBlocks(MinScoreBlocks, constantRate(5)), fleet, Score{Value: MaxScore, Discharge: 1, ChargeAcceptance: 1, AnomalyFree: 1, Trend: 1, Blocks: MinScoreBlocks}, true},
+		{"better than the fleet is capped", dischargeBlocks(3, constantRate(2)), fleet, Score{Value: MaxScore, Discharge: 1, ChargeAcceptance: 1, AnomalyFree: 1, Trend: 1, Blocks: 3}, true},
+		{"draining twice as fast", dischargeBlocks(3, constantRate(10)), fleet, Score{Value: 500, Discharge: 0.5, ChargeAcceptance: 1, AnomalyFree: 1, Trend: 1, Blocks: 3}, true},
+		{"unknown status ignored", append(dischargeBlocks(3, constantRate(10)), unknown...), fleet, Score{Value: 500, Discharge: 0.5, ChargeAcceptance: 1, AnomalyFree: 1, Trend: 1, Blocks: 6}, true},
+		{"slow charging", append(dischargeBlocks(3, constantRate(5)), chargeBlocks(2, 10)...), fleet, Score{Value: 500, Discharge: 1, ChargeAcceptance: 0.5, AnomalyFree: 1, Trend: 1, Blocks: 5}, true},
+		{"one anomalous block", anomalous, fleet, Score{Value: 750, Discharge: 1, ChargeAcceptance: 1, AnomalyFree: 0.75, Trend: 1, Blocks: 4}, true},
+		{"no fleet reference", dischargeBlocks(3, constantRate(10)), Fleet{}, Score{Value: MaxScore, Discharge: 1, ChargeAcceptance: 1, AnomalyFree: 1, Trend: 1, Blocks: 3}, true},
+		{"falling rate", dischargeBlocks(5, func(i int) float64 { return 9 - float64(i) }), Fleet{}, Score{Value: MaxScore, Discharge: 1, ChargeAcceptance: 1, AnomalyFree: 1, Trend: 1, Blocks: 5}, true},
+		{"same instant", sameTime, fleet, Score{Value: MaxScore, Discharge: 1, ChargeAcceptance: 1, AnomalyFree: 1, Trend: 1, Blocks: 3}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Compute(tt.blocks, tt.fleet)
+			if ok != tt.ok {
+				t.Fatalf("Compute() ok = %v; want %v", ok, tt.ok)
+			}
+			if !scoreEqual(got, tt.want) {
+				t.Errorf("Compute() = %+v; want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestComputeTrend(t *testing.T) {
+	tests := []struct {
+		name      string
+		rate      func(int) float64
+		wantTrend float64
+	}{
+		// The rate rises by 0.1 a day from a median of 5.95: 3 points over the horizon.
+		{"rising rate", func(i int) float64 { return 5 + 0.1*float64(i) }, 1 - 0.1*trendHorizonDays/5.95},
+		{"rate doubling within the horizon", func(i int) float64 { return 1 + float64(i) }, 0},
+		{"almost flat rate", func(i int) float64 { return 5 + 1e-9*float64(i) }, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Compute(dischargeBlocks(20, tt.rate), Fleet{})
+			if !ok {
+				t.Fatalf("Compute() not scored")
+			}
+			if math.Abs(got.Trend-tt.wantTrend) > 1e-6 {
+				t.Errorf("Compute() trend = %v; want %v", got.Trend, tt.wantTrend)
+			}
+			if math.Abs(got.Value-MaxScore*got.Trend) > 1e-6 {
+				t.Errorf("Compute() value = %v; want %v", got.Value, MaxScore*got.Trend)
+			}
+		})
+	}
+}
+
+func TestMedian(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		want   float64
+		ok     bool
+	}{
+		{"empty", nil, 0, false},
+		{"single value", []float64{3}, 3, true},
+		{"odd count", []float64{9, 1, 5}, 5, true},
+		{"even count", []float64{4, 1, 3, 2}, 2.5, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := append([]float64(nil), tt.values...)
+			got, ok := median(input)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("median(%v) = %v, %v; want %v, %v", tt.values, got, ok, tt.want, tt.ok)
+			}
+			for i := range input {
+				if input[i] != tt.values[i] {
+					t.Fatalf("median() reordered its input to %v", input)
+				}
+			}
+		})
+	}
+}
+
+func scoreEqual(a, b Score) bool {
+	near := func(x, y float64) bool { return math.Abs(x-y) < 1e-9 }
+	return a.Blocks == b.Blocks && near(a.Value, b.Value) && near(a.Discharge, b.Discharge) &&
+		near(a.ChargeAcceptance, b.ChargeAcceptance) && near(a.AnomalyFree, b.AnomalyFree) && near(a.Trend, b.Trend)
+}
@@ -0,0 +1,71 @@
+// Package battery scores, classifies and forecasts device battery health from the
+// battery_health blocks.
+package battery
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Tier is one battery risk band. Devices belong to the first tier, in ascending
+// MaxScore order, whose MaxScore is at or above their score. A nil MaxScore makes
+// the tier the catch-all above every bounded tier.
+type Tier struct {
+	Name     string
+	MaxScore *float64
+	AtRisk   bool
+}
+
+// TierUnassigned is reported for scores above every bounded tier when no catch-all
+// tier is configured.
+const TierUnassigned = "unassigned"
+
+// SortTiers orders tiers by boundary, the catch-all last.
+func SortTiers(tiers []Tier) {
+	sort.SliceStable(tiers, func(i, j int) bool {
+		a, b := tiers[i].MaxScore, tiers[j].MaxScore
+		if a == nil || b == nil {
+			return b == nil && a != nil
+		}
+		return *a < *b
+	})
+}
+
+// ValidateTiers checks that no two tiers share a boundary, so every score maps to
+// exactly one tier.
+func ValidateTiers(tiers []Tier) error {
+	seen := map[string]string{}
+	for _, t := range tiers {
+		key := "catch-all"
+		if t.MaxScore != nil {
+			key = fmt.Sprintf("%g", *t.MaxScore)
+		}
+		if other, ok := seen[key]; ok {
+			return fmt.Errorf("tiers %q and %q share the boundary %s", other, t.Name, key)
+		}
+		seen[key] = t.Name
+	}
+	return nil
+}
+
+// Classify returns the tier of a score among tiers sorted with SortTiers.
+func Classify(tiers []Tier, score float64) (Tier, bool) {
+	for _, t := range tiers {
+		if t.MaxScore == nil || score <= *t.MaxScore {
+			return t, true
+		}
+	}
+	return Tier{Name: TierUnassigned}, false
+}
+
+// AtRiskScore is the highest score still classified at risk, or false when no
+// bounded tier is at risk.
+func AtRiskScore(tiers []Tier) (float64, bool) {
+	best, found := 0.0, false
+	for _, t := range tiers {
+		if t.AtRisk && t.MaxScore != nil && (!found || *t.MaxScore > best) {
+			best, found = *t.MaxScore, true
+		}
+	}
+	return best, found
+}
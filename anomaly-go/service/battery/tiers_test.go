@@ -0,0 +1,120 @@
+package battery
+
+import (
+	"reflect"
+	"testing"
+)
+
+func bound(v float64) *float64 { return &v }
+
+// defaultTiers are the built-in tiers, sorted.
+func defaultTiers() []Tier {
+	return []Tier{
+		{Name: "critical", MaxScore: bound(100), AtRisk: true},
+		{Name: "at_risk", MaxScore: bound(300), AtRisk: true},
+		{Name: "watch", MaxScore: bound(600)},
+		{Name: "healthy"},
+	}
+}
+
+func tierNames(tiers []Tier) []string {
+	names := make([]string, 0, len(tiers))
+	for _, t := range tiers {
+		names = append(names, t.Name)
+	}
+	return names
+}
+
+func TestSortTiers(t *testing.T) {
+	tests := []struct {
+		name  string
+		tiers []Tier
+		want  []string
+	}{
+		{"empty", nil, []string{}},
+		{"single catch-all", []Tier{{Name: "all"}}, []string{"all"}},
+		{"already sorted", defaultTiers(), []string{"critical", "at_risk", "watch", "healthy"}},
+		{"catch-all first", []Tier{{Name: "healthy"}, {Name: "watch", MaxScore: bound(600)}, {Name: "critical", MaxScore: bound(100)}}, []string{"critical", "watch", "healthy"}},
+		{"negative and zero bounds", []Tier{{Name: "zero", MaxScore: bound(0)}, {Name: "negative", MaxScore: bound(-1)}}, []string{"negative", "zero"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SortTiers(tt.tiers)
+			if got := tierNames(tt.tiers); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SortTiers() = %v; want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateTiers(t *testing.T) {
+	tests := []struct {
+		name    string
+		tiers   []Tier
+		wantErr bool
+	}{
+		{"empty", nil, false},
+		{"defaults", defaultTiers(), false},
+		{"shared boundary", []Tier{{Name: "a", MaxScore: bound(100)}, {Name: "b", MaxScore: bound(100)}}, true},
+		{"two catch-alls", []Tier{{Name: "a"}, {Name: "b"}}, true},
+		{"close boundaries", []Tier{{Name: "a", MaxScore: bound(100)}, {Name: "b", MaxScore: bound(100.5)}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateTiers(tt.tiers); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateTiers() error = %v; want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestClassify(t *testing.T) {
+	bounded := defaultTiers()[:3]
+	tests := []struct {
+		name  string
+		tiers []Tier
+		score float64
+		want  string
+		ok    bool
+	}{
+		{"no tiers", nil, 500, TierUnassigned, false},
+		{"zero score", defaultTiers(), 0, "critical", true},
+		{"at a boundary", defaultTiers(), 100, "critical", true},
+		{"just above a boundary", defaultTiers(), 100.01, "at_risk", true},
+		{"top bounded tier", defaultTiers(), 600, "watch", true},
+		{"catch-all", defaultTiers(), MaxScore, "healthy", true},
+		{"above every bound without catch-all", bounded, 601, TierUnassigned, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Classify(tt.tiers, tt.score)
+			if got.Name != tt.want || ok != tt.ok {
+				t.Errorf("Classify(%v) = %q, %v; want %q, %v", tt.score, got.Name, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestAtRiskScore(t *testing.T) {
+	tests := []struct {
+		name  string
+		tiers []Tier
+		want  float64
+		ok    bool
+	}{
+		{"no tiers", nil, 0, false},
+		{"defaults", defaultTiers(), 300, true},
+		{"no at-risk tier", []Tier{{Name: "watch", MaxScore: bound(600)}, {Name: "healthy"}}, 0, false},
+		{"at-risk catch-all only", []Tier{{Name: "everything", AtRisk: true}}, 0, false},
+		{"zero bound", []Tier{{Name: "dead", MaxScore: bound(0), AtRisk: true}}, 0, true},
+		{"unsorted", []Tier{{Name: "b", MaxScore: bound(300), AtRisk: true}, {Name: "a", MaxScore: bound(100), AtRisk: true}}, 300, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := AtRiskScore(tt.tiers)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("AtRiskScore() = %v, %v; want %v, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,186 @@
+package battery
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+const (
+	// LowLevel is the battery level, in percent, below which time counts as spent low.
+	LowLevel = 20
+	// TopUpMaxGain is the most a charge may add, in percentage points, to count as a
+	// top-up rather than a full charge.
+	TopUpMaxGain = 20
+	// Overnight charges start at or after OvernightStartHour or before
+	// OvernightEndHour on the device's wall clock.
+	OvernightStartHour = 22
+	OvernightEndHour   = 6
+)
+
+// Wear accumulates the charge cycles and charging habits of a device over its
+// blocks. It is built incrementally: Add each new block once, in any order, and
+// Remove a block added before it is replaced by a new version.
+type Wear struct {
+	Blocks int64
+	// ChargedPercent and DischargedPercent are the percentage points gained while
+	// charging and lost while discharging.
+	ChargedPercent    float64
+	DischargedPercent float64
+	DischargeBlocks   int64
+	ChargeBlocks      int64
+	OvernightCharges  int64
+	TopUps            int64
+	// LowSeconds is the time spent below LowLevel out of ObservedSeconds.
+	LowSeconds      float64
+	ObservedSeconds float64
+	First           time.Time
+	Last            time.Time
+}
+
+// NewWear returns an empty accumulator.
+func NewWear() *Wear {
+	return &Wear{}
+}
+
+// Add folds one block into the accumulator.
+func (w *Wear) Add(b Block) {
+	if w.First.IsZero() || b.Start.Before(w.First) {
+		w.First = b.Start
+	}
+	if b.End.After(w.Last) {
+		w.Last = b.End
+	}
+	w.fold(b, 1)
+}
+
+// Remove takes a block added before back out of the accumulator. First and Last
+// keep the range observed so far.
+func (w *Wear) Remove(b Block) {
+	w.fold(b, -1)
+}
+
+// fold adds a block to the totals with weight 1, or removes it with weight -1.
+func (w *Wear) fold(b Block, n int64) {
+	w.Blocks += n
+
+	duration := b.End.Sub(b.Start).Seconds()
+	if duration > 0 {
+		w.ObservedSeconds += float64(n) * duration
+		w.LowSeconds += float64(n) * duration * lowShare(b.StartLevel, b.EndLevel)
+	}
+
+	switch {
+	case b.Discharging && b.EndLevel < b.StartLevel:
+		w.DischargeBlocks += n
+		w.DischargedPercent += float64(n) * (b.StartLevel - b.EndLevel)
+	case b.Charging:
+		w.ChargeBlocks += n
+		gain := math.Max(0, b.EndLevel-b.StartLevel)
+		w.ChargedPercent += float64(n) * gain
+		if gain < TopUpMaxGain {
+			w.TopUps += n
+		}
+		if h := b.Start.Hour(); h >= OvernightStartHour || h < OvernightEndHour {
+			w.OvernightCharges += n
+		}
+	}
+}
+
+// Cycles is the number of equivalent full charge cycles: the total discharge in
+// units of 100 percentage points.
+func (w *Wear) Cycles() float64 {
+	return w.DischargedPercent / 100
+}
+
+// AvgDepthOfDischarge is the mean level lost per discharge block, in percentage
+// points, or 0 without any.
+func (w *Wear) AvgDepthOfDischarge() float64 {
+	if w.DischargeBlocks == 0 {
+		return 0
+	}
+	return w.DischargedPercent / float64(w.DischargeBlocks)
+}
+
+// LowShare is the share of observed time spent below LowLevel.
+func (w *Wear) LowShare() float64 {
+	if w.ObservedSeconds == 0 {
+		return 0
+	}
+	return w.LowSeconds / w.ObservedSeconds
+}
+
+// OvernightShare is the share of charges started overnight.
+func (w *Wear) OvernightShare() float64 {
+	if w.ChargeBlocks == 0 {
+		return 0
+	}
+	return float64(w.OvernightCharges) / float64(w.ChargeBlocks)
+}
+
+// TopUpShare is the share of charges that were top-ups.
+func (w *Wear) TopUpShare() float64 {
+	if w.ChargeBlocks == 0 {
+		return 0
+	}
+	return float64(w.TopUps) / float64(w.ChargeBlocks)
+}
+
+// lowShare is the share of a block spent below LowLevel, assuming the level moves
+// linearly from start to end.
+func lowShare(start, end float64) float64 {
+	lo, hi := math.Min(start, end), math.Max(start, end)
+	switch {
+	case hi <= LowLevel:
+		return 1
+	case lo >= LowLevel:
+		return 0
+	default:
+		return (LowLevel - lo) / (hi - lo)
+	}
+}
+
+// Distribution summarizes a metric over the fleet.
+type Distribution struct {
+	Devices int
+	Mean    float64
+	Min     float64
+	P10     float64
+	P25     float64
+	P50     float64
+	P75     float64
+	P90     float64
+	Max     float64
+}
+
+// Distribute summarizes values, which it sorts in place.
+func Distribute(values []float64) Distribution {
+	d := Distribution{Devices: len(values)}
+	if len(values) == 0 {
+		return d
+	}
+	sort.Float64s(values)
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	d.Mean = sum / float64(len(values))
+	d.Min = values[0]
+	d.Max = values[len(values)-1]
+	d.P10 = quantile(values, 0.10)
+	d.P25 = quantile(values, 0.25)
+	d.P50 = quantile(values, 0.50)
+	d.P75 = quantile(values, 0.75)
+	d.P90 = quantile(values, 0.90)
+	return d
+}
+
+// quantile interpolates the q-quantile of sorted values.
+func quantile(sorted []float64, q float64) float64 {
+	pos := q * float64(len(sorted)-1)
+	i := int(pos)
+	if i+1 >= len(sorted) {
+		return sorted[len(sorted)-1]
+	}
+	return sorted[i] + (pos-float64(i))*(sorted[i+1]-sorted[i])
+}
@@ -0,0 +1,192 @@
+package battery
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// at returns a block of the given length starting at the given wall-clock hour of
+// the test day.
+func at(hour int, d time.Duration, from, to float64, charge bool) Block {
+	start := time.Date(2026, 3, 2, hour, 0, 0, 0, time.UTC)
+	return Block{Start: start, End: start.Add(d), StartLevel: from, EndLevel: to, Charging: charge, Discharging: !charge}
+}
+
+func TestWearAdd(t *testing.T) {
+	tests := []struct {
+		name          string
+		blocks        []Block
+		wantDischarge int64
+		wantCharge    int64
+		wantOvernight int64
+		wantTopUps    int64
+		wantCycles    float64
+		wantCharged   float64
+	}{
+		{"no blocks", nil, 0, 0, 0, 0, 0, 0},
+		{"single discharge", []Block{at(9, time.Hour, 80, 30, false)}, 1, 0, 0, 0, 0.5, 0},
+		{"discharge without drop", []Block{at(9, time.Hour, 50, 52, false)}, 0, 0, 0, 0, 0, 0},
+		{"unknown status", []Block{{Start: testNow, End: testNow.Add(time.Hour), StartLevel: 80, EndLevel: 30}}, 0, 0, 0, 0, 0, 0},
+		{"two full cycles", []Block{at(8, time.Hour, 100, 0, false), at(12, time.Hour, 100, 0, false)}, 2, 0, 0, 0, 2, 0},
+		{"full charge at 22:00", []Block{at(22, time.Hour, 10, 90, true)}, 0, 1, 1, 0, 0, 80},
+		{"top-up in the afternoon", []Block{at(14, time.Hour, 50, 60, true)}, 0, 1, 0, 1, 0, 10},
+		{"charge at 06:00 is not overnight", []Block{at(6, time.Hour, 10, 90, true)}, 0, 1, 0, 0, 0, 80},
+		{"charge at 05:00 is overnight", []Block{at(5, time.Hour, 10, 90, true)}, 0, 1, 1, 0, 0, 80},
+		{"gain at the top-up limit", []Block{at(14, time.Hour, 50, 50+TopUpMaxGain, true)}, 0, 1, 0, 0, 0, TopUpMaxGain},
+		{"charge losing level", []Block{at(14, time.Hour, 50, 48, true)}, 0, 1, 0, 1, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := NewWear()
+			for _, b := range tt.blocks {
+				w.Add(b)
+			}
+			if w.Blocks != int64(len(tt.blocks)) {
+				t.Errorf("Blocks = %d; want %d", w.Blocks, len(tt.blocks))
+			}
+			if w.DischargeBlocks != tt.wantDischarge || w.ChargeBlocks != tt.wantCharge || w.OvernightCharges != tt.wantOvernight || w.TopUps != tt.wantTopUps {
+				t.Errorf("discharges, charges, overnight, top-ups = %d, %d, %d, %d; want %d, %d, %d, %d",
+					w.DischargeBlocks, w.ChargeBlocks, w.OvernightCharges, w.TopUps, tt.wantDischarge, tt.wantCharge, tt.wantOvernight, tt.wantTopUps)
+			}
+			if math.Abs(w.Cycles()-tt.wantCycles) > 1e-9 || math.Abs(w.ChargedPercent-tt.wantCharged) > 1e-9 {
+				t.Errorf("Cycles() = %v, ChargedPercent = %v; want %v, %v", w.Cycles(), w.ChargedPercent, tt.wantCycles, tt.wantCharged)
+			}
+		})
+	}
+}
+
+func TestWearRemoveReplacedBlock(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new Block
+	}{
+		{"deeper discharge", at(9, time.Hour, 80, 60, false), at(9, time.Hour, 80, 30, false)},
+		{"longer block", at(9, time.Hour, 80, 60, false), at(9, 3*time.Hour, 80, 60, false)},
+		{"discharge now charging", at(9, time.Hour, 30, 10, false), at(9, time.Hour, 10, 30, true)},
+		{"overnight charge moved to the afternoon", at(23, time.Hour, 10, 90, true), at(14, time.Hour, 50, 60, true)},
+		{"unchanged", at(9, time.Hour, 80, 60, false), at(9, time.Hour, 80, 60, false)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			other := at(4, time.Hour, 100, 80, false)
+
+			got := NewWear()
+			got.Add(other)
+			got.Add(tt.old)
+			got.Remove(tt.old)
+			got.Add(tt.new)
+
+			want := NewWear()
+			want.Add(other)
+			want.Add(tt.new)
+
+			// First and Last keep the range of every version seen.
+			got.First, got.Last = want.First, want.Last
+			if !wearEqual(*got, *want) {
+				t.Errorf("replaced block = %+v; want %+v", *got, *want)
+			}
+		})
+	}
+}
+
+func wearEqual(a, b Wear) bool {
+	near := func(x, y float64) bool { return math.Abs(x-y) < 1e-9 }
+	return a.Blocks == b.Blocks && a.DischargeBlocks == b.DischargeBlocks && a.ChargeBlocks == b.ChargeBlocks &&
+		a.OvernightCharges == b.OvernightCharges && a.TopUps == b.TopUps &&
+		near(a.ChargedPercent, b.ChargedPercent) && near(a.DischargedPercent, b.DischargedPercent) &&
+		near(a.LowSeconds, b.LowSeconds) && near(a.ObservedSeconds, b.ObservedSeconds) &&
+		a.First.Equal(b.First) && a.Last.Equal(b.Last)
+}
+
+func TestWearShares(t *testing.T) {
+	empty := NewWear()
+	if empty.AvgDepthOfDischarge() != 0 || empty.LowShare() != 0 || empty.OvernightShare() != 0 || empty.TopUpShare() != 0 {
+		t.Errorf("empty accumulator shares = %v, %v, %v, %v; want zeros",
+			empty.AvgDepthOfDischarge(), empty.LowShare(), empty.OvernightShare(), empty.TopUpShare())
+	}
+
+	w := NewWear()
+	w.Add(at(8, 2*time.Hour, 30, 10, false))
+	w.Add(at(12, 2*time.Hour, 60, 40, false))
+	w.Add(at(23, time.Hour, 10, 90, true))
+	w.Add(at(15, 0, 40, 45, true))
+
+	tests := []struct {
+		name string
+		got  float64
+		want float64
+	}{
+		{"average depth of discharge", w.AvgDepthOfDischarge(), 20},
+		// One hour of the first block and the hour-long charge from 10% spends an
+		// eighth of it below 20%, out of 5 observed hours.
+		{"low share", w.LowShare(), (1 + 0.125) / 5},
+		{"overnight share", w.OvernightShare(), 0.5},
+		{"top-up share", w.TopUpShare(), 0.5},
+	}
+	for _, tt := range tests {
+		if math.Abs(tt.got-tt.want) > 1e-9 {
+			t.Errorf("%s = %v; want %v", tt.name, tt.got, tt.want)
+		}
+	}
+	if want := 5 * time.Hour; time.Duration(w.ObservedSeconds*float64(time.Second)) != want {
+		t.Errorf("ObservedSeconds = %v; want %v", w.ObservedSeconds, want.Seconds())
+	}
+}
+
+func TestWearAddOutOfOrder(t *testing.T) {
+	w := NewWear()
+	late, early := at(20, time.Hour, 80, 60, false), at(4, time.Hour, 100, 80, false)
+	w.Add(late)
+	w.Add(early)
+	if !w.First.Equal(early.Start) || !w.Last.Equal(late.End) {
+		t.Errorf("First, Last = %v, %v; want %v, %v", w.First, w.Last, early.Start, late.End)
+	}
+}
+
+func TestLowShare(t *testing.T) {
+	tests := []struct {
+		name       string
+		start, end float64
+		want       float64
+	}{
+		{"all above", 80, 40, 0},
+		{"ends at the threshold", 40, LowLevel, 0},
+		{"all below", 15, 5, 1},
+		{"flat at the threshold", LowLevel, LowLevel, 1},
+		{"flat below", 10, 10, 1},
+		{"crossing down", 30, 10, 0.5},
+		{"crossing up", 10, 50, 0.25},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := lowShare(tt.start, tt.end); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("lowShare(%v, %v) = %v; want %v", tt.start, tt.end, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDistribute(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		want   Distribution
+	}{
+		{"empty", nil, Distribution{}},
+		{"single value", []float64{7}, Distribution{Devices: 1, Mean: 7, Min: 7, P10: 7, P25: 7, P50: 7, P75: 7, P90: 7, Max: 7}},
+		{"unsorted", []float64{5, 1, 4, 2, 3}, Distribution{Devices: 5, Mean: 3, Min: 1, P10: 1.4, P25: 2, P50: 3, P75: 4, P90: 4.6, Max: 5}},
+		{"two values", []float64{10, 0}, Distribution{Devices: 2, Mean: 5, Min: 0, P10: 1, P25: 2.5, P50: 5, P75: 7.5, P90: 9, Max: 10}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Distribute(tt.values)
+			near := func(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
+			if got.Devices != tt.want.Devices || !near(got.Mean, tt.want.Mean) || !near(got.Min, tt.want.Min) ||
+				!near(got.P10, tt.want.P10) || !near(got.P25, tt.want.P25) || !near(got.P50, tt.want.P50) ||
+				!near(got.P75, tt.want.P75) || !near(got.P90, tt.want.P90) || !near(got.Max, tt.want.Max) {
+				t.Errorf("Distribute(%v) = %+v; want %+v", tt.values, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,257 @@
+// Package peers groups devices with similar activity and places each device against
+// its peer group.
+package peers
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Metrics a device is benchmarked on.
+const (
+	MetricVolume       = "volume"
+	MetricAnomalyRate  = "anomaly_rate"
+	MetricAvgAmount    = "avg_amount"
+	MetricBatteryScore = "battery_score"
+)
+
+// Band labels.
+const (
+	BandLow    = "low"
+	BandMedium = "medium"
+	BandHigh   = "high"
+
+	HoursNight     = "night"
+	HoursMorning   = "morning"
+	HoursAfternoon = "afternoon"
+	HoursEvening   = "evening"
+)
+
+const (
+	// MinPeers is the peer group size below which grouping falls back to coarser keys.
+	MinPeers = 5
+	// OutlierZ is the robust z-score (median and MAD based) from which a device is
+	// flagged as outside its peer group.
+	OutlierZ = 3.5
+)
+
+// Device is the activity profile of one device.
+type Device struct {
+	ID           int64
+	Transactions int
+	AnomalyRate  float64
+	AvgAmount    float64
+	MedianAmount float64
+	PeakHour     int
+	// BatteryScore is nil when the device has no battery score.
+	BatteryScore *float64
+}
+
+// Bands places a device on each grouping dimension.
+type Bands struct {
+	Volume string
+	Ticket string
+	Hours  string
+}
+
+// MetricPlacement is a device metric against its peers. Percentile is the share of
+// peers below the device, counting ties as half.
+type MetricPlacement struct {
+	Value      float64
+	Percentile float64
+	PeerMedian float64
+	RobustZ    float64
+	Outlier    bool
+}
+
+// Placement is a device against its peer group.
+type Placement struct {
+	DeviceID  int64
+	Bands     Bands
+	PeerGroup string
+	Peers     int
+	Metrics   map[string]MetricPlacement
+	Outlier   bool
+}
+
+// Benchmark groups the devices and places each of them against its peer group. The
+// group is the devices sharing the volume band, ticket size band and active hours;
+// when it has fewer than MinPeers devices, the active hours and then the ticket size
+// are dropped, down to the whole fleet.
+func Benchmark(devices []Device) []Placement {
+	volumeBand := tertileBands(devices, func(d Device) float64 { return float64(d.Transactions) })
+	ticketBand := tertileBands(devices, func(d Device) float64 { return d.MedianAmount })
+
+	bands := make([]Bands, len(devices))
+	groups := map[string][]int{}
+	for i, d := range devices {
+		b := Bands{Volume: volumeBand(float64(d.Transactions)), Ticket: ticketBand(d.MedianAmount), Hours: activeHours(d.PeakHour)}
+		bands[i] = b
+		for _, key := range groupKeys(b) {
+			groups[key] = append(groups[key], i)
+		}
+	}
+
+	placements := make([]Placement, 0, len(devices))
+	for i, d := range devices {
+		key, members := "", []int(nil)
+		for _, k := range groupKeys(bands[i]) {
+			key, members = k, groups[k]
+			if len(members) >= MinPeers {
+				break
+			}
+		}
+
+		p := Placement{DeviceID: d.ID, Bands: bands[i], PeerGroup: key, Peers: len(members) - 1, Metrics: map[string]MetricPlacement{}}
+		for _, metric := range []string{MetricVolume, MetricAnomalyRate, MetricAvgAmount, MetricBatteryScore} {
+			value, ok := metricValue(d, metric)
+			if !ok {
+				continue
+			}
+			var peerValues []float64
+			for _, j := range members {
+				if j == i {
+					continue
+				}
+				if v, ok := metricValue(devices[j], metric); ok {
+					peerValues = append(peerValues, v)
+				}
+			}
+			mp := place(value, peerValues)
+			p.Metrics[metric] = mp
+			p.Outlier = p.Outlier || mp.Outlier
+		}
+		placements = append(placements, p)
+	}
+	return placements
+}
+
+// groupKeys returns the peer group keys of a device from the finest to the coarsest.
+func groupKeys(b Bands) []string {
+	return []string{
+		fmt.Sprintf("volume:%s/ticket:%s/hours:%s", b.Volume, b.Ticket, b.Hours),
+		fmt.Sprintf("volume:%s/ticket:%s", b.Volume, b.Ticket),
+		fmt.Sprintf("volume:%s", b.Volume),
+		"fleet",
+	}
+}
+
+func metricValue(d Device, metric string) (float64, bool) {
+	switch metric {
+	case MetricVolume:
+		return float64(d.Transactions), true
+	case MetricAnomalyRate:
+		return d.AnomalyRate, true
+	case MetricAvgAmount:
+		return d.AvgAmount, true
+	case MetricBatteryScore:
+		if d.BatteryScore == nil {
+			return 0, false
+		}
+		return *d.BatteryScore, true
+	}
+	return 0, false
+}
+
+// place computes the percentile and robust z-score of value among peers.
+func place(value float64, peers []float64) MetricPlacement {
+	mp := MetricPlacement{Value: value}
+	if len(peers) == 0 {
+		return mp
+	}
+
+	below, equal := 0, 0
+	for _, v := range peers {
+		switch {
+		case v < value:
+			below++
+		case v == value:
+			equal++
+		}
+	}
+	mp.Percentile = 100 * (float64(below) + 0.5*float64(equal)) / float64(len(peers))
+
+	mp.PeerMedian = median(peers)
+	deviations := make([]float64, len(peers))
+	for i, v := range peers {
+		deviations[i] = math.Abs(v - mp.PeerMedian)
+	}
+	// 1.4826 scales the MAD to the standard deviation of a normal distribution. When
+	// more than half the peers share the median, the mean absolute deviation (scaled
+	// by 1.2533) is used instead; when all peers are equal, any difference is reported
+	// at the outlier limit.
+	spread := 1.4826 * median(deviations)
+	if spread == 0 {
+		spread = 1.2533 * mean(deviations)
+	}
+	switch {
+	case spread > 0:
+		mp.RobustZ = (value - mp.PeerMedian) / spread
+	case value != mp.PeerMedian:
+		mp.RobustZ = math.Copysign(OutlierZ, value-mp.PeerMedian)
+	}
+	mp.Outlier = len(peers) >= MinPeers-1 && math.Abs(mp.RobustZ) >= OutlierZ
+	return mp
+}
+
+func mean(values []float64) float64 {
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+// tertileBands returns a function placing a value in the low, medium or high third
+// of the fleet.
+func tertileBands(devices []Device, value func(Device) float64) func(float64) string {
+	values := make([]float64, len(devices))
+	for i, d := range devices {
+		values[i] = value(d)
+	}
+	sort.Float64s(values)
+	low, high := quantile(values, 1.0/3), quantile(values, 2.0/3)
+
+	return func(v float64) string {
+		switch {
+		case v <= low:
+			return BandLow
+		case v <= high:
+			return BandMedium
+		default:
+			return BandHigh
+		}
+	}
+}
+
+// activeHours names the part of the day holding the device's busiest hour.
+func activeHours(peakHour int) string {
+	switch {
+	case peakHour < 6:
+		return HoursNight
+	case peakHour < 12:
+		return HoursMorning
+	case peakHour < 18:
+		return HoursAfternoon
+	default:
+		return HoursEvening
+	}
+}
+
+func median(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	return quantile(sorted, 0.5)
+}
+
+// quantile interpolates the q-quantile of sorted values.
+func quantile(sorted []float64, q float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	pos := q * float64(len(sorted)-1)
+	lo := int(math.Floor(pos))
+	hi := int(math.Ceil(pos))
+	return sorted[lo] + (sorted[hi]-sorted[lo])*(pos-float64(lo))
+}
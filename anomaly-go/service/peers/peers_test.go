@@ -0,0 +1,189 @@
+package peers
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPlace(t *testing.T) {
+	tests := []struct {
+		name           string
+		value          float64
+		peers          []float64
+		wantPercentile float64
+		wantMedian     float64
+		wantZ          float64
+		wantOutlier    bool
+	}{
+		{"no peers", 5, nil, 0, 0, 0, false},
+		{"single peer", 5, []float64{3}, 100, 3, math.Copysign(OutlierZ, 1), false},
+		{"all equal, same value", 10, []float64{10, 10, 10, 10}, 50, 10, 0, false},
+		{"all equal, higher value", 11, []float64{10, 10, 10, 10}, 100, 10, OutlierZ, true},
+		{"all equal, lower value", 9, []float64{10, 10, 10, 10}, 0, 10, -OutlierZ, true},
+		{"all equal, too few peers", 11, []float64{10, 10, 10}, 100, 10, OutlierZ, false},
+		{"at the median", 3, []float64{1, 2, 3, 4, 5}, 50, 3, 0, false},
+		{"far above", 13, []float64{1, 2, 3, 4, 5}, 100, 3, 10 / 1.4826, true},
+		{"zero MAD falls back to mean deviation", 40, []float64{10, 10, 10, 20, 30}, 100, 10, 30 / (1.2533 * 6), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mp := place(tt.value, tt.peers)
+			if mp.Value != tt.value {
+				t.Errorf("place() value = %v; want %v", mp.Value, tt.value)
+			}
+			if math.Abs(mp.Percentile-tt.wantPercentile) > 1e-9 {
+				t.Errorf("place() percentile = %v; want %v", mp.Percentile, tt.wantPercentile)
+			}
+			if math.Abs(mp.PeerMedian-tt.wantMedian) > 1e-9 {
+				t.Errorf("place() peer median = %v; want %v", mp.PeerMedian, tt.wantMedian)
+			}
+			if math.Abs(mp.RobustZ-tt.wantZ) > 1e-9 {
+				t.Errorf("place() robust z = %v; want %v", mp.RobustZ, tt.wantZ)
+			}
+			if mp.Outlier != tt.wantOutlier {
+				t.Errorf("place() outlier = %v; want %v", mp.Outlier, tt.wantOutlier)
+			}
+		})
+	}
+}
+
+func TestQuantile(t *testing.T) {
+	tests := []struct {
+		name   string
+		sorted []float64
+		q      float64
+		want   float64
+	}{
+		{"empty", nil, 0.5, 0},
+		{"single value", []float64{7}, 0.9, 7},
+		{"minimum", []float64{1, 2, 3}, 0, 1},
+		{"maximum", []float64{1, 2, 3}, 1, 3},
+		{"median of even count", []float64{1, 2, 3, 4}, 0.5, 2.5},
+		{"first tertile", []float64{0, 3, 6, 9}, 1.0 / 3, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := quantile(tt.sorted, tt.q); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("quantile(%v, %v) = %v; want %v", tt.sorted, tt.q, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestActiveHours(t *testing.T) {
+	tests := []struct {
+		hour int
+		want string
+	}{
+		{0, HoursNight},
+		{5, HoursNight},
+		{6, HoursMorning},
+		{11, HoursMorning},
+		{12, HoursAfternoon},
+		{17, HoursAfternoon},
+		{18, HoursEvening},
+		{23, HoursEvening},
+	}
+	for _, tt := range tests {
+		if got := activeHours(tt.hour); got != tt.want {
+			t.Errorf("activeHours(%d) = %q; want %q", tt.hour, got, tt.want)
+		}
+	}
+}
+
+func TestTertileBands(t *testing.T) {
+	devices := []Device{{Transactions: 10}, {Transactions: 20}, {Transactions: 30}, {Transactions: 40}}
+	band := tertileBands(devices, func(d Device) float64 { return float64(d.Transactions) })
+	tests := []struct {
+		value float64
+		want  string
+	}{
+		{0, BandLow},
+		{20, BandLow},
+		{25, BandMedium},
+		{30, BandMedium},
+		{31, BandHigh},
+	}
+	for _, tt := range tests {
+		if got := band(tt.value); got != tt.want {
+			t.Errorf("band(%v) = %q; want %q", tt.value, got, tt.want)
+		}
+	}
+
+	same := tertileBands([]Device{{Transactions: 5}, {Transactions: 5}}, func(d Device) float64 { return float64(d.Transactions) })
+	if got := same(5); got != BandLow {
+		t.Errorf("band of an all-equal fleet = %q; want %q", got, BandLow)
+	}
+}
+
+// fleet returns n devices with the same profile.
+func fleet(n int) []Device {
+	devices := make([]Device, n)
+	for i := range devices {
+		devices[i] = Device{ID: int64(i + 1), Transactions: 100, AnomalyRate: 0.01, AvgAmount: 250, MedianAmount: 200, PeakHour: 9}
+	}
+	return devices
+}
+
+func TestBenchmark(t *testing.T) {
+	t.Run("empty", func(t *testing.T) {
+		if got := Benchmark(nil); len(got) != 0 {
+			t.Errorf("Benchmark(nil) = %+v; want none", got)
+		}
+	})
+
+	t.Run("single device", func(t *testing.T) {
+		got := Benchmark(fleet(1))
+		if len(got) != 1 {
+			t.Fatalf("Benchmark() returned %d placements; want 1", len(got))
+		}
+		p := got[0]
+		if p.PeerGroup != "fleet" || p.Peers != 0 || p.Outlier {
+			t.Errorf("Benchmark() = %+v; want the fleet group with no peers", p)
+		}
+		if _, ok := p.Metrics[MetricBatteryScore]; ok {
+			t.Errorf("Benchmark() placed a battery score the device does not have")
+		}
+		if len(p.Metrics) != 3 {
+			t.Errorf("Benchmark() placed %d metrics; want 3", len(p.Metrics))
+		}
+	})
+
+	t.Run("outlier in its group", func(t *testing.T) {
+		devices := fleet(6)
+		devices[5].AnomalyRate = 0.5
+		for _, p := range Benchmark(devices) {
+			if p.PeerGroup != "volume:low/ticket:low/hours:morning" || p.Peers != 5 {
+				t.Errorf("device %d in %q with %d peers; want the full group with 5", p.DeviceID, p.PeerGroup, p.Peers)
+			}
+			if want := p.DeviceID == 6; p.Outlier != want || p.Metrics[MetricAnomalyRate].Outlier != want {
+				t.Errorf("device %d outlier = %v; want %v", p.DeviceID, p.Outlier, want)
+			}
+		}
+	})
+
+	t.Run("small group falls back", func(t *testing.T) {
+		devices := fleet(6)
+		devices[5].PeakHour = 20
+		for _, p := range Benchmark(devices) {
+			want := "volume:low/ticket:low/hours:morning"
+			if p.DeviceID == 6 {
+				want = "volume:low/ticket:low"
+			}
+			if p.PeerGroup != want {
+				t.Errorf("device %d in %q; want %q", p.DeviceID, p.PeerGroup, want)
+			}
+		}
+	})
+
+	t.Run("battery score among peers that have one", func(t *testing.T) {
+		devices := fleet(6)
+		score := 800.0
+		devices[0].BatteryScore = &score
+		devices[1].BatteryScore = &score
+		p := Benchmark(devices)[0]
+		if mp, ok := p.Metrics[MetricBatteryScore]; !ok || mp.Percentile != 50 || mp.PeerMedian != score {
+			t.Errorf("battery score placement = %+v, %v; want the median of one peer", mp, ok)
+		}
+	})
+}
@@ -0,0 +1,130 @@
+package rules
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Feature names reported with each hit.
+const (
+	FeatureWindowCount     = "window_txn_count"
+	FeatureSameAmountCount = "window_same_amount_count"
+	FeatureAmount          = "txn_amt"
+)
+
+// Transaction is the input of the rule engine.
+type Transaction struct {
+	DeviceID int64
+	ID       string
+	Time     time.Time
+	Amount   float64
+}
+
+// History answers the window queries the rules need. Windows are half-open
+// intervals (from, to] and never include the transaction being evaluated.
+type History interface {
+	CountDeviceTransactions(deviceID int64, from, to time.Time) (int64, error)
+	CountDeviceAmount(deviceID int64, amount float64, from, to time.Time) (int64, error)
+	MedianDeviceAmount(deviceID int64, from, to time.Time) (float64, int64, error)
+}
+
+// Hit records that a rule matched a transaction.
+type Hit struct {
+	RuleID   uint
+	RuleName string
+	RuleType string
+	Message  string
+	// Feature names the observed figure: Value is its value and Limit the figure it crossed.
+	Feature string
+	Value   float64
+	Limit   float64
+}
+
+// Engine holds the active rule set. It is safe for concurrent use and the rule set
+// can be replaced at any time.
+type Engine struct {
+	mu    sync.RWMutex
+	rules []Rule
+}
+
+// NewEngine creates an engine without rules.
+func NewEngine() *Engine {
+	return &Engine{}
+}
+
+// Load replaces the active rule set.
+func (e *Engine) Load(rules []Rule) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.rules = rules
+}
+
+// Rules returns the active rule set.
+func (e *Engine) Rules() []Rule {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.rules
+}
+
+// Evaluate runs every active rule against a transaction.
+func (e *Engine) Evaluate(txn Transaction, history History) ([]Hit, error) {
+	var hits []Hit
+	for _, rule := range e.Rules() {
+		hit, matched, err := rule.evaluate(txn, history)
+		if err != nil {
+			return nil, err
+		}
+		if matched {
+			hits = append(hits, hit)
+		}
+	}
+	return hits, nil
+}
+
+func (r Rule) evaluate(txn Transaction, history History) (Hit, bool, error) {
+	from := txn.Time.Add(-r.Window)
+	hit := Hit{RuleID: r.ID, RuleName: r.Name, RuleType: r.Definition.Type}
+
+	switch r.Definition.Type {
+	case TypeVelocity:
+		count, err := history.CountDeviceTransactions(txn.DeviceID, from, txn.Time)
+		if err != nil {
+			return Hit{}, false, fmt.Errorf("rule %q: %w", r.Name, err)
+		}
+		count++ // the incoming transaction
+		if count < int64(r.Definition.MinCount) {
+			return Hit{}, false, nil
+		}
+		hit.Feature, hit.Value, hit.Limit = FeatureWindowCount, float64(count), float64(r.Definition.MinCount)
+		hit.Message = fmt.Sprintf("%d transactions from the device within %s", count, r.Definition.Window)
+
+	case TypeRepeatedAmount:
+		count, err := history.CountDeviceAmount(txn.DeviceID, txn.Amount, from, txn.Time)
+		if err != nil {
+			return Hit{}, false, fmt.Errorf("rule %q: %w", r.Name, err)
+		}
+		count++ // the incoming transaction
+		if count < int64(r.Definition.MinCount) {
+			return Hit{}, false, nil
+		}
+		hit.Feature, hit.Value, hit.Limit = FeatureSameAmountCount, float64(count), float64(r.Definition.MinCount)
+		hit.Message = fmt.Sprintf("Amount %.2f repeated %d times within %s", txn.Amount, count, r.Definition.Window)
+
+	case TypeAmountSpike:
+		median, samples, err := history.MedianDeviceAmount(txn.DeviceID, from, txn.Time)
+		if err != nil {
+			return Hit{}, false, fmt.Errorf("rule %q: %w", r.Name, err)
+		}
+		limit := median * r.Definition.Multiplier
+		if samples < int64(r.Definition.MinHistory) || median <= 0 || txn.Amount <= limit {
+			return Hit{}, false, nil
+		}
+		hit.Feature, hit.Value, hit.Limit = FeatureAmount, txn.Amount, limit
+		hit.Message = fmt.Sprintf("Amount %.2f is above %gx the %s median of %.2f", txn.Amount, r.Definition.Multiplier, r.Definition.Window, median)
+
+	default:
+		return Hit{}, false, nil
+	}
+	return hit, true, nil
+}
@@ -0,0 +1,208 @@
+package rules
+
+import (
+	"errors"
+	"sort"
+	"testing"
+	"time"
+)
+
+// fakeHistory answers the window queries from an in-memory transaction list.
+type fakeHistory struct {
+	txns []Transaction
+	err  error
+}
+
+func (h fakeHistory) window(deviceID int64, from, to time.Time) []Transaction {
+	var out []Transaction
+	for _, txn := range h.txns {
+		if txn.DeviceID == deviceID && txn.Time.After(from) && !txn.Time.After(to) {
+			out = append(out, txn)
+		}
+	}
+	return out
+}
+
+func (h fakeHistory) CountDeviceTransactions(deviceID int64, from, to time.Time) (int64, error) {
+	return int64(len(h.window(deviceID, from, to))), h.err
+}
+
+func (h fakeHistory) CountDeviceAmount(deviceID int64, amount float64, from, to time.Time) (int64, error) {
+	var n int64
+	for _, txn := range h.window(deviceID, from, to) {
+		if txn.Amount == amount {
+			n++
+		}
+	}
+	return n, h.err
+}
+
+func (h fakeHistory) MedianDeviceAmount(deviceID int64, from, to time.Time) (float64, int64, error) {
+	var amounts []float64
+	for _, txn := range h.window(deviceID, from, to) {
+		amounts = append(amounts, txn.Amount)
+	}
+	if len(amounts) == 0 {
+		return 0, 0, h.err
+	}
+	sort.Float64s(amounts)
+	mid := len(amounts) / 2
+	median := amounts[mid]
+	if len(amounts)%2 == 0 {
+		median = (amounts[mid-1] + amounts[mid]) / 2
+	}
+	return median, int64(len(amounts)), h.err
+}
+
+var now = time.Date(2024, 3, 4, 12, 0, 0, 0, time.UTC)
+
+// past returns a transaction of device 1 made ago before now.
+func past(ago time.Duration, amount float64) Transaction {
+	return Transaction{DeviceID: 1, ID: "h", Time: now.Add(-ago), Amount: amount}
+}
+
+func mustCompile(t *testing.T, source string) Rule {
+	t.Helper()
+	rule, err := Compile(1, "rule", source)
+	if err != nil {
+		t.Fatalf("Compile(%q) error = %v", source, err)
+	}
+	return rule
+}
+
+func TestEvaluateVelocity(t *testing.T) {
+	rule := "type: velocity\nwindow: 5m\nmin_count: 3\n"
+
+	tests := []struct {
+		name      string
+		history   []Transaction
+		wantHit   bool
+		wantValue float64
+	}{
+		{"no history", nil, false, 0},
+		{"one short", []Transaction{past(time.Minute, 10)}, false, 0},
+		{"at min count", []Transaction{past(time.Minute, 10), past(4*time.Minute, 10)}, true, 3},
+		{"above min count", []Transaction{past(time.Minute, 10), past(2*time.Minute, 10), past(3*time.Minute, 10)}, true, 4},
+		{"window start is excluded", []Transaction{past(time.Minute, 10), past(5*time.Minute, 10)}, false, 0},
+		{"outside the window", []Transaction{past(time.Minute, 10), past(6*time.Minute, 10)}, false, 0},
+		{"other device", []Transaction{past(time.Minute, 10), {DeviceID: 2, Time: now.Add(-time.Minute)}}, false, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertEvaluate(t, rule, fakeHistory{txns: tt.history}, Transaction{DeviceID: 1, ID: "t", Time: now, Amount: 10}, tt.wantHit, tt.wantValue, 3)
+		})
+	}
+}
+
+func TestEvaluateRepeatedAmount(t *testing.T) {
+	rule := "type: repeated_amount\nwindow: 1h\nmin_count: 3\n"
+
+	tests := []struct {
+		name      string
+		history   []Transaction
+		wantHit   bool
+		wantValue float64
+	}{
+		{"no history", nil, false, 0},
+		{"different amounts", []Transaction{past(time.Minute, 20), past(2*time.Minute, 30)}, false, 0},
+		{"at min count", []Transaction{past(time.Minute, 25), past(59*time.Minute, 25)}, true, 3},
+		{"mixed amounts", []Transaction{past(time.Minute, 25), past(2*time.Minute, 30), past(3*time.Minute, 25), past(4*time.Minute, 25)}, true, 4},
+		{"window start is excluded", []Transaction{past(time.Minute, 25), past(time.Hour, 25)}, false, 0},
+		{"outside the window", []Transaction{past(time.Minute, 25), past(2*time.Hour, 25)}, false, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertEvaluate(t, rule, fakeHistory{txns: tt.history}, Transaction{DeviceID: 1, ID: "t", Time: now, Amount: 25}, tt.wantHit, tt.wantValue, 3)
+		})
+	}
+}
+
+func TestEvaluateAmountSpike(t *testing.T) {
+	rule := "type: amount_spike\nwindow: 30d\nmultiplier: 3\nmin_history: 3\n"
+	day := 24 * time.Hour
+	history := []Transaction{past(day, 10), past(2*day, 20), past(3*day, 30)}
+
+	tests := []struct {
+		name    string
+		history []Transaction
+		amount  float64
+		wantHit bool
+	}{
+		{"above the limit", history, 61, true},
+		{"at the limit", history, 60, false},
+		{"below the limit", history, 45, false},
+		{"too little history", history[:2], 100, false},
+		{"history outside the window", append([]Transaction{past(31*day, 10)}, history[:2]...), 100, false},
+		{"zero median", []Transaction{past(day, 0), past(2*day, 0), past(3*day, 0)}, 100, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertEvaluate(t, rule, fakeHistory{txns: tt.history}, Transaction{DeviceID: 1, ID: "t", Time: now, Amount: tt.amount}, tt.wantHit, tt.amount, 60)
+		})
+	}
+}
+
+func assertEvaluate(t *testing.T, source string, history History, txn Transaction, wantHit bool, wantValue, wantLimit float64) {
+	t.Helper()
+	engine := NewEngine()
+	engine.Load([]Rule{mustCompile(t, source)})
+
+	hits, err := engine.Evaluate(txn, history)
+	if err != nil {
+		t.Fatalf("Evaluate() error = %v", err)
+	}
+	if !wantHit {
+		if len(hits) != 0 {
+			t.Errorf("Evaluate() = %+v; want no hits", hits)
+		}
+		return
+	}
+	if len(hits) != 1 {
+		t.Fatalf("Evaluate() = %+v; want one hit", hits)
+	}
+	hit := hits[0]
+	if hit.RuleID != 1 || hit.RuleName != "rule" || hit.Message == "" {
+		t.Errorf("Evaluate() hit = %+v; want rule 1 with a message", hit)
+	}
+	if hit.Value != wantValue || hit.Limit != wantLimit {
+		t.Errorf("Evaluate() value, limit = %v, %v; want %v, %v", hit.Value, hit.Limit, wantValue, wantLimit)
+	}
+}
+
+func TestEvaluateMultipleRules(t *testing.T) {
+	engine := NewEngine()
+	engine.Load([]Rule{
+		mustCompile(t, "type: velocity\nwindow: 5m\nmin_count: 2\n"),
+		mustCompile(t, "type: repeated_amount\nwindow: 5m\nmin_count: 2\n"),
+	})
+	history := fakeHistory{txns: []Transaction{past(time.Minute, 10)}}
+
+	hits, err := engine.Evaluate(Transaction{DeviceID: 1, Time: now, Amount: 20}, history)
+	if err != nil {
+		t.Fatalf("Evaluate() error = %v", err)
+	}
+	if len(hits) != 1 || hits[0].RuleType != TypeVelocity {
+		t.Errorf("Evaluate() = %+v; want only the velocity hit", hits)
+	}
+}
+
+func TestEvaluateHistoryError(t *testing.T) {
+	engine := NewEngine()
+	engine.Load([]Rule{mustCompile(t, "type: velocity\nwindow: 5m\nmin_count: 2\n")})
+	errHistory := errors.New("connection reset")
+
+	_, err := engine.Evaluate(Transaction{DeviceID: 1, Time: now}, fakeHistory{err: errHistory})
+	if !errors.Is(err, errHistory) {
+		t.Errorf("Evaluate() error = %v; want it to wrap %v", err, errHistory)
+	}
+}
+
+func TestEvaluateWithoutRules(t *testing.T) {
+	hits, err := NewEngine().Evaluate(Transaction{DeviceID: 1, Time: now}, fakeHistory{})
+	if err != nil || len(hits) != 0 {
+		t.Errorf("Evaluate() = %+v, %v; want no hits", hits, err)
+	}
+}
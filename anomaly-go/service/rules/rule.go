@@ -0,0 +1,99 @@
+// Package rules implements the declarative fraud rule engine. Rules are stored as
+// JSON or YAML documents and evaluated against every ingested transaction over
+// sliding windows of the device's history.
+package rules
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// Supported rule types.
+const (
+	// TypeVelocity hits when the device has at least MinCount transactions in Window,
+	// the incoming one included.
+	TypeVelocity = "velocity"
+	// TypeAmountSpike hits when the amount exceeds Multiplier times the device's median
+	// amount over Window, provided at least MinHistory transactions back the median.
+	TypeAmountSpike = "amount_spike"
+	// TypeRepeatedAmount hits when the same amount appears at least MinCount times on
+	// the device in Window, the incoming one included.
+	TypeRepeatedAmount = "repeated_amount"
+)
+
+// Definition is the stored form of a rule. YAML is a superset of JSON, so both
+// formats are accepted. Windows use Go duration syntax with an extra "d" unit for days,
+// e.g. "5m", "1h" or "30d".
+type Definition struct {
+	Type       string  `yaml:"type"        json:"type"`
+	Window     string  `yaml:"window"      json:"window"`
+	MinCount   int     `yaml:"min_count"   json:"min_count,omitempty"`
+	Multiplier float64 `yaml:"multiplier"  json:"multiplier,omitempty"`
+	MinHistory int     `yaml:"min_history" json:"min_history,omitempty"`
+}
+
+// Rule is a parsed and validated rule ready for evaluation.
+type Rule struct {
+	ID         uint
+	Name       string
+	Definition Definition
+	Window     time.Duration
+}
+
+// Compile parses a rule document and validates it.
+func Compile(id uint, name, source string) (Rule, error) {
+	var def Definition
+	if err := yaml.Unmarshal([]byte(source), &def); err != nil {
+		return Rule{}, fmt.Errorf("rule %q: invalid definition: %w", name, err)
+	}
+
+	window, err := ParseWindow(def.Window)
+	if err != nil {
+		return Rule{}, fmt.Errorf("rule %q: %w", name, err)
+	}
+
+	switch def.Type {
+	case TypeVelocity, TypeRepeatedAmount:
+		if def.MinCount < 2 {
+			return Rule{}, fmt.Errorf("rule %q: min_count must be at least 2", name)
+		}
+	case TypeAmountSpike:
+		if def.Multiplier <= 1 {
+			return Rule{}, fmt.Errorf("rule %q: multiplier must be greater than 1", name)
+		}
+		if def.MinHistory < 1 {
+			def.MinHistory = 1
+		}
+	default:
+		return Rule{}, fmt.Errorf("rule %q: unknown type %q", name, def.Type)
+	}
+
+	return Rule{ID: id, Name: name, Definition: def, Window: window}, nil
+}
+
+// ParseWindow parses a Go duration, additionally accepting whole days such as "30d".
+func ParseWindow(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	var window time.Duration
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid window %q", value)
+		}
+		window = time.Duration(n) * 24 * time.Hour
+	} else {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return 0, fmt.Errorf("invalid window %q", value)
+		}
+		window = d
+	}
+	if window <= 0 {
+		return 0, fmt.Errorf("window must be positive")
+	}
+	return window, nil
+}
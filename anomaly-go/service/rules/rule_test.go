@@ -0,0 +1,140 @@
+package rules
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseWindow(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"minutes", "5m", 5 * time.Minute, false},
+		{"hours", "1h", time.Hour, false},
+		{"compound", "1h30m", 90 * time.Minute, false},
+		{"days", "30d", 30 * 24 * time.Hour, false},
+		{"surrounding spaces", " 2d ", 48 * time.Hour, false},
+		{"fractional days", "1.5d", 0, true},
+		{"zero", "0s", 0, true},
+		{"negative", "-5m", 0, true},
+		{"zero days", "0d", 0, true},
+		{"no unit", "5", 0, true},
+		{"empty", "", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseWindow(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseWindow(%q) error = %v; wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseWindow(%q) = %v; want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		name       string
+		source     string
+		want       Definition
+		wantWindow time.Duration
+		wantErr    string
+	}{
+		{
+			name:       "velocity yaml",
+			source:     "type: velocity\nwindow: 5m\nmin_count: 3\n",
+			want:       Definition{Type: TypeVelocity, Window: "5m", MinCount: 3},
+			wantWindow: 5 * time.Minute,
+		},
+		{
+			name:       "velocity json",
+			source:     `{"type": "velocity", "window": "1h", "min_count": 10}`,
+			want:       Definition{Type: TypeVelocity, Window: "1h", MinCount: 10},
+			wantWindow: time.Hour,
+		},
+		{
+			name:       "repeated amount yaml",
+			source:     "type: repeated_amount\nwindow: 1d\nmin_count: 2\n",
+			want:       Definition{Type: TypeRepeatedAmount, Window: "1d", MinCount: 2},
+			wantWindow: 24 * time.Hour,
+		},
+		{
+			name:       "amount spike json",
+			source:     `{"type": "amount_spike", "window": "30d", "multiplier": 5, "min_history": 10}`,
+			want:       Definition{Type: TypeAmountSpike, Window: "30d", Multiplier: 5, MinHistory: 10},
+			wantWindow: 30 * 24 * time.Hour,
+		},
+		{
+			name:       "amount spike defaults min history",
+			source:     "type: amount_spike\nwindow: 7d\nmultiplier: 2.5\n",
+			want:       Definition{Type: TypeAmountSpike, Window: "7d", Multiplier: 2.5, MinHistory: 1},
+			wantWindow: 7 * 24 * time.Hour,
+		},
+		{
+			name:    "malformed yaml",
+			source:  "type: [velocity",
+			wantErr: "invalid definition",
+		},
+		{
+			name:    "malformed json",
+			source:  `{"type": "velocity", "window": "5m"`,
+			wantErr: "invalid definition",
+		},
+		{
+			name:    "missing window",
+			source:  "type: velocity\nmin_count: 3\n",
+			wantErr: "invalid window",
+		},
+		{
+			name:    "velocity min count too low",
+			source:  "type: velocity\nwindow: 5m\nmin_count: 1\n",
+			wantErr: "min_count must be at least 2",
+		},
+		{
+			name:    "repeated amount without min count",
+			source:  `{"type": "repeated_amount", "window": "1h"}`,
+			wantErr: "min_count must be at least 2",
+		},
+		{
+			name:    "amount spike multiplier too low",
+			source:  "type: amount_spike\nwindow: 30d\nmultiplier: 1\n",
+			wantErr: "multiplier must be greater than 1",
+		},
+		{
+			name:    "unknown type",
+			source:  "type: geo_hop\nwindow: 1h\n",
+			wantErr: `unknown type "geo_hop"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Compile(7, "rule", tt.source)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Compile() error = %v; want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Compile() error = %v", err)
+			}
+			if got.ID != 7 || got.Name != "rule" {
+				t.Errorf("Compile() identity = %d %q; want 7 \"rule\"", got.ID, got.Name)
+			}
+			if got.Definition != tt.want {
+				t.Errorf("Compile() definition = %+v; want %+v", got.Definition, tt.want)
+			}
+			if got.Window != tt.wantWindow {
+				t.Errorf("Compile() window = %v; want %v", got.Window, tt.wantWindow)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+// Package scoring implements the built-in streaming anomaly scorer. It keeps an
+// exponentially weighted baseline per device and scores every new transaction
+// against it before folding the transaction into the baseline.
+package scoring
+
+import (
+	"math"
+	"time"
+)
+
+// HoursPerDay is the length of the time-of-day profile.
+const HoursPerDay = 24
+
+// Baseline is the learned behaviour of a single device.
+type Baseline struct {
+	DeviceID int64
+	Count    int64
+
+	// EWMA mean and variance of the transaction amount.
+	AmountMean float64
+	AmountVar  float64
+
+	// EWMA mean and variance of the log inter-arrival time in seconds. The log keeps
+	// bursts and quiet nights on a comparable scale.
+	GapMean float64
+	GapVar  float64
+
+	LastTxnTime time.Time
+
+	// HourProfile is the exponentially decayed share of transactions per hour of day.
+	// It sums to 1 once the device has seen a transaction.
+	HourProfile []float64
+
+	// Seasonality is the device's hour-of-week profile, if any. It is maintained
+	// separately and read-only for the scorer.
+	Seasonality *Seasonality
+}
+
+// NewBaseline returns an empty baseline for a device.
+func NewBaseline(deviceID int64) *Baseline {
+	return &Baseline{DeviceID: deviceID, HourProfile: make([]float64, HoursPerDay)}
+}
+
+// Clone returns a deep copy of the baseline.
+func (b *Baseline) Clone() *Baseline {
+	c := *b
+	c.HourProfile = append([]float64(nil), b.HourProfile...)
+	return &c
+}
+
+// Update folds a transaction into the baseline. Transactions older than the last
+// one seen still update the amount and hour profile but not the inter-arrival time.
+func (b *Baseline) Update(cfg Config, txn Transaction) {
+	if len(b.HourProfile) != HoursPerDay {
+		b.HourProfile = make([]float64, HoursPerDay)
+	}
+
+	if b.Count == 0 {
+		b.AmountMean = txn.Amount
+		b.AmountVar = 0
+		b.HourProfile[txn.Time.Hour()] = 1
+		b.LastTxnTime = txn.Time
+		b.Count = 1
+		return
+	}
+
+	b.AmountMean, b.AmountVar = ewma(b.AmountMean, b.AmountVar, txn.Amount, cfg.AmountAlpha)
+
+	if gap, ok := b.logGap(txn.Time); ok {
+		if b.Count == 1 {
+			b.GapMean, b.GapVar = gap, 0
+		} else {
+			b.GapMean, b.GapVar = ewma(b.GapMean, b.GapVar, gap, cfg.GapAlpha)
+		}
+		b.LastTxnTime = txn.Time
+	}
+
+	for h := range b.HourProfile {
+		b.HourProfile[h] *= 1 - cfg.HourAlpha
+	}
+	b.HourProfile[txn.Time.Hour()] += cfg.HourAlpha
+
+	b.Count++
+}
+
+// logGap returns the log of the seconds elapsed since the last transaction, or false
+// when the transaction is not newer than it.
+func (b *Baseline) logGap(t time.Time) (float64, bool) {
+	if b.LastTxnTime.IsZero() || !t.After(b.LastTxnTime) {
+		return 0, false
+	}
+	return math.Log1p(t.Sub(b.LastTxnTime).Seconds()), true
+}
+
+// hourShare returns the share of the device's transactions made at the given hour.
+func (b *Baseline) hourShare(hour int) float64 {
+	if len(b.HourProfile) != HoursPerDay {
+		return 0
+	}
+	return b.HourProfile[hour]
+}
+
+// ewma applies one step of the exponentially weighted mean and variance recurrence.
+func ewma(mean, variance, x, alpha float64) (float64, float64) {
+	delta := x - mean
+	mean += alpha * delta
+	variance = (1 - alpha) * (variance + alpha*delta*delta)
+	return mean, variance
+}
+
+// stdDev returns the standard deviation with a floor, so a device that always charges
+// the same amount does not turn every small deviation into an infinite z-score.
+func stdDev(mean, variance, relativeFloor, absoluteFloor float64) float64 {
+	return math.Max(math.Sqrt(variance), math.Max(relativeFloor*math.Abs(mean), absoluteFloor))
+}
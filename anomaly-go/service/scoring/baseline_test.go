@@ -0,0 +1,128 @@
+package scoring
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// noon is the time of the first transaction of the test devices.
+var noon = time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+
+func TestBaselineUpdate(t *testing.T) {
+	cfg := Config{AmountAlpha: 0.5, GapAlpha: 0.5, HourAlpha: 0.25}
+	b := NewBaseline(1)
+
+	b.Update(cfg, Transaction{Time: noon, Amount: 100})
+	if b.Count != 1 || b.AmountMean != 100 || b.AmountVar != 0 || !b.LastTxnTime.Equal(noon) {
+		t.Fatalf("first Update() = count %d, mean %v, var %v, last %v; want 1, 100, 0, %v", b.Count, b.AmountMean, b.AmountVar, b.LastTxnTime, noon)
+	}
+	if b.HourProfile[12] != 1 {
+		t.Errorf("first Update() hour share = %v; want 1", b.HourProfile[12])
+	}
+
+	// The first gap seeds the inter-arrival mean; amounts follow the EWMA.
+	b.Update(cfg, Transaction{Time: noon.Add(time.Minute), Amount: 140})
+	if b.AmountMean != 120 || b.AmountVar != 400 {
+		t.Errorf("second Update() amount = %v ± %v; want 120 ± 400", b.AmountMean, b.AmountVar)
+	}
+	if want := math.Log1p(60); b.GapMean != want || b.GapVar != 0 {
+		t.Errorf("second Update() gap = %v ± %v; want %v ± 0", b.GapMean, b.GapVar, want)
+	}
+
+	// The second gap follows the EWMA, and the hour profile decays towards 13:00.
+	b.Update(cfg, Transaction{Time: noon.Add(time.Minute + time.Hour), Amount: 120})
+	if want := (math.Log1p(60) + math.Log1p(3600)) / 2; math.Abs(b.GapMean-want) > 1e-12 {
+		t.Errorf("third Update() gap mean = %v; want %v", b.GapMean, want)
+	}
+	if b.HourProfile[12] != 0.75 || b.HourProfile[13] != 0.25 {
+		t.Errorf("third Update() hour shares = %v at 12:00, %v at 13:00; want 0.75, 0.25", b.HourProfile[12], b.HourProfile[13])
+	}
+	if b.Count != 3 {
+		t.Errorf("Count = %d; want 3", b.Count)
+	}
+
+	// A late transaction updates the amount but not the inter-arrival time.
+	gapMean, last := b.GapMean, b.LastTxnTime
+	b.Update(cfg, Transaction{Time: noon, Amount: 120})
+	if b.GapMean != gapMean || !b.LastTxnTime.Equal(last) || b.Count != 4 {
+		t.Errorf("late Update() = gap %v, last %v, count %d; want %v, %v, 4", b.GapMean, b.LastTxnTime, b.Count, gapMean, last)
+	}
+}
+
+func TestBaselineClone(t *testing.T) {
+	b := NewBaseline(1)
+	b.Update(DefaultConfig(), Transaction{Time: noon, Amount: 100})
+	c := b.Clone()
+	c.HourProfile[12] = 0
+	if b.HourProfile[12] != 1 {
+		t.Errorf("changing the clone changed the hour profile of the original")
+	}
+}
+
+func TestLogGap(t *testing.T) {
+	tests := []struct {
+		name string
+		last time.Time
+		t    time.Time
+		want float64
+		ok   bool
+	}{
+		{"no previous transaction", time.Time{}, noon, 0, false},
+		{"same instant", noon, noon, 0, false},
+		{"older", noon, noon.Add(-time.Second), 0, false},
+		{"one minute later", noon, noon.Add(time.Minute), math.Log1p(60), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &Baseline{LastTxnTime: tt.last}
+			got, ok := b.logGap(tt.t)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("logGap() = %v, %v; want %v, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestEWMA(t *testing.T) {
+	tests := []struct {
+		name                   string
+		mean, variance, x      float64
+		alpha                  float64
+		wantMean, wantVariance float64
+	}{
+		{"on the mean", 10, 4, 10, 0.5, 10, 2},
+		{"above the mean", 10, 0, 14, 0.5, 12, 4},
+		{"below the mean", 10, 0, 6, 0.25, 9, 3},
+		{"alpha one forgets", 10, 4, 20, 1, 20, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mean, variance := ewma(tt.mean, tt.variance, tt.x, tt.alpha)
+			if math.Abs(mean-tt.wantMean) > 1e-12 || math.Abs(variance-tt.wantVariance) > 1e-12 {
+				t.Errorf("ewma() = %v, %v; want %v, %v", mean, variance, tt.wantMean, tt.wantVariance)
+			}
+		})
+	}
+}
+
+func TestStdDev(t *testing.T) {
+	tests := []struct {
+		name     string
+		mean     float64
+		variance float64
+		want     float64
+	}{
+		{"from the variance", 100, 400, 20},
+		{"relative floor", 100, 1, 5},
+		{"absolute floor", 2, 0, 1},
+		{"negative mean", -100, 0, 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := stdDev(tt.mean, tt.variance, 0.05, 1); got != tt.want {
+				t.Errorf("stdDev(%v, %v) = %v; want %v", tt.mean, tt.variance, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package scoring
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Built-in scorer registered in the model registry at startup.
+const (
+	BaselineScorerName    = "ewma_baseline"
+	BaselineScorerVersion = "1.1.0"
+)
+
+// Scorer rates transactions against per-device baselines. Every implementation is
+// identified by a model name and version so that stored scores can be traced back
+// to the model that produced them.
+type Scorer interface {
+	// Model returns the registry identity and parameters of the scorer.
+	Model() ModelInfo
+	// Score rates a transaction without modifying the baseline.
+	Score(b *Baseline, txn Transaction) Result
+	// Update folds a transaction into the baseline.
+	Update(b *Baseline, txn Transaction)
+}
+
+// ModelInfo identifies a model version.
+type ModelInfo struct {
+	Name       string
+	Version    string
+	Parameters json.RawMessage
+}
+
+// Key is the value recorded in anomaly_results.model_version, e.g. "ewma_baseline@1.0.0".
+func (m ModelInfo) Key() string {
+	return m.Name + "@" + m.Version
+}
+
+// Default returns the built-in scorer with its default settings.
+func Default() Scorer {
+	return NewBaselineScorer(BaselineScorerVersion, DefaultConfig())
+}
+
+// Buildable reports whether the service can run models of the given name itself.
+// Other names may still be registered to trace scores written by external models.
+func Buildable(name string) bool {
+	return name == BaselineScorerName
+}
+
+// New builds the scorer for a registered model. Parameters missing from the JSON
+// document keep their default values, except for features added after the model
+// was registered, which stay off so stored versions keep scoring the same way.
+func New(name, version string, parameters []byte) (Scorer, error) {
+	switch name {
+	case BaselineScorerName:
+		cfg := DefaultConfig()
+		cfg.SeasonalMinWeeks = 0
+		if len(parameters) > 0 {
+			if err := json.Unmarshal(parameters, &cfg); err != nil {
+				return nil, fmt.Errorf("invalid parameters: %w", err)
+			}
+		}
+		if err := cfg.Validate(); err != nil {
+			return nil, err
+		}
+		return NewBaselineScorer(version, cfg), nil
+	default:
+		return nil, fmt.Errorf("model %q has no in-process implementation", name)
+	}
+}
@@ -0,0 +1,132 @@
+package scoring
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestModelInfoKey(t *testing.T) {
+	m := ModelInfo{Name: BaselineScorerName, Version: "1.0.0"}
+	if got := m.Key(); got != "ewma_baseline@1.0.0" {
+		t.Errorf("Key() = %q; want %q", got, "ewma_baseline@1.0.0")
+	}
+}
+
+func TestDefault(t *testing.T) {
+	m := Default().Model()
+	if m.Name != BaselineScorerName || m.Version != BaselineScorerVersion {
+		t.Errorf("Default().Model() = %s@%s; want %s@%s", m.Name, m.Version, BaselineScorerName, BaselineScorerVersion)
+	}
+
+	var cfg Config
+	if err := json.Unmarshal(m.Parameters, &cfg); err != nil {
+		t.Fatalf("Default().Model().Parameters is not a config: %v", err)
+	}
+	if cfg != DefaultConfig() {
+		t.Errorf("Default() parameters = %+v; want %+v", cfg, DefaultConfig())
+	}
+}
+
+func TestBuildable(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{BaselineScorerName, true},
+		{"isolation_forest", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Buildable(tt.name); got != tt.want {
+				t.Errorf("Buildable(%q) = %v; want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name       string
+		model      string
+		parameters string
+		want       func(*Config)
+		wantErr    string
+	}{
+		{
+			name:  "defaults without parameters",
+			model: BaselineScorerName,
+			want:  func(*Config) {},
+		},
+		{
+			name:       "partial parameters keep defaults",
+			model:      BaselineScorerName,
+			parameters: `{"min_history": 5, "z_threshold": 3}`,
+			want:       func(c *Config) { c.MinHistory, c.ZThreshold = 5, 3 },
+		},
+		{
+			name:       "explicit seasonal weeks",
+			model:      BaselineScorerName,
+			parameters: `{"seasonal_min_weeks": 4}`,
+			want:       func(c *Config) { c.SeasonalMinWeeks = 4 },
+		},
+		{
+			name:       "negative seasonal weeks",
+			model:      BaselineScorerName,
+			parameters: `{"seasonal_min_weeks": -1}`,
+			wantErr:    "seasonal_min_weeks must not be negative",
+		},
+		{
+			name:       "malformed parameters",
+			model:      BaselineScorerName,
+			parameters: `{"min_history": `,
+			wantErr:    "invalid parameters",
+		},
+		{
+			name:       "invalid alpha",
+			model:      BaselineScorerName,
+			parameters: `{"amount_alpha": 1.5}`,
+			wantErr:    "amount_alpha must be in (0, 1]",
+		},
+		{
+			name:       "saturation below threshold",
+			model:      BaselineScorerName,
+			parameters: `{"z_threshold": 4, "z_saturation": 3}`,
+			wantErr:    "z_saturation must be greater than z_threshold",
+		},
+		{
+			name:    "external model",
+			model:   "isolation_forest",
+			wantErr: "no in-process implementation",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scorer, err := New(tt.model, "2.0.0", []byte(tt.parameters))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("New() error = %v; want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+
+			m := scorer.Model()
+			if m.Key() != tt.model+"@2.0.0" {
+				t.Errorf("New().Model().Key() = %q; want %q", m.Key(), tt.model+"@2.0.0")
+			}
+			want := DefaultConfig()
+			// Stored models keep seasonality off unless their parameters turn it on.
+			want.SeasonalMinWeeks = 0
+			tt.want(&want)
+			if got := scorer.(*BaselineScorer).Config; got != want {
+				t.Errorf("New() config = %+v; want %+v", got, want)
+			}
+		})
+	}
+}
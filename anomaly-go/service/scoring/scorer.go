@@ -0,0 +1,214 @@
+package scoring
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+)
+
+// Reason codes produced by the scorer.
+const (
+	ReasonAmountHigh   = "AMOUNT_HIGH"
+	ReasonAmountLow    = "AMOUNT_LOW"
+	ReasonVelocityHigh = "VELOCITY_HIGH"
+	ReasonUnusualHour  = "UNUSUAL_HOUR"
+)
+
+// Feature names reported with each reason.
+const (
+	FeatureAmount       = "txn_amt"
+	FeatureInterArrival = "inter_arrival_seconds"
+	FeatureHourOfDay    = "hour_of_day_share"
+	FeatureHourOfWeek   = "hour_of_week_share"
+)
+
+// Config tunes the baseline scorer. It is stored as the model parameters in the registry.
+type Config struct {
+	// Smoothing factors of the amount, inter-arrival and hour-of-day EWMAs.
+	AmountAlpha float64 `json:"amount_alpha"`
+	GapAlpha    float64 `json:"gap_alpha"`
+	HourAlpha   float64 `json:"hour_alpha"`
+	// MinHistory is the number of transactions a device needs before it is scored.
+	MinHistory int64 `json:"min_history"`
+	// ZThreshold is the z-score from which a deviation starts contributing, and
+	// ZSaturation the z-score at which it contributes fully.
+	ZThreshold  float64 `json:"z_threshold"`
+	ZSaturation float64 `json:"z_saturation"`
+	// HourWeight caps the contribution of an unusual hour of day, which on its own
+	// should not be enough to flag a transaction.
+	HourWeight float64 `json:"hour_weight"`
+	// SeasonalMinWeeks is the number of weeks an hour-of-week slot needs before the
+	// seasonal profile replaces the hour-of-day profile and scales the expected
+	// inter-arrival time. 0 disables seasonality.
+	SeasonalMinWeeks int64 `json:"seasonal_min_weeks"`
+}
+
+// Validate checks that the settings are usable.
+func (c Config) Validate() error {
+	for name, alpha := range map[string]float64{"amount_alpha": c.AmountAlpha, "gap_alpha": c.GapAlpha, "hour_alpha": c.HourAlpha} {
+		if alpha <= 0 || alpha > 1 {
+			return fmt.Errorf("%s must be in (0, 1]", name)
+		}
+	}
+	if c.MinHistory < 0 {
+		return fmt.Errorf("min_history must not be negative")
+	}
+	if c.ZThreshold < 0 || c.ZSaturation <= c.ZThreshold {
+		return fmt.Errorf("z_saturation must be greater than z_threshold and z_threshold must not be negative")
+	}
+	if c.HourWeight < 0 || c.HourWeight > 1 {
+		return fmt.Errorf("hour_weight must be in [0, 1]")
+	}
+	if c.SeasonalMinWeeks < 0 {
+		return fmt.Errorf("seasonal_min_weeks must not be negative")
+	}
+	return nil
+}
+
+// DefaultConfig returns the scorer settings used by the service.
+func DefaultConfig() Config {
+	return Config{
+		AmountAlpha: 0.05,
+		GapAlpha:    0.05,
+		HourAlpha:   0.02,
+		MinHistory:  20,
+		ZThreshold:  2,
+		ZSaturation: 6,
+		HourWeight:  0.4,
+
+		SeasonalMinWeeks: 3,
+	}
+}
+
+// Transaction is the input of the scorer.
+type Transaction struct {
+	DeviceID int64
+	ID       string
+	Time     time.Time
+	Amount   float64
+}
+
+// Reason explains one contribution to a score.
+type Reason struct {
+	Code     string
+	Message  string
+	Feature  string
+	Value    float64
+	Baseline float64
+}
+
+// Result is the outcome of scoring a transaction.
+type Result struct {
+	// Confidence is the anomaly confidence in percent (0-100), on the same scale as
+	// the configured confidence threshold.
+	Confidence float64
+	Reasons    []Reason
+}
+
+// BaselineScorer scores transactions against per-device EWMA baselines.
+type BaselineScorer struct {
+	Config  Config
+	version string
+}
+
+// NewBaselineScorer creates a scorer with the given settings, registered under version.
+func NewBaselineScorer(version string, cfg Config) *BaselineScorer {
+	return &BaselineScorer{Config: cfg, version: version}
+}
+
+// Model identifies the scorer in the model registry.
+func (s *BaselineScorer) Model() ModelInfo {
+	params, _ := json.Marshal(s.Config)
+	return ModelInfo{Name: BaselineScorerName, Version: s.version, Parameters: params}
+}
+
+// Update folds a transaction into the device baseline using the scorer's smoothing factors.
+func (s *BaselineScorer) Update(b *Baseline, txn Transaction) {
+	b.Update(s.Config, txn)
+}
+
+// Score rates a transaction against the device baseline without modifying it.
+// Devices with less than MinHistory transactions always score 0.
+func (s *BaselineScorer) Score(b *Baseline, txn Transaction) Result {
+	result := Result{Reasons: []Reason{}}
+	if b.Count < s.Config.MinHistory {
+		return result
+	}
+
+	// Independent contributions in [0,1] combined as 1 - Π(1 - p).
+	normal := 1.0
+
+	amountSD := stdDev(b.AmountMean, b.AmountVar, 0.05, 1)
+	amountZ := (txn.Amount - b.AmountMean) / amountSD
+	if p := s.zContribution(math.Abs(amountZ)); p > 0 {
+		normal *= 1 - p
+		code, direction := ReasonAmountHigh, "above"
+		if amountZ < 0 {
+			code, direction = ReasonAmountLow, "below"
+		}
+		result.Reasons = append(result.Reasons, Reason{
+			Code:     code,
+			Message:  fmt.Sprintf("Amount %.2f is %.1f standard deviations %s the device average of %.2f", txn.Amount, math.Abs(amountZ), direction, b.AmountMean),
+			Feature:  FeatureAmount,
+			Value:    txn.Amount,
+			Baseline: b.AmountMean,
+		})
+	}
+
+	if gap, ok := b.logGap(txn.Time); ok && b.Count > 1 {
+		// Busy hours of the week shorten the expected gap in proportion to their rate.
+		expectedGap := b.GapMean
+		if _, rate, ok := b.Seasonality.share(txn.Time, s.Config.SeasonalMinWeeks); ok && rate > 0 {
+			expectedGap = math.Max(0, expectedGap-math.Log(math.Min(10, math.Max(0.1, rate))))
+		}
+
+		// Only gaps shorter than usual are suspicious.
+		gapZ := (expectedGap - gap) / stdDev(b.GapMean, b.GapVar, 0.05, 0.1)
+		if p := s.zContribution(gapZ); p > 0 {
+			normal *= 1 - p
+			seconds := math.Expm1(gap)
+			usual := math.Expm1(expectedGap)
+			result.Reasons = append(result.Reasons, Reason{
+				Code:     ReasonVelocityHigh,
+				Message:  fmt.Sprintf("Transaction arrived %.0fs after the previous one; the device usually waits about %.0fs", seconds, usual),
+				Feature:  FeatureInterArrival,
+				Value:    seconds,
+				Baseline: usual,
+			})
+		}
+	}
+
+	// An hour with less than a quarter of the uniform share is considered unusual. The
+	// hour-of-week profile is used once established, so a busy Saturday evening is not
+	// judged against weekday evenings.
+	share, uniform := b.hourShare(txn.Time.Hour()), 1.0/HoursPerDay
+	feature, when := FeatureHourOfDay, fmt.Sprintf("at %02d:00", txn.Time.Hour())
+	if weekly, _, ok := b.Seasonality.share(txn.Time, s.Config.SeasonalMinWeeks); ok {
+		share, uniform = weekly, 1.0/HoursPerWeek
+		feature, when = FeatureHourOfWeek, fmt.Sprintf("on %ss at %02d:00", txn.Time.Weekday(), txn.Time.Hour())
+	}
+	if share < uniform/4 {
+		p := s.Config.HourWeight * (1 - share/(uniform/4))
+		normal *= 1 - p
+		result.Reasons = append(result.Reasons, Reason{
+			Code:     ReasonUnusualHour,
+			Message:  fmt.Sprintf("Only %.1f%% of this device's transactions happen %s", share*100, when),
+			Feature:  feature,
+			Value:    share,
+			Baseline: uniform,
+		})
+	}
+
+	result.Confidence = math.Round((1-normal)*10000) / 100
+	return result
+}
+
+// zContribution maps a z-score to a contribution in [0,1], linear between
+// ZThreshold and ZSaturation.
+func (s *BaselineScorer) zContribution(z float64) float64 {
+	if z <= s.Config.ZThreshold {
+		return 0
+	}
+	return math.Min(1, (z-s.Config.ZThreshold)/(s.Config.ZSaturation-s.Config.ZThreshold))
+}
@@ -0,0 +1,112 @@
+package scoring
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// established returns a baseline with enough history to be scored: amounts of
+// 100 ± 10, an hour between transactions and every hour of the day equally busy.
+func established() *Baseline {
+	b := NewBaseline(1)
+	b.Count = 50
+	b.AmountMean, b.AmountVar = 100, 100
+	b.GapMean, b.GapVar = math.Log1p(3600), 0
+	b.LastTxnTime = noon
+	for h := range b.HourProfile {
+		b.HourProfile[h] = 1.0 / HoursPerDay
+	}
+	return b
+}
+
+func TestScore(t *testing.T) {
+	onlyNine := established()
+	for h := range onlyNine.HourProfile {
+		onlyNine.HourProfile[h] = 0
+	}
+	onlyNine.HourProfile[9] = 1
+	young := established()
+	young.Count = DefaultConfig().MinHistory - 1
+
+	next := noon.Add(time.Hour)
+	tests := []struct {
+		name           string
+		b              *Baseline
+		txn            Transaction
+		wantConfidence float64
+		wantCodes      []string
+	}{
+		{"usual transaction", established(), Transaction{Time: next, Amount: 100}, 0, nil},
+		{"not enough history", young, Transaction{Time: noon.Add(time.Second), Amount: 1000}, 0, nil},
+		{"amount at the threshold", established(), Transaction{Time: next, Amount: 120}, 0, nil},
+		// z = 4 is halfway between the threshold of 2 and saturation at 6.
+		{"high amount", established(), Transaction{Time: next, Amount: 140}, 50, []string{ReasonAmountHigh}},
+		{"low amount", established(), Transaction{Time: next, Amount: 40}, 100, []string{ReasonAmountLow}},
+		{"burst", established(), Transaction{Time: noon.Add(time.Minute), Amount: 100}, 100, []string{ReasonVelocityHigh}},
+		{"long pause", established(), Transaction{Time: noon.Add(24 * time.Hour), Amount: 100}, 0, nil},
+		{"unusual hour", onlyNine, Transaction{Time: next, Amount: 100}, 40, []string{ReasonUnusualHour}},
+		{"usual hour", onlyNine, Transaction{Time: time.Date(2026, 3, 3, 9, 0, 0, 0, time.UTC), Amount: 100}, 0, nil},
+		// 1 - (1 - 0.5)(1 - 0.4)
+		{"high amount at an unusual hour", onlyNine, Transaction{Time: next, Amount: 140}, 70, []string{ReasonAmountHigh, ReasonUnusualHour}},
+	}
+	s := &BaselineScorer{Config: DefaultConfig()}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := tt.b.Clone()
+			got := s.Score(tt.b, tt.txn)
+			if math.Abs(got.Confidence-tt.wantConfidence) > 1e-9 {
+				t.Errorf("Score() confidence = %v; want %v", got.Confidence, tt.wantConfidence)
+			}
+			var codes []string
+			for _, r := range got.Reasons {
+				codes = append(codes, r.Code)
+			}
+			if !reflect.DeepEqual(codes, tt.wantCodes) {
+				t.Errorf("Score() reasons = %v; want %v", codes, tt.wantCodes)
+			}
+			if !reflect.DeepEqual(tt.b, before) {
+				t.Errorf("Score() modified the baseline")
+			}
+		})
+	}
+}
+
+func TestScoreReasonDetails(t *testing.T) {
+	s := &BaselineScorer{Config: DefaultConfig()}
+	got := s.Score(established(), Transaction{Time: noon.Add(time.Minute), Amount: 140})
+	want := []Reason{
+		{Code: ReasonAmountHigh, Feature: FeatureAmount, Value: 140, Baseline: 100},
+		{Code: ReasonVelocityHigh, Feature: FeatureInterArrival, Value: 60, Baseline: 3600},
+	}
+	if len(got.Reasons) != len(want) {
+		t.Fatalf("Score() reasons = %+v; want %+v", got.Reasons, want)
+	}
+	for i, r := range got.Reasons {
+		w := want[i]
+		if r.Code != w.Code || r.Feature != w.Feature || math.Abs(r.Value-w.Value) > 1e-6 || math.Abs(r.Baseline-w.Baseline) > 1e-6 || r.Message == "" {
+			t.Errorf("Score() reason %d = %+v; want %+v with a message", i, r, w)
+		}
+	}
+}
+
+func TestZContribution(t *testing.T) {
+	s := &BaselineScorer{Config: Config{ZThreshold: 2, ZSaturation: 6}}
+	tests := []struct {
+		z    float64
+		want float64
+	}{
+		{-3, 0},
+		{0, 0},
+		{2, 0},
+		{3, 0.25},
+		{6, 1},
+		{10, 1},
+	}
+	for _, tt := range tests {
+		if got := s.zContribution(tt.z); got != tt.want {
+			t.Errorf("zContribution(%v) = %v; want %v", tt.z, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package scoring
+
+import (
+	"math"
+	"time"
+)
+
+// HoursPerWeek is the length of the seasonal profile: one slot per hour of the week,
+// Monday 00:00 first.
+const HoursPerWeek = 7 * HoursPerDay
+
+// SeasonalityAlpha is the weight of the newest week in the smoothed count of a slot.
+const SeasonalityAlpha = 0.2
+
+// Seasonality is the expected number of transactions of a device in each hour of the
+// week, smoothed across weeks. It is built from completed hours by a background job
+// and only read while scoring.
+type Seasonality struct {
+	DeviceID int64
+	// Expected and Variance are the EWMA mean and variance of the hourly count per
+	// slot, and Observations the number of weeks folded into each slot.
+	Expected     []float64
+	Variance     []float64
+	Observations []int64
+	// LastHour is the start of the last hour folded in.
+	LastHour time.Time
+}
+
+// NewSeasonality returns an empty profile for a device.
+func NewSeasonality(deviceID int64) *Seasonality {
+	return &Seasonality{
+		DeviceID:     deviceID,
+		Expected:     make([]float64, HoursPerWeek),
+		Variance:     make([]float64, HoursPerWeek),
+		Observations: make([]int64, HoursPerWeek),
+	}
+}
+
+// SlotOf returns the hour-of-week slot of t, read on its own wall clock.
+func SlotOf(t time.Time) int {
+	day := (int(t.Weekday()) + 6) % 7
+	return day*HoursPerDay + t.Hour()
+}
+
+// Observe folds the transaction count of one completed hour into its slot.
+func (s *Seasonality) Observe(hour time.Time, count float64) {
+	slot := SlotOf(hour)
+	if s.Observations[slot] == 0 {
+		s.Expected[slot], s.Variance[slot] = count, 0
+	} else {
+		s.Expected[slot], s.Variance[slot] = ewma(s.Expected[slot], s.Variance[slot], count, SeasonalityAlpha)
+	}
+	s.Observations[slot]++
+	s.LastHour = hour
+}
+
+// Expect returns the expected count and its standard deviation for the hour holding
+// t, or false when the slot has fewer than minWeeks observations.
+func (s *Seasonality) Expect(t time.Time, minWeeks int64) (float64, float64, bool) {
+	if s == nil || minWeeks <= 0 || len(s.Expected) != HoursPerWeek {
+		return 0, 0, false
+	}
+	slot := SlotOf(t)
+	if s.Observations[slot] < minWeeks {
+		return 0, 0, false
+	}
+	return s.Expected[slot], math.Sqrt(s.Variance[slot]), true
+}
+
+// share returns the part of the weekly volume expected in the hour holding t, and
+// the rate of that hour relative to the average hour.
+func (s *Seasonality) share(t time.Time, minWeeks int64) (float64, float64, bool) {
+	expected, _, ok := s.Expect(t, minWeeks)
+	if !ok {
+		return 0, 0, false
+	}
+	total := 0.0
+	for _, e := range s.Expected {
+		total += e
+	}
+	if total <= 0 {
+		return 0, 0, false
+	}
+	share := expected / total
+	return share, share * HoursPerWeek, true
+}
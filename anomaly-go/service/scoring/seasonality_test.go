@@ -0,0 +1,135 @@
+package scoring
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// monday is the start of a week on the device wall clock.
+var monday = time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+
+func TestSlotOf(t *testing.T) {
+	tests := []struct {
+		name string
+		t    time.Time
+		want int
+	}{
+		{"monday midnight", monday, 0},
+		{"monday last minute of the first hour", monday.Add(59 * time.Minute), 0},
+		{"wednesday afternoon", monday.Add(2*24*time.Hour + 13*time.Hour + 30*time.Minute), 61},
+		{"sunday last hour", monday.Add(6*24*time.Hour + 23*time.Hour), HoursPerWeek - 1},
+		{"next monday wraps", monday.AddDate(0, 0, 7), 0},
+		{"wall clock, not UTC", time.Date(2026, 3, 2, 0, 30, 0, 0, time.FixedZone("IST", 5*3600+1800)), 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SlotOf(tt.t); got != tt.want {
+				t.Errorf("SlotOf(%v) = %d; want %d", tt.t, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSeasonalityObserve(t *testing.T) {
+	tests := []struct {
+		name         string
+		counts       []float64
+		wantExpected float64
+		wantVariance float64
+	}{
+		{"first week", []float64{10}, 10, 0},
+		{"second week", []float64{10, 20}, 12, 16},
+		{"constant count", []float64{5, 5, 5, 5}, 5, 0},
+		{"zero counts", []float64{0, 0}, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewSeasonality(1)
+			hour := monday.Add(9 * time.Hour)
+			for week, count := range tt.counts {
+				s.Observe(hour.AddDate(0, 0, 7*week), count)
+			}
+			slot := SlotOf(hour)
+			if math.Abs(s.Expected[slot]-tt.wantExpected) > 1e-9 || math.Abs(s.Variance[slot]-tt.wantVariance) > 1e-9 {
+				t.Errorf("slot %d = %v ± %v; want %v ± %v", slot, s.Expected[slot], s.Variance[slot], tt.wantExpected, tt.wantVariance)
+			}
+			if s.Observations[slot] != int64(len(tt.counts)) {
+				t.Errorf("slot %d has %d observations; want %d", slot, s.Observations[slot], len(tt.counts))
+			}
+			if want := hour.AddDate(0, 0, 7*(len(tt.counts)-1)); !s.LastHour.Equal(want) {
+				t.Errorf("LastHour = %v; want %v", s.LastHour, want)
+			}
+			for i, n := range s.Observations {
+				if i != slot && n != 0 {
+					t.Errorf("slot %d has %d observations; want none", i, n)
+				}
+			}
+		})
+	}
+}
+
+func TestSeasonalityExpect(t *testing.T) {
+	hour := monday.Add(9 * time.Hour)
+	observed := NewSeasonality(1)
+	observed.Observe(hour, 10)
+	observed.Observe(hour.AddDate(0, 0, 7), 20)
+
+	tests := []struct {
+		name         string
+		s            *Seasonality
+		t            time.Time
+		minWeeks     int64
+		wantExpected float64
+		wantStdDev   float64
+		ok           bool
+	}{
+		{"nil profile", nil, hour, 1, 0, 0, false},
+		{"zero minimum", observed, hour, 0, 0, 0, false},
+		{"truncated profile", &Seasonality{Expected: []float64{1}}, hour, 1, 0, 0, false},
+		{"empty slot", observed, hour.Add(time.Hour), 1, 0, 0, false},
+		{"too few weeks", observed, hour, 3, 0, 0, false},
+		{"enough weeks", observed, hour, 2, 12, 4, true},
+		{"same hour another week", observed, hour.AddDate(0, 0, 21).Add(45 * time.Minute), 1, 12, 4, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expected, stdDev, ok := tt.s.Expect(tt.t, tt.minWeeks)
+			if ok != tt.ok || math.Abs(expected-tt.wantExpected) > 1e-9 || math.Abs(stdDev-tt.wantStdDev) > 1e-9 {
+				t.Errorf("Expect() = %v, %v, %v; want %v, %v, %v", expected, stdDev, ok, tt.wantExpected, tt.wantStdDev, tt.ok)
+			}
+		})
+	}
+}
+
+func TestSeasonalityShare(t *testing.T) {
+	morning := monday.Add(9 * time.Hour)
+	evening := monday.Add(21 * time.Hour)
+
+	tests := []struct {
+		name      string
+		counts    map[time.Time]float64
+		t         time.Time
+		wantShare float64
+		wantRate  float64
+		ok        bool
+	}{
+		{"no observations", nil, morning, 0, 0, false},
+		{"quiet week", map[time.Time]float64{morning: 0}, morning, 0, 0, false},
+		{"single busy hour", map[time.Time]float64{morning: 12}, morning, 1, HoursPerWeek, true},
+		{"two equal hours", map[time.Time]float64{morning: 12, evening: 12}, evening, 0.5, HoursPerWeek / 2, true},
+		{"quiet hour of a busy week", map[time.Time]float64{morning: 12, evening: 0}, evening, 0, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewSeasonality(1)
+			for hour, count := range tt.counts {
+				s.Observe(hour, count)
+			}
+			share, rate, ok := s.share(tt.t, 1)
+			if ok != tt.ok || math.Abs(share-tt.wantShare) > 1e-9 || math.Abs(rate-tt.wantRate) > 1e-9 {
+				t.Errorf("share() = %v, %v, %v; want %v, %v, %v", share, rate, ok, tt.wantShare, tt.wantRate, tt.ok)
+			}
+		})
+	}
+}
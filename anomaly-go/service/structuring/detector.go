@@ -0,0 +1,215 @@
+// Package structuring finds signs of structuring in the amounts of one device over
+// one time window: repeated amounts just under a limit, a high share of round
+// amounts and last digits that are not uniformly distributed.
+package structuring
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// Finding types.
+const (
+	FindingNearLimit    = "near_limit"
+	FindingRoundAmounts = "round_amounts"
+	FindingLastDigit    = "last_digit"
+)
+
+// Config tunes the detector.
+type Config struct {
+	// Limits are the reporting or velocity limits fraudsters try to stay under, and
+	// NearLimitBand the fraction below a limit that counts as near it.
+	Limits        []float64
+	NearLimitBand float64
+	// MinRepeats is the number of near-limit amounts from which they are reported.
+	MinRepeats int
+	// RoundUnit is the multiple an amount must be of to count as round, and
+	// RoundBaselineShare the share of round amounts considered normal.
+	RoundUnit          float64
+	RoundBaselineShare float64
+	// MinSamples is the number of transactions a window needs before the share and
+	// digit tests are run.
+	MinSamples int
+	// DigitPValue is the significance level of the last-digit chi-square test.
+	DigitPValue float64
+	// MaxSupporting caps the transaction IDs listed with a finding.
+	MaxSupporting int
+}
+
+// DefaultConfig returns the detector settings used when none are configured.
+func DefaultConfig() Config {
+	return Config{
+		Limits:             []float64{2000, 10000, 50000},
+		NearLimitBand:      0.05,
+		MinRepeats:         3,
+		RoundUnit:          100,
+		RoundBaselineShare: 0.2,
+		MinSamples:         30,
+		DigitPValue:        0.01,
+		MaxSupporting:      50,
+	}
+}
+
+// Transaction is the part of a transaction the detector looks at.
+type Transaction struct {
+	ID     string
+	Time   time.Time
+	Amount float64
+}
+
+// Finding is one structuring signal, scored from 0 to 100.
+type Finding struct {
+	Type     string
+	Score    float64
+	Message  string
+	Limit    float64
+	Observed float64
+	Expected float64
+	// Transactions lists up to MaxSupporting supporting transaction IDs out of
+	// Supporting in total.
+	Transactions []string
+	Supporting   int
+}
+
+// Detect runs every test over the transactions of one device and window.
+func Detect(cfg Config, txns []Transaction) []Finding {
+	sorted := append([]Transaction(nil), txns...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Time.Before(sorted[j].Time) })
+
+	findings := nearLimit(cfg, sorted)
+	if f, ok := roundAmounts(cfg, sorted); ok {
+		findings = append(findings, f)
+	}
+	if f, ok := lastDigit(cfg, sorted); ok {
+		findings = append(findings, f)
+	}
+	return findings
+}
+
+// nearLimit reports each limit with at least MinRepeats amounts just under it. The
+// score grows with every repeat beyond the minimum.
+func nearLimit(cfg Config, txns []Transaction) []Finding {
+	var findings []Finding
+	for _, limit := range cfg.Limits {
+		floor := limit * (1 - cfg.NearLimitBand)
+		var supporting []Transaction
+		for _, t := range txns {
+			if t.Amount >= floor && t.Amount < limit {
+				supporting = append(supporting, t)
+			}
+		}
+		if len(supporting) < cfg.MinRepeats {
+			continue
+		}
+
+		f := newFinding(cfg, FindingNearLimit, supporting)
+		f.Score = 100 * (1 - math.Pow(0.5, float64(len(supporting)-cfg.MinRepeats+1)))
+		f.Message = fmt.Sprintf("%d amounts between %.2f and the %.2f limit", len(supporting), floor, limit)
+		f.Limit = limit
+		f.Observed = float64(len(supporting))
+		f.Expected = float64(cfg.MinRepeats)
+		findings = append(findings, f)
+	}
+	return findings
+}
+
+// roundAmounts reports a share of round amounts significantly above the baseline
+// share (one-sided binomial z-test at z >= 3). The score is the excess share.
+func roundAmounts(cfg Config, txns []Transaction) (Finding, bool) {
+	n := 0
+	var supporting []Transaction
+	for _, t := range txns {
+		if t.Amount <= 0 {
+			continue
+		}
+		n++
+		if isMultiple(t.Amount, cfg.RoundUnit) {
+			supporting = append(supporting, t)
+		}
+	}
+	if n < cfg.MinSamples {
+		return Finding{}, false
+	}
+
+	p := cfg.RoundBaselineShare
+	share := float64(len(supporting)) / float64(n)
+	z := (float64(len(supporting)) - float64(n)*p) / math.Sqrt(float64(n)*p*(1-p))
+	if z < 3 {
+		return Finding{}, false
+	}
+
+	f := newFinding(cfg, FindingRoundAmounts, supporting)
+	f.Score = 100 * math.Min(1, (share-p)/(1-p))
+	f.Message = fmt.Sprintf("%.0f%% of amounts are multiples of %.0f, against %.0f%% expected", share*100, cfg.RoundUnit, p*100)
+	f.Observed = share
+	f.Expected = p
+	return f, true
+}
+
+// lastDigit tests whether the last digit of the integer amounts is uniform, as
+// Benford's law predicts for trailing digits, with a chi-square goodness-of-fit test.
+// The over-represented digits make up the supporting transactions.
+func lastDigit(cfg Config, txns []Transaction) (Finding, bool) {
+	var counts [10]int
+	byDigit := make(map[int][]Transaction)
+	n := 0
+	for _, t := range txns {
+		if t.Amount < 10 {
+			continue
+		}
+		d := int(math.Mod(math.Floor(t.Amount), 10))
+		counts[d]++
+		byDigit[d] = append(byDigit[d], t)
+		n++
+	}
+	if n < cfg.MinSamples {
+		return Finding{}, false
+	}
+
+	expected := float64(n) / 10
+	chi2 := 0.0
+	for _, c := range counts {
+		chi2 += (float64(c) - expected) * (float64(c) - expected) / expected
+	}
+	pValue := chiSquareSurvival(chi2, 9)
+	if pValue >= cfg.DigitPValue {
+		return Finding{}, false
+	}
+
+	var supporting []Transaction
+	var digits []int
+	for d, c := range counts {
+		if float64(c) > expected {
+			digits = append(digits, d)
+			supporting = append(supporting, byDigit[d]...)
+		}
+	}
+	sort.SliceStable(supporting, func(i, j int) bool { return supporting[i].Time.Before(supporting[j].Time) })
+
+	f := newFinding(cfg, FindingLastDigit, supporting)
+	f.Score = 100 * (1 - pValue)
+	f.Message = fmt.Sprintf("last digits %v are over-represented (chi-square %.1f, p=%.4f)", digits, chi2, pValue)
+	f.Observed = chi2
+	f.Expected = 9
+	return f, true
+}
+
+func newFinding(cfg Config, findingType string, supporting []Transaction) Finding {
+	f := Finding{Type: findingType, Supporting: len(supporting)}
+	for i, t := range supporting {
+		if cfg.MaxSupporting > 0 && i >= cfg.MaxSupporting {
+			break
+		}
+		f.Transactions = append(f.Transactions, t.ID)
+	}
+	return f
+}
+
+func isMultiple(amount, unit float64) bool {
+	if unit <= 0 {
+		return false
+	}
+	return math.Abs(amount-math.Round(amount/unit)*unit) < 1e-6
+}
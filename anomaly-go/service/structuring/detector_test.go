@@ -0,0 +1,182 @@
+package structuring
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var testStart = time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+
+// amounts returns one transaction per amount, a minute apart, with IDs t0, t1, ...
+func amounts(values ...float64) []Transaction {
+	txns := make([]Transaction, 0, len(values))
+	for i, v := range values {
+		txns = append(txns, Transaction{ID: fmt.Sprintf("t%d", i), Time: testStart.Add(time.Duration(i) * time.Minute), Amount: v})
+	}
+	return txns
+}
+
+// repeat returns value n times.
+func repeat(value float64, n int) []float64 {
+	values := make([]float64, n)
+	for i := range values {
+		values[i] = value
+	}
+	return values
+}
+
+func TestNearLimit(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Limits = []float64{2000}
+	tests := []struct {
+		name      string
+		amounts   []float64
+		wantCount int
+		wantScore float64
+	}{
+		{"empty", nil, 0, 0},
+		{"single amount", []float64{1950}, 0, 0},
+		{"one repeat short", []float64{1950, 1990}, 0, 0},
+		{"at minimum repeats", []float64{1950, 1990, 1999.99}, 3, 50},
+		{"band floor included", []float64{1900, 1900, 1900}, 3, 50},
+		{"limit excluded", []float64{2000, 2000, 2000}, 0, 0},
+		{"below band", []float64{1899.99, 1899.99, 1899.99}, 0, 0},
+		{"one repeat over", []float64{1950, 1960, 1970, 1980}, 4, 75},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			findings := nearLimit(cfg, amounts(tt.amounts...))
+			if tt.wantCount == 0 {
+				if len(findings) != 0 {
+					t.Fatalf("nearLimit() = %+v; want no finding", findings)
+				}
+				return
+			}
+			if len(findings) != 1 {
+				t.Fatalf("nearLimit() returned %d findings; want 1", len(findings))
+			}
+			f := findings[0]
+			if f.Type != FindingNearLimit || f.Limit != 2000 || f.Supporting != tt.wantCount {
+				t.Errorf("nearLimit() = %+v; want %d amounts under 2000", f, tt.wantCount)
+			}
+			if math.Abs(f.Score-tt.wantScore) > 1e-9 {
+				t.Errorf("nearLimit() score = %v; want %v", f.Score, tt.wantScore)
+			}
+		})
+	}
+}
+
+func TestRoundAmounts(t *testing.T) {
+	cfg := DefaultConfig()
+	tests := []struct {
+		name      string
+		amounts   []float64
+		want      bool
+		wantScore float64
+	}{
+		{"empty", nil, false, 0},
+		{"single round amount", []float64{500}, false, 0},
+		{"too few samples", repeat(500, cfg.MinSamples-1), false, 0},
+		{"all round", repeat(500, cfg.MinSamples), true, 100},
+		{"baseline share", append(repeat(500, 6), repeat(123, 24)...), false, 0},
+		{"non-positive amounts ignored", append(repeat(0, 10), repeat(500, cfg.MinSamples-1)...), false, 0},
+		{"half round", append(repeat(500, 15), repeat(123, 15)...), true, 37.5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, ok := roundAmounts(cfg, amounts(tt.amounts...))
+			if ok != tt.want {
+				t.Fatalf("roundAmounts() ok = %v; want %v", ok, tt.want)
+			}
+			if ok && math.Abs(f.Score-tt.wantScore) > 1e-9 {
+				t.Errorf("roundAmounts() score = %v; want %v", f.Score, tt.wantScore)
+			}
+		})
+	}
+}
+
+func TestLastDigit(t *testing.T) {
+	cfg := DefaultConfig()
+	var uniform []float64
+	for i := 0; i < 30; i++ {
+		uniform = append(uniform, float64(100+i))
+	}
+	tests := []struct {
+		name       string
+		amounts    []float64
+		want       bool
+		wantDigits int
+	}{
+		{"empty", nil, false, 0},
+		{"single amount", []float64{107}, false, 0},
+		{"too few samples", repeat(107, cfg.MinSamples-1), false, 0},
+		{"amounts under 10 ignored", append(repeat(7, 10), repeat(107, cfg.MinSamples-1)...), false, 0},
+		{"uniform digits", uniform, false, 0},
+		{"one digit", repeat(107, cfg.MinSamples), true, cfg.MinSamples},
+		{"cents ignored", repeat(107.99, cfg.MinSamples), true, cfg.MinSamples},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, ok := lastDigit(cfg, amounts(tt.amounts...))
+			if ok != tt.want {
+				t.Fatalf("lastDigit() ok = %v; want %v", ok, tt.want)
+			}
+			if !ok {
+				return
+			}
+			if f.Supporting != tt.wantDigits {
+				t.Errorf("lastDigit() supporting = %d; want %d", f.Supporting, tt.wantDigits)
+			}
+			if f.Score <= 100*(1-cfg.DigitPValue) || f.Score > 100 {
+				t.Errorf("lastDigit() score = %v; want above %v", f.Score, 100*(1-cfg.DigitPValue))
+			}
+		})
+	}
+}
+
+func TestDetect(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Limits = []float64{2000}
+	cfg.MaxSupporting = 2
+
+	if findings := Detect(cfg, nil); len(findings) != 0 {
+		t.Errorf("Detect(nil) = %+v; want no finding", findings)
+	}
+
+	// Out of time order: the supporting transactions are listed oldest first.
+	txns := amounts(1950, 1960, 1970)
+	txns[0].Time, txns[2].Time = txns[2].Time, txns[0].Time
+	findings := Detect(cfg, txns)
+	if len(findings) != 1 {
+		t.Fatalf("Detect() returned %d findings; want 1", len(findings))
+	}
+	f := findings[0]
+	if want := []string{"t2", "t1"}; !reflect.DeepEqual(f.Transactions, want) || f.Supporting != 3 {
+		t.Errorf("Detect() supporting %v of %d; want %v of 3", f.Transactions, f.Supporting, want)
+	}
+	if txns[0].ID != "t0" {
+		t.Errorf("Detect() reordered its input")
+	}
+}
+
+func TestIsMultiple(t *testing.T) {
+	tests := []struct {
+		amount, unit float64
+		want         bool
+	}{
+		{500, 100, true},
+		{0, 100, true},
+		{500.01, 100, false},
+		{499.9999999, 100, true},
+		{500, 0, false},
+		{500, -100, false},
+	}
+	for _, tt := range tests {
+		if got := isMultiple(tt.amount, tt.unit); got != tt.want {
+			t.Errorf("isMultiple(%v, %v) = %v; want %v", tt.amount, tt.unit, got, tt.want)
+		}
+	}
+}
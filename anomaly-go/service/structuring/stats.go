@@ -0,0 +1,60 @@
+package structuring
+
+import "math"
+
+// chiSquareSurvival returns P(X >= x) for a chi-square variable with dof degrees of
+// freedom, i.e. the p-value of a chi-square test.
+func chiSquareSurvival(x float64, dof int) float64 {
+	if x <= 0 {
+		return 1
+	}
+	return upperGammaRegularized(float64(dof)/2, x/2)
+}
+
+// upperGammaRegularized computes Q(a, x) with the series expansion of P(a, x) below
+// a+1 and a continued fraction above it (Numerical Recipes, 6.2).
+func upperGammaRegularized(a, x float64) float64 {
+	const (
+		maxIter = 200
+		eps     = 1e-12
+		tiny    = 1e-300
+	)
+	lgamma, _ := math.Lgamma(a)
+	prefix := math.Exp(-x + a*math.Log(x) - lgamma)
+
+	if x < a+1 {
+		sum, term := 1/a, 1/a
+		for n := 1; n < maxIter; n++ {
+			term *= x / (a + float64(n))
+			sum += term
+			if math.Abs(term) < math.Abs(sum)*eps {
+				break
+			}
+		}
+		return math.Max(0, 1-sum*prefix)
+	}
+
+	b := x + 1 - a
+	c := 1 / tiny
+	d := 1 / b
+	h := d
+	for i := 1; i < maxIter; i++ {
+		an := -float64(i) * (float64(i) - a)
+		b += 2
+		d = an*d + b
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = b + an/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		delta := d * c
+		h *= delta
+		if math.Abs(delta-1) < eps {
+			break
+		}
+	}
+	return h * prefix
+}
@@ -0,0 +1,37 @@
+package structuring
+
+import (
+	"math"
+	"testing"
+)
+
+func TestChiSquareSurvival(t *testing.T) {
+	tests := []struct {
+		name string
+		x    float64
+		dof  int
+		want float64
+	}{
+		{"zero statistic", 0, 9, 1},
+		{"negative statistic", -1, 9, 1},
+		// With 2 degrees of freedom the survival function is exp(-x/2).
+		{"two dof series branch", 1, 2, math.Exp(-0.5)},
+		{"two dof fraction branch", 10, 2, math.Exp(-5)},
+		// Critical values of the chi-square distribution with 9 degrees of freedom.
+		{"9 dof at 5%", 16.919, 9, 0.05},
+		{"9 dof at 1%", 21.666, 9, 0.01},
+		{"9 dof at the mean", 9, 9, 0.4373},
+		{"far tail", 500, 9, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := chiSquareSurvival(tt.x, tt.dof)
+			if math.Abs(got-tt.want) > 1e-4 {
+				t.Errorf("chiSquareSurvival(%v, %d) = %v; want %v", tt.x, tt.dof, got, tt.want)
+			}
+			if got < 0 || got > 1 {
+				t.Errorf("chiSquareSurvival(%v, %d) = %v; want a probability", tt.x, tt.dof, got)
+			}
+		})
+	}
+}
@@ -1,3 +1,26 @@
 package constants
 
 const ApplicationVersion = "1.0.01_T1"
+
+// AdminUsername is the user allowed to call the /admin routes.
+const AdminUsername = "admin"
+
+// Label values written to anomaly_results.label. They are stored and compared in lower case.
+const (
+	LabelFraud           = "yes"
+	LabelNotFraud        = "no"
+	LabelAnomalyDetected = "anomaly detected"
+	LabelReviewRequired  = "review required"
+	// LabelNullKey is the key used for transactions without a label, matching the
+	// "null" value accepted by the anomaly_check filter.
+	LabelNullKey = "null"
+)
+
+// Reviewer verdicts written to anomaly_results.review. They are compared in lower case.
+const (
+	ReviewFraud    = "yes"
+	ReviewNotFraud = "no"
+)
+
+// DefaultConfidenceThreshold is used when no threshold is stored in the thresholds table.
+const DefaultConfidenceThreshold = 50